@@ -1,6 +1,9 @@
 package main
 
-import "mybooks/internal/infrastructure/api"
+import (
+	"mybooks/internal/infrastructure/api"
+	_ "time/tzdata" // Timezones of the users, when the system has no timezone database
+)
 
 // main is the entry point of the Go program.
 //
@@ -0,0 +1,71 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The kinds of events published when a user does something with a book.
+const (
+	ReadingStarted     = "reading_started"
+	BookFinished       = "book_finished"
+	BookRated          = "book_rated"
+	BookAddedToLibrary = "book_added_to_library"
+)
+
+// Event is something a user did in the domain, published once it is saved so other parts of the
+// application can react to it without the service that saved it knowing about them.
+type Event struct {
+	Kind      string
+	UserID    uuid.UUID
+	BookID    uuid.UUID
+	LibraryID *uuid.UUID
+	Rating    *float64
+	At        time.Time
+}
+
+// Handler reacts to an event. It handles its own errors, since the publisher has already answered
+// the request that caused the event.
+type Handler func(event Event)
+
+var (
+	mutex    sync.RWMutex
+	handlers []Handler
+)
+
+// Subscribe registers a handler called for every event published from then on.
+//
+// Parameters:
+// - handler: the function called with each event.
+//
+// Returns:
+// - None.
+func Subscribe(handler Handler) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	handlers = append(handlers, handler)
+}
+
+// Publish calls every subscribed handler with an event, in the order they subscribed, before returning.
+// An event without a time happened now.
+//
+// Parameters:
+// - event: the event.
+//
+// Returns:
+// - None.
+func Publish(event Event) {
+	if event.At.IsZero() {
+		event.At = time.Now()
+	}
+
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The kinds of activities of a user shown in the feeds.
+const (
+	ActivityStartedReading = "started_reading"
+	ActivityFinished       = "finished"
+	ActivityRated          = "rated"
+	ActivityAddedToLibrary = "added_to_library"
+)
+
+// ActivityKinds lists every kind of activity, in the order their settings are shown.
+var ActivityKinds = []string{ActivityStartedReading, ActivityFinished, ActivityRated, ActivityAddedToLibrary}
+
+// The visibilities of an activity. Public activities are shown to anyone on a public profile, activities
+// for followers only in the feeds of the accepted followers, and private activities only to their user.
+const (
+	ActivityVisibilityPublic    = "public"
+	ActivityVisibilityFollowers = "followers"
+	ActivityVisibilityPrivate   = "private"
+)
+
+// ActivityDefaultVisibility is the visibility of the activities of a kind the user did not set one for.
+const ActivityDefaultVisibility = ActivityVisibilityFollowers
+
+// Activity is something a user did with a book, shown in the feeds of their followers. The book and the
+// library are copied when the activity is recorded, so the feeds never read the books themselves.
+type Activity struct {
+	ID              uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey"`
+	Kind            string     `json:"kind" gorm:"not null;size:30"`
+	BookID          uuid.UUID  `json:"book_id" gorm:"type:uuid;not null;index"`
+	BookTitle       string     `json:"book_title" gorm:"not null;size:100"`
+	BookAuthor      string     `json:"book_author" gorm:"size:100"`
+	BookCover       string     `json:"book_cover" gorm:"size:1024"`
+	LibraryID       *uuid.UUID `json:"library_id,omitempty" gorm:"type:uuid;index"`
+	LibraryName     string     `json:"library_name,omitempty" gorm:"size:100"`
+	Rating          *float64   `json:"rating,omitempty" gorm:"type:numeric(2,1)"`
+	Visibility      string     `json:"visibility" gorm:"not null;size:20" validate:"required,oneof=public followers private"`
+	UserID          uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index:idx_activities_user_created,priority:1"`
+	User            User       `json:"-" gorm:"foreignKey:UserID"`
+	UserHandle      *string    `json:"handle" gorm:"->;-:migration"`
+	UserDisplayName string     `json:"display_name" gorm:"->;-:migration"`
+	CreatedAt       time.Time  `json:"created_at" gorm:"index:idx_activities_user_created,priority:2"`
+}
+
+// ActivitySetting is the visibility a user chose for their future activities of a kind.
+type ActivitySetting struct {
+	UserID     uuid.UUID `json:"-" gorm:"type:uuid;primaryKey"`
+	Kind       string    `json:"kind" gorm:"primaryKey;size:30" validate:"required,oneof=started_reading finished rated added_to_library"`
+	Visibility string    `json:"visibility" gorm:"not null;size:20" validate:"required,oneof=public followers private"`
+	User       User      `json:"-" gorm:"foreignKey:UserID"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// ActivityPage is a page of a feed, newest activities first. The next cursor is the ID of the last activity of
+// the page, to give as "before" to get the next page, and is empty on the last page.
+type ActivityPage struct {
+	Activities []Activity `json:"activities"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Author struct {
+	ID             uuid.UUID    `json:"id" gorm:"type:uuid;primaryKey" validate:"required,uuid4"`
+	Name           string       `json:"name" gorm:"not null;size:100;uniqueIndex:idx_authors_organization_name" validate:"required,min=1,max=100"`
+	SortName       string       `json:"sort_name" gorm:"not null;size:100;index" validate:"max=100"`
+	OrganizationID uuid.UUID    `json:"-" gorm:"type:uuid;uniqueIndex:idx_authors_organization_name"`
+	UserID         uuid.UUID    `json:"-" gorm:"type:uuid;not null;index"`
+	User           User         `json:"-" gorm:"foreignKey:UserID"`
+	Books          []BookAuthor `json:"books,omitempty" gorm:"foreignKey:AuthorID"`
+	BookCount      int64        `json:"book_count" gorm:"->;-:migration"`
+	CreatedAt      time.Time    `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time    `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+type BookAuthor struct {
+	BookID   uuid.UUID `json:"book_id" gorm:"type:uuid;primaryKey"`
+	AuthorID uuid.UUID `json:"author_id" gorm:"type:uuid;primaryKey;index"`
+	Role     string    `json:"role" gorm:"primaryKey;size:20" validate:"required,oneof=author translator editor illustrator"`
+	Position int       `json:"position" gorm:"not null;default:0"`
+	Author   *Author   `json:"author,omitempty" gorm:"foreignKey:AuthorID"`
+	Book     *Book     `json:"book,omitempty" gorm:"foreignKey:BookID"`
+}
+
+type AuthorCredit struct {
+	Name string `json:"name" validate:"required,min=1,max=100"`
+	Role string `json:"role" validate:"required,oneof=author translator editor illustrator"`
+}
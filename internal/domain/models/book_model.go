@@ -7,20 +7,29 @@ import (
 )
 
 type Book struct {
-	ID            uuid.UUID `json:"id" gorm:"type:uuid;primaryKey" validate:"required,uuid4"`
-	Title         string    `json:"title" gorm:"not null;size:100;index" validate:"required,min=1,max=100"`
-	Author        string    `json:"author" gorm:"not null;size:100;index" validate:"required,min=1,max=100"`
-	Description   string    `json:"description" gorm:"size:1024" validate:"max=1024"`
-	Cover         string    `json:"cover" gorm:"size:1024" validate:"max=1024,url"`
-	Genre         string    `json:"genre" gorm:"size:100;index" validate:"max=100"`
-	ISBN          string    `json:"isbn" gorm:"size:20;index" validate:"max=20"`
-	PublishedDate string    `json:"published_date" gorm:"size:20;index" validate:"max=20"`
-	Language      string    `json:"language" gorm:"size:10" validate:"max=10"`
-	Pages         int       `json:"pages" gorm:"default:0" validate:"min=0"`
-	Read          bool      `json:"read" gorm:"default:false"`
-	UserID        uuid.UUID `json:"-" gorm:"type:uuid;not null;index"`
-	User          User      `json:"-" gorm:"foreignKey:UserID"`
-	Libraries     []Library `json:"-" gorm:"many2many:book_library;"`
-	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt     time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	ID             uuid.UUID    `json:"id" gorm:"type:uuid;primaryKey" validate:"required,uuid4"`
+	Title          string       `json:"title" gorm:"not null;size:100;index" validate:"required,min=1,max=100"`
+	Author         string       `json:"author" gorm:"not null;size:100;index" validate:"required,min=1,max=100"`
+	Description    string       `json:"description" gorm:"size:1024" validate:"max=1024"`
+	Cover          string       `json:"cover" gorm:"size:1024" validate:"max=1024,url"`
+	Genre          string       `json:"genre" gorm:"size:100;index" validate:"max=100"`
+	ISBN           string       `json:"isbn" gorm:"size:20;index" validate:"max=20"`
+	PublishedDate  string       `json:"published_date" gorm:"size:20;index" validate:"max=20"`
+	Language       string       `json:"language" gorm:"size:10" validate:"max=10"`
+	Pages          int          `json:"pages" gorm:"default:0" validate:"min=0"`
+	Read           bool         `json:"read" gorm:"default:false"`
+	ReadAt         *time.Time   `json:"read_at" gorm:"index"`
+	AverageRating  *float64     `json:"average_rating,omitempty" gorm:"->;-:migration"`
+	RatingCount    int64        `json:"rating_count,omitempty" gorm:"->;-:migration"`
+	WorkID         *uuid.UUID   `json:"work_id" gorm:"type:uuid;index"`
+	OrganizationID uuid.UUID    `json:"-" gorm:"type:uuid;index"`
+	UserID         uuid.UUID    `json:"-" gorm:"type:uuid;not null;index"`
+	User           User         `json:"-" gorm:"foreignKey:UserID"`
+	Libraries      []Library    `json:"-" gorm:"many2many:book_library;"`
+	Authors        []BookAuthor `json:"authors" gorm:"foreignKey:BookID"`
+	Series         []BookSeries `json:"series,omitempty" gorm:"foreignKey:BookID"`
+	Tags           []Tag        `json:"tags" gorm:"many2many:book_tag;"`
+	Copies         []Copy       `json:"copies,omitempty" gorm:"foreignKey:BookID"`
+	CreatedAt      time.Time    `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time    `json:"updated_at" gorm:"autoUpdateTime"`
 }
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Borrower is a contact of an organization that books are lent to, so the same person is not retyped
+// with a different spelling on every loan.
+type Borrower struct {
+	ID             uuid.UUID `json:"id" gorm:"type:uuid;primaryKey" validate:"required,uuid4"`
+	Name           string    `json:"name" gorm:"not null;size:100" validate:"required,min=1,max=100"`
+	NormalizedName string    `json:"-" gorm:"not null;size:100;uniqueIndex:idx_borrowers_organization_name"`
+	Email          string    `json:"email" gorm:"size:100" validate:"omitempty,email,max=100"`
+	Phone          string    `json:"phone" gorm:"size:30" validate:"max=30"`
+	Notes          string    `json:"notes" gorm:"size:1024" validate:"max=1024"`
+	OrganizationID uuid.UUID `json:"-" gorm:"type:uuid;uniqueIndex:idx_borrowers_organization_name"`
+	UserID         uuid.UUID `json:"-" gorm:"type:uuid;not null;index"`
+	User           User      `json:"-" gorm:"foreignKey:UserID"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// BorrowerStats summarizes the loans of a borrower. A loan counts as overdue when it is still out after
+// its due date or was returned after it.
+type BorrowerStats struct {
+	TotalLoans        int64    `json:"total_loans"`
+	CurrentlyHolding  int64    `json:"currently_holding"`
+	OverdueCount      int64    `json:"overdue_count"`
+	AverageReturnDays *float64 `json:"average_return_days"`
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ClubRoles ranks the roles of the members of a book club. Members follow the schedule, record their
+// progress and discuss it, and owners also manage the club, its book, its schedule and its members.
+var ClubRoles = map[string]int{
+	"member": 1,
+	"owner":  2,
+}
+
+// Club is a book club reading a book together. The current book is chosen by an owner from their own books
+// and its title, author, cover and pages are copied into the club, since the other members cannot see the
+// books of the organization it comes from. The join code is only shown to the owners.
+type Club struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey" validate:"required,uuid4"`
+	Name        string     `json:"name" gorm:"not null;size:100" validate:"required,min=1,max=100"`
+	Description string     `json:"description" gorm:"size:500" validate:"max=500"`
+	JoinCode    string     `json:"join_code,omitempty" gorm:"not null;size:100;uniqueIndex"`
+	BookID      *uuid.UUID `json:"book_id" gorm:"type:uuid"`
+	BookTitle   string     `json:"book_title" gorm:"size:100"`
+	BookAuthor  string     `json:"book_author" gorm:"size:100"`
+	BookCover   string     `json:"book_cover" gorm:"size:1024"`
+	BookPages   int        `json:"book_pages" gorm:"default:0"`
+	Role        string     `json:"role,omitempty" gorm:"->;-:migration"`
+	Members     int64      `json:"members" gorm:"->;-:migration"`
+	UserID      uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	User        User       `json:"-" gorm:"foreignKey:UserID"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// ClubMember is a member of a book club with their progress in the current book, the last page they read.
+// A member can hide their progress from the other members, which still gates the spoilers they see.
+type ClubMember struct {
+	ClubID            uuid.UUID  `json:"club_id" gorm:"type:uuid;primaryKey"`
+	UserID            uuid.UUID  `json:"user_id" gorm:"type:uuid;primaryKey;index"`
+	Role              string     `json:"role" gorm:"not null;size:20" validate:"required,oneof=owner member"`
+	Page              *int       `json:"page" gorm:"not null;default:0"`
+	ProgressVisible   bool       `json:"progress_visible" gorm:"not null;default:true"`
+	ProgressUpdatedAt *time.Time `json:"progress_updated_at"`
+	Handle            *string    `json:"handle" gorm:"->;-:migration"`
+	DisplayName       string     `json:"display_name" gorm:"->;-:migration"`
+	User              User       `json:"-" gorm:"foreignKey:UserID"`
+	Club              Club       `json:"-" gorm:"foreignKey:ClubID"`
+	CreatedAt         time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// ClubSection is a part of the reading schedule of a book club: the pages of the current book to read
+// between two dates, with its own discussion. A section is unlocked for a member once they reached its
+// first page, and read once they reached its last page.
+type ClubSection struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey" validate:"required,uuid4"`
+	ClubID    uuid.UUID `json:"club_id" gorm:"type:uuid;not null;index"`
+	Title     string    `json:"title" gorm:"not null;size:100" validate:"required,min=1,max=100"`
+	StartPage int       `json:"start_page" gorm:"not null" validate:"min=0"`
+	EndPage   int       `json:"end_page" gorm:"not null" validate:"gtefield=StartPage"`
+	StartsOn  string    `json:"starts_on" gorm:"not null;size:20" validate:"required,datetime=2006-01-02"`
+	EndsOn    string    `json:"ends_on" gorm:"not null;size:20" validate:"required,datetime=2006-01-02"`
+	Posts     int64     `json:"posts" gorm:"->;-:migration"`
+	Unlocked  bool      `json:"unlocked" gorm:"-"`
+	Read      bool      `json:"read" gorm:"-"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// ClubPost is a message of the discussion of a section of the schedule of a book club. It remembers the
+// progress of its author when they wrote it: the other members only see it once they read as far, or read
+// the whole section, and until then it is a spoiler without its body.
+type ClubPost struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primaryKey" validate:"required,uuid4"`
+	SectionID   uuid.UUID `json:"section_id" gorm:"type:uuid;not null;index"`
+	Body        string    `json:"body,omitempty" gorm:"not null;size:5000" validate:"required,min=1,max=5000"`
+	Page        int       `json:"-" gorm:"not null;default:0"`
+	Spoiler     bool      `json:"spoiler" gorm:"-"`
+	UserID      uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	User        User      `json:"-" gorm:"foreignKey:UserID"`
+	Handle      *string   `json:"handle" gorm:"->;-:migration"`
+	DisplayName string    `json:"display_name" gorm:"->;-:migration"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Copy is a physical item of an edition. Loans are made of copies, and a copy sits in at most one
+// library at a time. The price paid and the current value of a copy are kept in their own currency.
+type Copy struct {
+	ID             uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey" validate:"required,uuid4"`
+	BookID         uuid.UUID  `json:"book_id" gorm:"type:uuid;not null;index"`
+	Condition      string     `json:"condition" gorm:"size:20" validate:"omitempty,oneof=new fine good fair poor"`
+	Location       string     `json:"location" gorm:"size:100" validate:"max=100"`
+	LibraryID      *uuid.UUID `json:"library_id" gorm:"type:uuid;index"`
+	AcquiredOn     string     `json:"acquired_on" gorm:"size:20" validate:"omitempty,datetime=2006-01-02"`
+	AcquiredFrom   string     `json:"acquired_from" gorm:"size:100" validate:"max=100"`
+	GiftFrom       string     `json:"gift_from" gorm:"size:100" validate:"max=100"`
+	Price          float64    `json:"price" gorm:"type:numeric(12,2);not null;default:0" validate:"min=0"`
+	Currency       string     `json:"currency" gorm:"size:3" validate:"required_unless=Price 0,omitempty,iso4217"`
+	Value          float64    `json:"value" gorm:"type:numeric(12,2);not null;default:0" validate:"min=0"`
+	ValueCurrency  string     `json:"value_currency" gorm:"size:3" validate:"required_unless=Value 0,omitempty,iso4217"`
+	ValuedOn       string     `json:"valued_on" gorm:"size:20" validate:"omitempty,datetime=2006-01-02"`
+	Notes          string     `json:"notes" gorm:"size:1024" validate:"max=1024"`
+	OrganizationID uuid.UUID  `json:"-" gorm:"type:uuid;index"`
+	UserID         uuid.UUID  `json:"-" gorm:"type:uuid;not null;index"`
+	User           User       `json:"-" gorm:"foreignKey:UserID"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExchangeRate is a conversion rate supplied by an organization: one unit of FromCurrency is worth Rate
+// units of ToCurrency. A rate is also used the other way around, with its inverse.
+type ExchangeRate struct {
+	ID             uuid.UUID `json:"id" gorm:"type:uuid;primaryKey" validate:"required,uuid4"`
+	FromCurrency   string    `json:"from_currency" gorm:"not null;size:3;uniqueIndex:idx_exchange_rates_organization_pair" validate:"required,iso4217"`
+	ToCurrency     string    `json:"to_currency" gorm:"not null;size:3;uniqueIndex:idx_exchange_rates_organization_pair" validate:"required,iso4217,nefield=FromCurrency"`
+	Rate           float64   `json:"rate" gorm:"not null" validate:"required,gt=0"`
+	OrganizationID uuid.UUID `json:"-" gorm:"type:uuid;uniqueIndex:idx_exchange_rates_organization_pair"`
+	UserID         uuid.UUID `json:"-" gorm:"type:uuid;not null;index"`
+	User           User      `json:"-" gorm:"foreignKey:UserID"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The statuses of a follow. Following a public profile is accepted at once, while following a private
+// one is pending until its user approves it.
+const (
+	FollowStatusPending  = "pending"
+	FollowStatusAccepted = "accepted"
+)
+
+// Follow is a user following another one, to see their activity in their feed once accepted.
+type Follow struct {
+	FollowerID uuid.UUID `json:"-" gorm:"type:uuid;primaryKey"`
+	FollowedID uuid.UUID `json:"-" gorm:"type:uuid;primaryKey;index"`
+	Status     string    `json:"status" gorm:"not null;size:20"`
+	Follower   User      `json:"-" gorm:"foreignKey:FollowerID"`
+	Followed   User      `json:"-" gorm:"foreignKey:FollowedID"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// FollowUser is a user on the other side of a follow, a follower or a followed user, with the status of the follow.
+type FollowUser struct {
+	ID          uuid.UUID `json:"id"`
+	Handle      *string   `json:"handle"`
+	DisplayName string    `json:"display_name"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
+}
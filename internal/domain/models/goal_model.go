@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The statuses of a reading goal. A goal is on track while the books or pages read are at least the
+// share of the target matching the share of the year elapsed, in the timezone of the user.
+const (
+	GoalStatusNotStarted = "not_started"
+	GoalStatusOnTrack    = "on_track"
+	GoalStatusBehind     = "behind"
+	GoalStatusAchieved   = "achieved"
+	GoalStatusMissed     = "missed"
+)
+
+// ReadingGoal is the number of books, or of pages, a user wants to read in a year. The progress counts the
+// books of the organization marked as read during the year.
+type ReadingGoal struct {
+	ID             uuid.UUID `json:"id" gorm:"type:uuid;primaryKey" validate:"required,uuid4"`
+	Year           int       `json:"year" gorm:"not null;uniqueIndex:idx_reading_goals_year" validate:"min=1900,max=9999"`
+	Kind           string    `json:"kind" gorm:"not null;size:20;uniqueIndex:idx_reading_goals_year" validate:"required,oneof=books pages"`
+	Target         int       `json:"target" gorm:"not null" validate:"required,min=1,max=1000000"`
+	OrganizationID uuid.UUID `json:"-" gorm:"type:uuid;not null;uniqueIndex:idx_reading_goals_year"`
+	UserID         uuid.UUID `json:"-" gorm:"type:uuid;not null;uniqueIndex:idx_reading_goals_year"`
+	User           User      `json:"-" gorm:"foreignKey:UserID"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// GoalProgress is where a user stands with a reading goal. The expected progress is the share of the target
+// matching the share of the year elapsed, and the projection the progress at the end of the year at the
+// current pace. The needed weekly pace is what is left to read per remaining week to achieve the goal.
+type GoalProgress struct {
+	Kind          string  `json:"kind"`
+	Target        int     `json:"target"`
+	Progress      int64   `json:"progress"`
+	Percent       float64 `json:"percent"`
+	Expected      float64 `json:"expected"`
+	Projected     float64 `json:"projected"`
+	NeededPerWeek float64 `json:"needed_per_week"`
+	Status        string  `json:"status"`
+}
+
+// ReadingStreak is the number of consecutive days a user read on. The current streak ends today, or yesterday
+// when the user did not read yet today, and the longest streak is the longest one of the year.
+type ReadingStreak struct {
+	Current    int    `json:"current"`
+	Longest    int    `json:"longest"`
+	LastReadOn string `json:"last_read_on,omitempty"`
+}
+
+// YearSummary sums up the reading of a user in a year: the goals, the books finished, the time spent in reading
+// sessions, in seconds, the days read on and the reading streaks.
+type YearSummary struct {
+	Year        int            `json:"year"`
+	Timezone    string         `json:"timezone"`
+	Goals       []GoalProgress `json:"goals"`
+	BooksRead   int64          `json:"books_read"`
+	PagesRead   int64          `json:"pages_read"`
+	ReadingTime int64          `json:"reading_time"`
+	DaysRead    int            `json:"days_read"`
+	Streak      ReadingStreak  `json:"streak"`
+	Books       []Book         `json:"books"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The statuses of a hold. Holds wait in the queue of a book in the order they were placed. When the
+// book is returned the first waiting hold becomes ready and its user has HoldPickupTTL to borrow the
+// book before the hold expires and the next one in the queue becomes ready.
+const (
+	HoldStatusWaiting   = "waiting"
+	HoldStatusReady     = "ready"
+	HoldStatusFulfilled = "fulfilled"
+	HoldStatusExpired   = "expired"
+	HoldStatusCancelled = "cancelled"
+)
+
+// HoldPickupTTL is how long a ready hold is kept for its user.
+const HoldPickupTTL = 3 * 24 * time.Hour
+
+type Hold struct {
+	ID             uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey" validate:"required,uuid4"`
+	BookID         string     `json:"book_id" gorm:"not null;size:36;index" validate:"required,min=1,max=36"`
+	BookTitle      string     `json:"book_title,omitempty" gorm:"->;-:migration"`
+	Status         string     `json:"status" gorm:"not null;size:20;default:waiting;index"`
+	Position       int        `json:"position,omitempty" gorm:"->;-:migration"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+	OrganizationID uuid.UUID  `json:"organization_id" gorm:"type:uuid;index"`
+	UserID         uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	User           User       `json:"-" gorm:"foreignKey:UserID"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
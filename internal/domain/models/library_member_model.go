@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LibraryRoles ranks the roles of the members of a shared library. A higher rank includes
+// every permission of the lower ones: viewers read the library, editors change its books
+// and owners also manage its members.
+var LibraryRoles = map[string]int{
+	"viewer": 1,
+	"editor": 2,
+	"owner":  3,
+}
+
+type LibraryMember struct {
+	LibraryID uuid.UUID `json:"library_id" gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;primaryKey;index"`
+	Role      string    `json:"role" gorm:"not null;size:20" validate:"required,oneof=owner editor viewer"`
+	User      User      `json:"-" gorm:"foreignKey:UserID"`
+	Library   Library   `json:"-" gorm:"foreignKey:LibraryID"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+type LibraryInvitation struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primaryKey" validate:"required,uuid4"`
+	LibraryID   uuid.UUID `json:"library_id" gorm:"type:uuid;not null;index"`
+	Library     Library   `json:"-" gorm:"foreignKey:LibraryID"`
+	Email       string    `json:"email" gorm:"not null;size:100;index" validate:"required,email,max=100"`
+	Role        string    `json:"role" gorm:"not null;size:20" validate:"required,oneof=owner editor viewer"`
+	Status      string    `json:"status" gorm:"not null;size:20;default:pending"`
+	Token       string    `json:"-" gorm:"not null;size:100;uniqueIndex"`
+	InvitedByID uuid.UUID `json:"invited_by_id" gorm:"type:uuid;not null"`
+	ExpiresAt   time.Time `json:"expires_at" gorm:"not null"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
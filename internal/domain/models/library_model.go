@@ -7,12 +7,33 @@ import (
 )
 
 type Library struct {
-	ID          uuid.UUID `json:"id" gorm:"type:uuid;primaryKey" validate:"required,uuid4"`
-	Name        string    `json:"name" gorm:"not null;size:100" validate:"required,min=1,max=100"`
-	Description string    `json:"description" gorm:"size:1024" validate:"max=1024"`
-	UserID      uuid.UUID `json:"-" gorm:"type:uuid;not null;index"`
-	User        User      `json:"-" gorm:"foreignKey:UserID"`
-	Books       []Book    `json:"books" gorm:"many2many:book_library;"`
-	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	ID             uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey" validate:"required,uuid4"`
+	Name           string     `json:"name" gorm:"not null;size:100" validate:"required,min=1,max=100"`
+	Description    string     `json:"description" gorm:"size:1024" validate:"max=1024"`
+	Rule           string     `json:"rule" gorm:"size:1024" validate:"max=1024"`
+	ParentID       *uuid.UUID `json:"parent_id" gorm:"type:uuid;index"`
+	Children       []Library  `json:"children,omitempty" gorm:"foreignKey:ParentID"`
+	Public         bool       `json:"public" gorm:"not null;default:false"`
+	Role           string     `json:"role,omitempty" gorm:"->;-:migration"`
+	OrganizationID uuid.UUID  `json:"-" gorm:"type:uuid;index"`
+	UserID         uuid.UUID  `json:"-" gorm:"type:uuid;not null;index"`
+	User           User       `json:"-" gorm:"foreignKey:UserID"`
+	Books          []Book     `json:"books" gorm:"many2many:book_library;"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// LibraryPositionGap is the distance between the positions of consecutive books appended to a library.
+// Moving a book only rewrites its own position, picking the midpoint between its new neighbours.
+const LibraryPositionGap = 1024.0
+
+type BookLibrary struct {
+	LibraryID uuid.UUID `json:"library_id" gorm:"type:uuid;primaryKey"`
+	BookID    uuid.UUID `json:"book_id" gorm:"type:uuid;primaryKey"`
+	Position  float64   `json:"position" gorm:"not null;default:0;index"`
+}
+
+// TableName returns the name of the join table between books and libraries.
+func (BookLibrary) TableName() string {
+	return "book_library"
 }
@@ -6,13 +6,35 @@ import (
 	"github.com/google/uuid"
 )
 
+// The statuses of a loan. Loans created by the lender start active, while borrow requests of other
+// users start requested until the lender approves or declines them. A borrower marks a loan as
+// return pending, and the loan is returned once the lender confirms it.
+const (
+	LoanStatusRequested     = "requested"
+	LoanStatusDeclined      = "declined"
+	LoanStatusActive        = "active"
+	LoanStatusReturnPending = "return_pending"
+	LoanStatusReturned      = "returned"
+)
+
+// LoanRequestIndex is the name of the unique index allowing a borrower a single pending request per book.
+const LoanRequestIndex = "idx_loans_pending_request"
+
 type Loan struct {
-	ID           uuid.UUID `json:"id" gorm:"type:uuid;primaryKey" validate:"required,uuid4"`
-	BookID       string    `json:"book_id" gorm:"not null;size:36;index" validate:"required,min=1,max=36"`
-	LoanDate     string    `json:"loan_date" gorm:"not null;size:20" validate:"required,min=1,max=20"`
-	BorrowerName string    `json:"borrower_name" gorm:"not null;size:100;index" validate:"required,min=1,max=100"`
-	IsReturned   bool      `json:"is_returned" gorm:"default:false"`
-	UserID       uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
-	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt    time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	ID                uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey" validate:"required,uuid4"`
+	BookID            string     `json:"book_id" gorm:"not null;size:36;index" validate:"required,min=1,max=36"`
+	CopyID            *uuid.UUID `json:"copy_id" gorm:"type:uuid;index"`
+	LoanDate          string     `json:"loan_date" gorm:"not null;size:20" validate:"required,min=1,max=20"`
+	DueDate           string     `json:"due_date" gorm:"size:20" validate:"omitempty,datetime=2006-01-02"`
+	ReturnedAt        *time.Time `json:"returned_at"`
+	BorrowerName      string     `json:"borrower_name" gorm:"not null;size:100;index" validate:"required_without=BorrowerContactID,max=100"`
+	BorrowerContactID *uuid.UUID `json:"borrower_contact_id" gorm:"type:uuid;index"`
+	BorrowerContact   *Borrower  `json:"borrower_contact,omitempty" gorm:"foreignKey:BorrowerContactID"`
+	BorrowerID        *uuid.UUID `json:"borrower_id" gorm:"type:uuid;index"`
+	Status            string     `json:"status" gorm:"not null;size:20;default:active;index"`
+	IsReturned        bool       `json:"is_returned" gorm:"default:false"`
+	OrganizationID    uuid.UUID  `json:"organization_id" gorm:"type:uuid;index"`
+	UserID            uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	CreatedAt         time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
 }
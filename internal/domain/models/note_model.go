@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Note is a passage highlighted in a book, or a note written about it, with where it is in the book.
+// Notes are private to the user who wrote them and can be tagged with the tags of the organization.
+// Notes imported from an e-reader keep the name of the e-reader and a fingerprint of the clipping,
+// so importing the same clippings again does not duplicate them.
+type Note struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primaryKey" validate:"required,uuid4"`
+	BookID      uuid.UUID `json:"book_id" gorm:"type:uuid;not null;index"`
+	BookTitle   string    `json:"book_title,omitempty" gorm:"->;-:migration"`
+	Kind        string    `json:"kind" gorm:"not null;size:20;default:highlight" validate:"required,oneof=highlight note"`
+	Text        string    `json:"text" gorm:"type:text;not null" validate:"required,min=1,max=10000"`
+	Comment     string    `json:"comment" gorm:"type:text;not null;default:''" validate:"max=10000"`
+	Page        int       `json:"page" gorm:"default:0" validate:"min=0"`
+	Location    string    `json:"location" gorm:"size:50" validate:"max=50"`
+	Tags        []Tag     `json:"tags" gorm:"many2many:note_tag;"`
+	Source      string    `json:"source,omitempty" gorm:"size:20"`
+	Fingerprint string    `json:"-" gorm:"size:64;index"`
+	UserID      uuid.UUID `json:"-" gorm:"type:uuid;not null;index"`
+	User        User      `json:"-" gorm:"foreignKey:UserID"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// NoteImportResult counts what an import of e-reader clippings did.
+type NoteImportResult struct {
+	Imported     int `json:"imported"`
+	Duplicates   int `json:"duplicates"`
+	BooksCreated int `json:"books_created"`
+	Skipped      int `json:"skipped"`
+}
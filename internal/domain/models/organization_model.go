@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrganizationRoles ranks the roles of the members of an organization. Members manage the books,
+// libraries and loans of the organization, admins also manage its members and settings, and
+// owners can also delete it.
+var OrganizationRoles = map[string]int{
+	"member": 1,
+	"admin":  2,
+	"owner":  3,
+}
+
+type Organization struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey" validate:"required,uuid4"`
+	Name      string    `json:"name" gorm:"not null;size:100" validate:"required,min=1,max=100"`
+	Personal  bool      `json:"personal" gorm:"not null;default:false"`
+	Role      string    `json:"role,omitempty" gorm:"->;-:migration"`
+	UserID    uuid.UUID `json:"-" gorm:"type:uuid;not null;index"`
+	User      User      `json:"-" gorm:"foreignKey:UserID"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+type OrganizationMember struct {
+	OrganizationID uuid.UUID    `json:"organization_id" gorm:"type:uuid;primaryKey"`
+	UserID         uuid.UUID    `json:"user_id" gorm:"type:uuid;primaryKey;index"`
+	Role           string       `json:"role" gorm:"not null;size:20" validate:"required,oneof=owner admin member"`
+	User           User         `json:"-" gorm:"foreignKey:UserID"`
+	Organization   Organization `json:"-" gorm:"foreignKey:OrganizationID"`
+	CreatedAt      time.Time    `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time    `json:"updated_at" gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReadingSessionMaxDuration is how long a reading session may last when the READING_SESSION_MAX_DURATION
+// environment variable is not set. A session in progress for longer was forgotten, so it is stopped
+// automatically at its start time plus the maximum duration.
+const ReadingSessionMaxDuration = 4 * time.Hour
+
+// ReadingSessionInProgressIndex is the name of the unique index allowing a user a single reading session in progress.
+const ReadingSessionInProgressIndex = "idx_reading_sessions_in_progress"
+
+// ReadingSession is a period a user spent reading a book, from a start page to an end page. A session is in
+// progress until it is stopped, and a user reads one book at a time. The duration is in seconds.
+type ReadingSession struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey" validate:"required,uuid4"`
+	BookID      uuid.UUID  `json:"book_id" gorm:"type:uuid;not null;index"`
+	BookTitle   string     `json:"book_title,omitempty" gorm:"->;-:migration"`
+	StartPage   int        `json:"start_page" gorm:"default:0" validate:"min=0"`
+	EndPage     *int       `json:"end_page" validate:"omitempty,min=0"`
+	StartedAt   time.Time  `json:"started_at" gorm:"not null;index"`
+	EndedAt     *time.Time `json:"ended_at"`
+	Duration    int64      `json:"duration" gorm:"default:0" validate:"min=0"`
+	AutoStopped bool       `json:"auto_stopped" gorm:"default:false"`
+	UserID      uuid.UUID  `json:"-" gorm:"type:uuid;not null;index"`
+	User        User       `json:"-" gorm:"foreignKey:UserID"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// BookReadingStats sums up the reading sessions of a user on a book. The reading speed and the estimated
+// time to finish the book, in seconds, are only known once the user read pages in stopped sessions, and
+// the estimate also needs the number of pages of the book.
+type BookReadingStats struct {
+	BookID           uuid.UUID `json:"book_id"`
+	Pages            int       `json:"pages"`
+	CurrentPage      int       `json:"current_page"`
+	PagesRead        int64     `json:"pages_read"`
+	Duration         int64     `json:"duration"`
+	Sessions         int64     `json:"sessions"`
+	PagesPerHour     *float64  `json:"pages_per_hour"`
+	RemainingPages   *int      `json:"remaining_pages"`
+	EstimatedSeconds *int64    `json:"estimated_seconds"`
+}
+
+// ReadingDay is the reading time, in seconds, and the pages read by a user on a day.
+type ReadingDay struct {
+	Date     string `json:"date"`
+	Duration int64  `json:"duration"`
+	Pages    int64  `json:"pages"`
+	Sessions int64  `json:"sessions"`
+}
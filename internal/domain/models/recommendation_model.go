@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecommendationBook is a book of the collection of a user with what the recommendations learn from: whether it
+// was read, the rating the user gave it, its credited authors and the series it belongs to.
+type RecommendationBook struct {
+	ID        uuid.UUID              `json:"id"`
+	Title     string                 `json:"title"`
+	Author    string                 `json:"author"`
+	Cover     string                 `json:"cover"`
+	Genre     string                 `json:"genre"`
+	Pages     int                    `json:"pages"`
+	Read      bool                   `json:"read"`
+	Rating    *float64               `json:"rating"`
+	Authors   []string               `json:"authors" gorm:"-"`
+	Series    []RecommendationSeries `json:"series" gorm:"-"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// RecommendationSeries is the position of a book in a series.
+type RecommendationSeries struct {
+	BookID   uuid.UUID `json:"-"`
+	SeriesID uuid.UUID `json:"series_id"`
+	Name     string    `json:"name"`
+	Position float64   `json:"position"`
+}
+
+// Recommendation is an unread book of the collection of a user suggested to read next, with its score and the
+// reasons it was suggested for, most important first.
+type Recommendation struct {
+	BookID  uuid.UUID `json:"book_id"`
+	Title   string    `json:"title"`
+	Author  string    `json:"author"`
+	Cover   string    `json:"cover"`
+	Genre   string    `json:"genre"`
+	Pages   int       `json:"pages"`
+	Score   float64   `json:"score"`
+	Reasons []string  `json:"reasons"`
+}
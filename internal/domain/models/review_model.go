@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Review is the rating of a book by a user, from 0.5 to 5 stars in half-star steps, with an optional
+// Markdown review. Reviews are private: only their author sees the text, while the ratings of every
+// user count towards the average rating of the book.
+type Review struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey" validate:"required,uuid4"`
+	BookID    uuid.UUID `json:"book_id" gorm:"type:uuid;not null;uniqueIndex:idx_reviews_book_user"`
+	BookTitle string    `json:"book_title,omitempty" gorm:"->;-:migration"`
+	Rating    float64   `json:"rating" gorm:"type:numeric(2,1);not null" validate:"required,min=0.5,max=5"`
+	Body      string    `json:"body" gorm:"type:text" validate:"max=20000"`
+	HTML      string    `json:"html,omitempty" gorm:"-"`
+	UserID    uuid.UUID `json:"-" gorm:"type:uuid;not null;uniqueIndex:idx_reviews_book_user;index"`
+	User      User      `json:"-" gorm:"foreignKey:UserID"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
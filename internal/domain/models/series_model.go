@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Series struct {
+	ID             uuid.UUID    `json:"id" gorm:"type:uuid;primaryKey" validate:"required,uuid4"`
+	Name           string       `json:"name" gorm:"not null;size:100;index" validate:"required,min=1,max=100"`
+	Description    string       `json:"description" gorm:"size:1024" validate:"max=1024"`
+	TotalVolumes   int          `json:"total_volumes" gorm:"default:0" validate:"min=0"`
+	OrganizationID uuid.UUID    `json:"-" gorm:"type:uuid;index"`
+	UserID         uuid.UUID    `json:"-" gorm:"type:uuid;not null;index"`
+	User           User         `json:"-" gorm:"foreignKey:UserID"`
+	Books          []BookSeries `json:"-" gorm:"foreignKey:SeriesID"`
+	BookCount      int64        `json:"book_count" gorm:"->;-:migration"`
+	ReadCount      int64        `json:"read_count" gorm:"->;-:migration"`
+	CreatedAt      time.Time    `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time    `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+type BookSeries struct {
+	BookID   uuid.UUID `json:"book_id" gorm:"type:uuid;primaryKey"`
+	SeriesID uuid.UUID `json:"series_id" gorm:"type:uuid;primaryKey;index"`
+	Position float64   `json:"position" gorm:"not null;default:0" validate:"min=0"`
+	Book     *Book     `json:"book,omitempty" gorm:"foreignKey:BookID"`
+	Series   *Series   `json:"series,omitempty" gorm:"foreignKey:SeriesID"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import "github.com/google/uuid"
+
+// CollectionStats is the aggregate view of the books of an organization.
+type CollectionStats struct {
+	Totals        StatsTotals  `json:"totals"`
+	Genres        []StatsGroup `json:"genres"`
+	Languages     []StatsGroup `json:"languages"`
+	Authors       []StatsGroup `json:"authors"`
+	Decades       []StatsGroup `json:"decades"`
+	Libraries     []StatsGroup `json:"libraries"`
+	Loans         LoanStats    `json:"loans"`
+	AddedPerMonth []StatsGroup `json:"added_per_month"`
+	Longest       []Book       `json:"longest"`
+	Shortest      []Book       `json:"shortest"`
+}
+
+// StatsTotals counts the books of an organization, their pages and their copies.
+type StatsTotals struct {
+	Books  int64 `json:"books"`
+	Pages  int64 `json:"pages"`
+	Read   int64 `json:"read"`
+	Unread int64 `json:"unread"`
+	Copies int64 `json:"copies"`
+}
+
+// StatsGroup counts the books sharing a value, such as a genre or a decade, their pages and how many of them
+// were read. Groups of authors and libraries also have the ID of the author or library. An empty key groups
+// the books without a value.
+type StatsGroup struct {
+	Key   string     `json:"key"`
+	ID    *uuid.UUID `json:"id,omitempty"`
+	Books int64      `json:"books"`
+	Pages int64      `json:"pages"`
+	Read  int64      `json:"read"`
+}
+
+// LoanStats counts the loans of an organization still out, and those of them past their due date.
+type LoanStats struct {
+	Out     int64 `json:"out"`
+	Overdue int64 `json:"overdue"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Tag struct {
+	ID             uuid.UUID `json:"id" gorm:"type:uuid;primaryKey" validate:"required,uuid4"`
+	Name           string    `json:"name" gorm:"not null;size:50" validate:"required,min=1,max=50"`
+	NormalizedName string    `json:"-" gorm:"not null;size:50;uniqueIndex:idx_tags_organization_name"`
+	Color          string    `json:"color" gorm:"size:9" validate:"omitempty,hexcolor"`
+	OrganizationID uuid.UUID `json:"-" gorm:"type:uuid;uniqueIndex:idx_tags_organization_name"`
+	UserID         uuid.UUID `json:"-" gorm:"type:uuid;not null;index"`
+	User           User      `json:"-" gorm:"foreignKey:UserID"`
+	Books          []Book    `json:"-" gorm:"many2many:book_tag;"`
+	BookCount      int64     `json:"book_count" gorm:"->;-:migration"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
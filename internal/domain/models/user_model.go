@@ -8,12 +8,17 @@ import (
 )
 
 type User struct {
-	ID        uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey"`
-	Email     string         `json:"email" gorm:"unique;not null;size:100" validate:"required,min=1,max=100"`
-	Password  string         `json:"password" gorm:"not null;size:100" validate:"required,min=1,max=100"`
-	Books     []Book         `json:"books" gorm:"foreignKey:UserID"`
-	Libraries []Library      `json:"libraries" gorm:"foreignKey:UserID"`
-	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
-	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+	ID          uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey"`
+	Email       string         `json:"email" gorm:"unique;not null;size:100" validate:"required,min=1,max=100"`
+	Password    string         `json:"password" gorm:"not null;size:100" validate:"required,min=1,max=100"`
+	Timezone    string         `json:"timezone" gorm:"not null;size:64;default:UTC" validate:"omitempty,timezone"`
+	Handle      *string        `json:"handle" gorm:"size:30;uniqueIndex"`
+	DisplayName string         `json:"display_name" gorm:"size:100"`
+	Bio         string         `json:"bio" gorm:"size:500"`
+	Public      bool           `json:"public" gorm:"not null;default:false"`
+	Books       []Book         `json:"books" gorm:"foreignKey:UserID"`
+	Libraries   []Library      `json:"libraries" gorm:"foreignKey:UserID"`
+	CreatedAt   time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt   gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 }
@@ -0,0 +1,35 @@
+package models
+
+import "github.com/google/uuid"
+
+// ValuationGroup sums the prices and values of the copies of an organization sharing the same library,
+// acquisition year and currencies.
+type ValuationGroup struct {
+	LibraryID     *uuid.UUID
+	LibraryName   string
+	Year          string
+	Currency      string
+	ValueCurrency string
+	Copies        int64
+	Paid          float64
+	Value         float64
+}
+
+// CopyValuation is a copy with the details of its book and library, as listed in a valuation export.
+type CopyValuation struct {
+	CopyID        uuid.UUID
+	Title         string
+	Author        string
+	ISBN          string
+	LibraryName   string
+	Condition     string
+	Location      string
+	AcquiredOn    string
+	AcquiredFrom  string
+	GiftFrom      string
+	Price         float64
+	Currency      string
+	Value         float64
+	ValueCurrency string
+	ValuedOn      string
+}
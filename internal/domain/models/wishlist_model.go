@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WishlistItem is a book an organization wants but does not own yet. Items are ranked by priority,
+// from 5 (most wanted) to 1. Buying an item turns it into an owned book, which the item then links to.
+type WishlistItem struct {
+	ID             uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey" validate:"required,uuid4"`
+	Title          string         `json:"title" gorm:"not null;size:100" validate:"required,min=1,max=100"`
+	Author         string         `json:"author" gorm:"not null;size:100" validate:"required,min=1,max=100"`
+	ISBN           string         `json:"isbn" gorm:"size:20" validate:"max=20"`
+	Cover          string         `json:"cover" gorm:"size:1024" validate:"max=1024,url"`
+	Priority       int            `json:"priority" gorm:"not null;default:3" validate:"min=1,max=5"`
+	Notes          string         `json:"notes" gorm:"size:1024" validate:"max=1024"`
+	TargetPrice    float64        `json:"target_price" gorm:"type:numeric(12,2);not null;default:0" validate:"min=0"`
+	Currency       string         `json:"currency" gorm:"size:3" validate:"omitempty,iso4217"`
+	Links          []WishlistLink `json:"links" gorm:"foreignKey:WishlistItemID" validate:"max=10,dive"`
+	BookID         *uuid.UUID     `json:"book_id" gorm:"type:uuid;index"`
+	BoughtAt       *time.Time     `json:"bought_at"`
+	OrganizationID uuid.UUID      `json:"-" gorm:"type:uuid;index"`
+	UserID         uuid.UUID      `json:"-" gorm:"type:uuid;not null;index"`
+	User           User           `json:"-" gorm:"foreignKey:UserID"`
+	CreatedAt      time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// WishlistLink is a place where a wishlist item can be bought.
+type WishlistLink struct {
+	ID             uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	WishlistItemID uuid.UUID `json:"-" gorm:"type:uuid;not null;index"`
+	Label          string    `json:"label" gorm:"size:100" validate:"max=100"`
+	URL            string    `json:"url" gorm:"not null;size:1024" validate:"required,max=1024,url"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// WishlistShare is the public link of the wishlist of an organization. Anyone with the token can see
+// the items that were not bought yet, without their notes.
+type WishlistShare struct {
+	OrganizationID uuid.UUID `json:"-" gorm:"type:uuid;primaryKey"`
+	Token          string    `json:"-" gorm:"not null;size:100;uniqueIndex"`
+	UserID         uuid.UUID `json:"-" gorm:"type:uuid;not null;index"`
+	User           User      `json:"-" gorm:"foreignKey:UserID"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Work is a book as written by its authors, whatever the edition. Every edition of the work (a Book)
+// points to it, so a hardcover and a paperback of the same novel are grouped together.
+type Work struct {
+	ID             uuid.UUID `json:"id" gorm:"type:uuid;primaryKey" validate:"required,uuid4"`
+	Title          string    `json:"title" gorm:"not null;size:100;index" validate:"required,min=1,max=100"`
+	Author         string    `json:"author" gorm:"size:100" validate:"max=100"`
+	OrganizationID uuid.UUID `json:"-" gorm:"type:uuid;index"`
+	UserID         uuid.UUID `json:"-" gorm:"type:uuid;not null;index"`
+	User           User      `json:"-" gorm:"foreignKey:UserID"`
+	Editions       []Book    `json:"editions,omitempty" gorm:"foreignKey:WorkID"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// YearReview is the shareable summary of the reading of a user in a year: the books of the organization
+// finished during the year, the pages read, the time spent in reading sessions, in seconds, the top genres
+// and authors, the longest and highest-rated books and the books and pages read month by month.
+type YearReview struct {
+	Year         int               `json:"year"`
+	Name         string            `json:"name"`
+	Timezone     string            `json:"timezone"`
+	BooksRead    int64             `json:"books_read"`
+	PagesRead    int64             `json:"pages_read"`
+	ReadingTime  int64             `json:"reading_time"`
+	Genres       []StatsGroup      `json:"genres"`
+	Authors      []StatsGroup      `json:"authors"`
+	Longest      *YearReviewBook   `json:"longest"`
+	HighestRated *YearReviewBook   `json:"highest_rated"`
+	Months       []YearReviewMonth `json:"months"`
+	Books        []YearReviewBook  `json:"books"`
+}
+
+// YearReviewBook is a book finished during the year of a review, with the rating the user gave it, if any.
+type YearReviewBook struct {
+	ID     uuid.UUID `json:"id"`
+	Title  string    `json:"title"`
+	Author string    `json:"author"`
+	Cover  string    `json:"cover"`
+	Genre  string    `json:"genre"`
+	Pages  int       `json:"pages"`
+	Rating *float64  `json:"rating"`
+	ReadAt time.Time `json:"read_at"`
+}
+
+// YearReviewMonth counts the books finished in a month of the year of a review, as YYYY-MM, and their pages.
+type YearReviewMonth struct {
+	Month string `json:"month"`
+	Books int64  `json:"books"`
+	Pages int64  `json:"pages"`
+}
+
+// YearReviewShare is the public link of the year review of a user in an organization. Anyone with the token
+// can see the review, and sharing it again replaces the token.
+type YearReviewShare struct {
+	ID             uuid.UUID `json:"-" gorm:"type:uuid;primaryKey"`
+	Year           int       `json:"year" gorm:"not null;uniqueIndex:idx_year_review_shares_year"`
+	Token          string    `json:"-" gorm:"not null;size:100;uniqueIndex"`
+	OrganizationID uuid.UUID `json:"-" gorm:"type:uuid;not null;uniqueIndex:idx_year_review_shares_year"`
+	UserID         uuid.UUID `json:"-" gorm:"type:uuid;not null;uniqueIndex:idx_year_review_shares_year"`
+	User           User      `json:"-" gorm:"foreignKey:UserID"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
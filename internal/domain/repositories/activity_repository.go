@@ -0,0 +1,285 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"mybooks/internal/domain/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ActivityRepository interface {
+	RecordActivity(activity *models.Activity) error
+	GetFeed(userID string, before *uuid.UUID, limit int) (*[]models.Activity, error)
+	GetPublicActivities(handle string, before *uuid.UUID, limit int) (*[]models.Activity, error)
+	UpdateActivityVisibility(userID, id, visibility string) error
+	DeleteActivity(userID, id string) error
+	GetActivitySettings(userID string) (*[]models.ActivitySetting, error)
+	SetActivitySetting(setting *models.ActivitySetting) error
+}
+
+type activityRepositoryImp struct {
+	db *gorm.DB
+}
+
+// NewActivityRepository creates a new instance of the ActivityRepository interface.
+//
+// It takes a *gorm.DB parameter, which represents the database connection.
+// It returns an ActivityRepository pointer, which is an implementation of the ActivityRepository interface.
+func NewActivityRepository(db *gorm.DB) ActivityRepository {
+	return &activityRepositoryImp{
+		db: db,
+	}
+}
+
+// RecordActivity records an activity of a user with the visibility the user chose for its kind, copying the
+// title, author and cover of the book and the name of the library.
+//
+// To keep the feeds readable, starting a book is only recorded when the user did not start it since they last
+// finished it, a new rating replaces the previous rating activity of the book, and adding a book to a library
+// is only recorded once.
+//
+// Parameters:
+// - activity: a pointer to the activity, with its ID, kind, user, book, library and rating.
+//
+// Returns:
+// - error: an error object if the book was not found or there was an issue recording the activity.
+func (r *activityRepositoryImp) RecordActivity(activity *models.Activity) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	var book models.Book
+	if err := tx.Select("id", "title", "author", "cover").First(&book, "id = ?", activity.BookID).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("book not found")
+		}
+		return err
+	}
+
+	activity.BookTitle = book.Title
+	activity.BookAuthor = book.Author
+	activity.BookCover = book.Cover
+
+	if activity.LibraryID != nil {
+		var library models.Library
+		if err := tx.Select("id", "name").First(&library, "id = ?", *activity.LibraryID).Error; err != nil {
+			tx.Rollback()
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("library not found")
+			}
+			return err
+		}
+		activity.LibraryName = library.Name
+	}
+
+	userBook := tx.Model(&models.Activity{}).Where("user_id = ? AND book_id = ?", activity.UserID, activity.BookID)
+
+	switch activity.Kind {
+	case models.ActivityStartedReading:
+		var latest models.Activity
+		err := userBook.Where("kind IN ?", []string{models.ActivityStartedReading, models.ActivityFinished}).
+			Order("created_at DESC").
+			Limit(1).
+			Find(&latest).Error
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if latest.Kind == models.ActivityStartedReading {
+			tx.Rollback()
+			return nil
+		}
+
+	case models.ActivityRated:
+		if err := userBook.Where("kind = ?", models.ActivityRated).Delete(&models.Activity{}).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+
+	case models.ActivityAddedToLibrary:
+		var count int64
+		if err := userBook.Where("kind = ? AND library_id = ?", models.ActivityAddedToLibrary, activity.LibraryID).Count(&count).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if count > 0 {
+			tx.Rollback()
+			return nil
+		}
+	}
+
+	activity.Visibility = models.ActivityDefaultVisibility
+	var setting models.ActivitySetting
+	if err := tx.Where("user_id = ? AND kind = ?", activity.UserID, activity.Kind).Limit(1).Find(&setting).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if setting.Visibility != "" {
+		activity.Visibility = setting.Visibility
+	}
+
+	if err := tx.Omit("User").Create(activity).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// GetFeed retrieves a page of the feed of a user, newest first: their own activities and the public and
+// followers activities of the users they follow once their follow is accepted.
+//
+// The feed is built when it is read, from the follows of the user, and is paginated with the ID of the
+// last activity of the previous page, which stays stable while new activities are recorded.
+//
+// Parameters:
+// - userID: a string representing the user ID.
+// - before: the ID of the activity the page starts after, nil for the first page.
+// - limit: the largest number of activities of the page.
+//
+// Returns:
+// - *[]models.Activity: a pointer to a slice of models.Activity with the handle and display name of their user.
+// - error: an error object if there was an issue retrieving the feed.
+func (r *activityRepositoryImp) GetFeed(userID string, before *uuid.UUID, limit int) (*[]models.Activity, error) {
+	query := r.activities(before, limit).
+		Where(`activities.user_id = ? OR (activities.visibility IN ? AND activities.user_id IN (
+			SELECT followed_id FROM follows WHERE follower_id = ? AND status = ?))`,
+			userID, []string{models.ActivityVisibilityPublic, models.ActivityVisibilityFollowers}, userID, models.FollowStatusAccepted)
+
+	activities := make([]models.Activity, 0)
+	if err := query.Find(&activities).Error; err != nil {
+		return nil, err
+	}
+
+	return &activities, nil
+}
+
+// GetPublicActivities retrieves a page of the public activities of a public profile, newest first.
+//
+// Parameters:
+// - handle: the handle of the profile.
+// - before: the ID of the activity the page starts after, nil for the first page.
+// - limit: the largest number of activities of the page.
+//
+// Returns:
+// - *[]models.Activity: a pointer to a slice of models.Activity with the handle and display name of their user.
+// - error: an error object if there is no public profile with the handle or there was an issue retrieving the activities.
+func (r *activityRepositoryImp) GetPublicActivities(handle string, before *uuid.UUID, limit int) (*[]models.Activity, error) {
+	var user models.User
+	if err := r.db.Select("id").First(&user, "handle = ? AND public = true", handle).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("profile not found")
+		}
+		return nil, err
+	}
+
+	query := r.activities(before, limit).
+		Where("activities.user_id = ? AND activities.visibility = ?", user.ID, models.ActivityVisibilityPublic)
+
+	activities := make([]models.Activity, 0)
+	if err := query.Find(&activities).Error; err != nil {
+		return nil, err
+	}
+
+	return &activities, nil
+}
+
+// UpdateActivityVisibility changes the visibility of an activity of a user.
+//
+// Parameters:
+// - userID: a string representing the user ID.
+// - id: a string representing the ID of the activity.
+// - visibility: the new visibility of the activity.
+//
+// Returns:
+// - error: an error object if the activity was not found or there was an issue updating it.
+func (r *activityRepositoryImp) UpdateActivityVisibility(userID, id, visibility string) error {
+	result := r.db.Model(&models.Activity{}).Where("id = ? AND user_id = ?", id, userID).Update("visibility", visibility)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("activity not found")
+	}
+
+	return nil
+}
+
+// DeleteActivity deletes an activity of a user.
+//
+// Parameters:
+// - userID: a string representing the user ID.
+// - id: a string representing the ID of the activity.
+//
+// Returns:
+// - error: an error object if the activity was not found or there was an issue deleting it.
+func (r *activityRepositoryImp) DeleteActivity(userID, id string) error {
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Activity{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("activity not found")
+	}
+
+	return nil
+}
+
+// GetActivitySettings retrieves the visibilities a user chose for the kinds of activities.
+//
+// Parameters:
+// - userID: a string representing the user ID.
+//
+// Returns:
+// - *[]models.ActivitySetting: a pointer to a slice of models.ActivitySetting, only for the kinds the user set.
+// - error: an error object if there was an issue retrieving the settings.
+func (r *activityRepositoryImp) GetActivitySettings(userID string) (*[]models.ActivitySetting, error) {
+	var settings []models.ActivitySetting
+
+	if err := r.db.Where("user_id = ?", userID).Find(&settings).Error; err != nil {
+		return nil, err
+	}
+
+	return &settings, nil
+}
+
+// SetActivitySetting sets the visibility of the future activities of a kind of a user. The activities already
+// recorded keep their visibility.
+//
+// Parameters:
+// - setting: a pointer to the setting.
+//
+// Returns:
+// - error: an error object if there was an issue saving the setting.
+func (r *activityRepositoryImp) SetActivitySetting(setting *models.ActivitySetting) error {
+	return r.db.Omit("User").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "kind"}},
+		DoUpdates: clause.AssignmentColumns([]string{"visibility", "updated_at"}),
+	}).Create(setting).Error
+}
+
+// activities selects a page of activities with the handle and display name of their user, newest first,
+// starting after the given activity.
+func (r *activityRepositoryImp) activities(before *uuid.UUID, limit int) *gorm.DB {
+	query := r.db.Model(&models.Activity{}).
+		Select("activities.*, users.handle AS user_handle, users.display_name AS user_display_name").
+		Joins("JOIN users ON users.id = activities.user_id AND users.deleted_at IS NULL")
+
+	if before != nil {
+		query = query.Where("(activities.created_at, activities.id) < (SELECT created_at, id FROM activities WHERE id = ?)", *before)
+	}
+
+	return query.Order("activities.created_at DESC, activities.id DESC").Limit(limit)
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"mybooks/internal/domain/models"
+	"mybooks/pkg"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -36,11 +37,26 @@ func NewAuthRepository(db *gorm.DB) AuthRepository {
 // CreateUser creates a new user in the database.
 //
 // It takes a pointer to a User struct as a parameter and returns an error.
-// The function creates a new record in the database using the provided User object.
+// The function creates a new record in the database using the provided User object,
+// together with the personal organization owning the books of the user.
 // If there is an error during the creation process, it returns the error.
 // Otherwise, it returns nil.
 func (r *authRepositoryImp) CreateUser(user *models.User) error {
-	if err := r.db.Create(user).Error; err != nil {
+	organizationID, err := pkg.GenerateRandomID()
+	if err != nil {
+		return err
+	}
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	if err := tx.Create(user).Error; err != nil {
+		tx.Rollback()
 		if errors.Is(err, gorm.ErrDuplicatedKey) {
 			return fmt.Errorf("user with email %s already exists", user.Email)
 		}
@@ -48,7 +64,19 @@ func (r *authRepositoryImp) CreateUser(user *models.User) error {
 		return err
 	}
 
-	return nil
+	organization := models.Organization{
+		ID:       organizationID,
+		Name:     "Personal",
+		Personal: true,
+		UserID:   user.ID,
+	}
+
+	if err := createOrganization(tx, &organization); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
 }
 
 // GetUserByEmail retrieves a user from the database based on the provided email.
@@ -0,0 +1,361 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"mybooks/internal/domain/models"
+	"mybooks/pkg"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AuthorRepository interface {
+	GetAllAuthors(orgID string, filters map[string]interface{}) (*[]models.Author, error)
+	GetAuthorByID(orgID, id string) (*models.Author, error)
+	UpdateAuthor(orgID string, author *models.Author) error
+	MergeAuthors(orgID, targetID string, sourceIDs []string) error
+	SetBookAuthors(orgID, bookID string, credits []models.AuthorCredit) error
+}
+
+type authorRepositoryImp struct {
+	db *gorm.DB
+}
+
+// NewAuthorRepository creates a new instance of the AuthorRepository interface.
+//
+// It takes a *gorm.DB parameter, which represents the database connection.
+// It returns an AuthorRepository pointer, which is an implementation of the AuthorRepository interface.
+func NewAuthorRepository(db *gorm.DB) AuthorRepository {
+	return &authorRepositoryImp{
+		db: db,
+	}
+}
+
+// GetAllAuthors retrieves all authors of an organization that match the provided filters.
+//
+// The only supported filter is "name", which matches both the name and the sort name.
+// Each author is returned with the number of books it is credited on, ordered by sort name.
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+// - filters: a map of key-value pairs representing the filters to be applied.
+//
+// Returns:
+// - *[]models.Author: a pointer to a slice of models.Author objects representing the retrieved authors.
+// - error: an error object if there was an issue retrieving the authors.
+func (r *authorRepositoryImp) GetAllAuthors(orgID string, filters map[string]interface{}) (*[]models.Author, error) {
+	var authors []models.Author
+	query := r.db.Model(&models.Author{}).
+		Select("authors.*, (SELECT COUNT(DISTINCT book_id) FROM book_authors WHERE book_authors.author_id = authors.id) AS book_count").
+		Where("organization_id = ?", orgID)
+
+	if name, ok := filters["name"]; ok {
+		pattern := fmt.Sprintf("%%%s%%", name)
+		query = query.Where("LOWER(name) LIKE ? OR LOWER(sort_name) LIKE ?", pattern, pattern)
+	}
+
+	if err := query.Order("sort_name ASC").Find(&authors).Error; err != nil {
+		return nil, err
+	}
+
+	return &authors, nil
+}
+
+// GetAuthorByID retrieves an author by its ID together with the books it is credited on.
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+// - id: a string representing the ID of the author to retrieve.
+//
+// Returns:
+// - *models.Author: a pointer to the retrieved author.
+// - error: an error object if there was an issue retrieving the author.
+func (r *authorRepositoryImp) GetAuthorByID(orgID, id string) (*models.Author, error) {
+	var author models.Author
+
+	err := r.db.
+		Preload("Books", func(db *gorm.DB) *gorm.DB {
+			return db.Order("role ASC")
+		}).
+		Preload("Books.Book").
+		First(&author, "id = ? AND organization_id = ?", id, orgID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("author not found")
+		}
+		return nil, err
+	}
+
+	return &author, nil
+}
+
+// UpdateAuthor renames an author and refreshes the author names of the books it is credited on.
+//
+// If the sort name is empty it is derived from the new name.
+// It returns an error if the author is not found or another author already has the same name.
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+// - author: a pointer to a models.Author object representing the author to be updated.
+//
+// Returns:
+// - error: an error object if there was an issue updating the author.
+func (r *authorRepositoryImp) UpdateAuthor(orgID string, author *models.Author) error {
+	if author.SortName == "" {
+		author.SortName = pkg.AuthorSortName(author.Name)
+	}
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	var existing models.Author
+	err := tx.Where("organization_id = ? AND LOWER(name) = LOWER(?) AND id <> ?", orgID, author.Name, author.ID).First(&existing).Error
+	if err == nil {
+		tx.Rollback()
+		return fmt.Errorf("author with name %s already exists, merge the authors instead", author.Name)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Rollback()
+		return err
+	}
+
+	result := tx.Model(&models.Author{}).Where("id = ? AND organization_id = ?", author.ID, orgID).Updates(map[string]interface{}{
+		"name":      author.Name,
+		"sort_name": author.SortName,
+	})
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return errors.New("author not found")
+	}
+
+	var bookIDs []uuid.UUID
+	if err := tx.Model(&models.BookAuthor{}).Where("author_id = ?", author.ID).Distinct().Pluck("book_id", &bookIDs).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := refreshBookAuthorNames(tx, bookIDs); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// MergeAuthors merges the source authors into the target author.
+//
+// Every credit of the source authors is moved to the target author, credits the target
+// already has on the same book and role are dropped, the source authors are deleted and
+// the author names of the affected books are refreshed.
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+// - targetID: a string representing the ID of the author that is kept.
+// - sourceIDs: a slice of strings representing the IDs of the authors merged into the target.
+//
+// Returns:
+// - error: an error object if there was an issue merging the authors.
+func (r *authorRepositoryImp) MergeAuthors(orgID, targetID string, sourceIDs []string) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	var target models.Author
+	if err := tx.First(&target, "id = ? AND organization_id = ?", targetID, orgID).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("author not found")
+		}
+		return err
+	}
+
+	var sources []models.Author
+	if err := tx.Where("id IN ? AND organization_id = ? AND id <> ?", sourceIDs, orgID, target.ID).Find(&sources).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if len(sources) == 0 {
+		tx.Rollback()
+		return fmt.Errorf("author not found")
+	}
+
+	var bookIDs []uuid.UUID
+	for _, source := range sources {
+		var sourceBookIDs []uuid.UUID
+		if err := tx.Model(&models.BookAuthor{}).Where("author_id = ?", source.ID).Distinct().Pluck("book_id", &sourceBookIDs).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+		bookIDs = append(bookIDs, sourceBookIDs...)
+
+		// Drop the credits the target author already has on the same book and role
+		if err := tx.Exec(`DELETE FROM book_authors s WHERE s.author_id = ? AND EXISTS (
+			SELECT 1 FROM book_authors t WHERE t.author_id = ? AND t.book_id = s.book_id AND t.role = s.role)`, source.ID, target.ID).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if err := tx.Model(&models.BookAuthor{}).Where("author_id = ?", source.ID).Update("author_id", target.ID).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if err := tx.Delete(&source).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := refreshBookAuthorNames(tx, bookIDs); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// SetBookAuthors replaces every credit of a book with the provided credits.
+//
+// Authors are matched by name (case-insensitive) and created when they do not exist yet.
+// The author name of the book is refreshed from the credits with the "author" role.
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+// - bookID: a string representing the ID of the book.
+// - credits: a slice of models.AuthorCredit objects in display order.
+//
+// Returns:
+// - error: an error object if there was an issue setting the authors of the book.
+func (r *authorRepositoryImp) SetBookAuthors(orgID, bookID string, credits []models.AuthorCredit) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	var book models.Book
+	if err := tx.First(&book, "id = ? AND organization_id = ?", bookID, orgID).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("book not found")
+		}
+		return err
+	}
+
+	if err := linkBookAuthors(tx, book.OrganizationID, book.UserID, book.ID, credits, "author", "translator", "editor", "illustrator"); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := refreshBookAuthorNames(tx, []uuid.UUID{book.ID}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// linkBookAuthors replaces the credits of a book having one of the given roles.
+//
+// Authors are matched by name (case-insensitive) within the organization and created by the
+// given user when they do not exist yet. The position of each credit follows the order of the credits slice.
+func linkBookAuthors(tx *gorm.DB, orgID, userID, bookID uuid.UUID, credits []models.AuthorCredit, roles ...string) error {
+	if err := tx.Where("book_id = ? AND role IN ?", bookID, roles).Delete(&models.BookAuthor{}).Error; err != nil {
+		return err
+	}
+
+	for position, credit := range credits {
+		author, err := findOrCreateAuthor(tx, orgID, userID, credit.Name)
+		if err != nil {
+			return err
+		}
+
+		link := models.BookAuthor{
+			BookID:   bookID,
+			AuthorID: author.ID,
+			Role:     credit.Role,
+			Position: position,
+		}
+
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&link).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// findOrCreateAuthor returns the author of an organization with the given name, creating it when it does not exist.
+// A new author is recorded as created by the given user.
+func findOrCreateAuthor(tx *gorm.DB, orgID, userID uuid.UUID, name string) (*models.Author, error) {
+	var author models.Author
+
+	err := tx.Where("organization_id = ? AND LOWER(name) = LOWER(?)", orgID, name).First(&author).Error
+	if err == nil {
+		return &author, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	id, err := pkg.GenerateRandomID()
+	if err != nil {
+		return nil, err
+	}
+
+	author = models.Author{
+		ID:             id,
+		Name:           name,
+		SortName:       pkg.AuthorSortName(name),
+		OrganizationID: orgID,
+		UserID:         userID,
+	}
+
+	if err := tx.Create(&author).Error; err != nil {
+		return nil, err
+	}
+
+	return &author, nil
+}
+
+// refreshBookAuthorNames rebuilds the author name of the given books from their "author" credits.
+//
+// The names are separated by "; ", which SplitAuthorNames always reads as a list, unlike commas that may
+// be taken for an inverted name. The credits that do not fit in the 100 characters of the author name are
+// left out whole rather than cut, so that splitting the name never gives a partial author.
+func refreshBookAuthorNames(tx *gorm.DB, bookIDs []uuid.UUID) error {
+	if len(bookIDs) == 0 {
+		return nil
+	}
+
+	return tx.Exec(`UPDATE books SET author = names.author FROM (
+		SELECT credits.book_id, STRING_AGG(credits.name, '; ' ORDER BY credits.position) AS author
+		FROM (
+			SELECT ba.book_id, a.name, ba.position,
+				SUM(LENGTH(a.name) + 2) OVER (PARTITION BY ba.book_id ORDER BY ba.position, a.id) - 2 AS length
+			FROM book_authors ba JOIN authors a ON a.id = ba.author_id
+			WHERE ba.role = 'author' AND ba.book_id IN ?
+		) credits
+		WHERE credits.length <= 100
+		GROUP BY credits.book_id
+	) names WHERE books.id = names.book_id`, bookIDs).Error
+}
@@ -4,16 +4,23 @@ import (
 	"errors"
 	"fmt"
 	"mybooks/internal/domain/models"
+	"mybooks/pkg"
+	"time"
 
 	"gorm.io/gorm"
 )
 
+// bookRatingSelectSQL selects the books with the average and the number of the ratings of their reviews.
+const bookRatingSelectSQL = `books.*,
+	(SELECT AVG(reviews.rating) FROM reviews WHERE reviews.book_id = books.id) AS average_rating,
+	(SELECT COUNT(*) FROM reviews WHERE reviews.book_id = books.id) AS rating_count`
+
 type BookRepository interface {
 	CreateBook(book *models.Book) error
-	GetAllBooks(userID string, filters map[string]interface{}) (*[]models.Book, error)
-	GetBookById(userID string, id string) (*models.Book, error)
-	DeleteBook(userID string, id string) error
-	UpdateBook(userID string, book *models.Book) error
+	GetAllBooks(orgID string, filters map[string]interface{}) (*[]models.Book, error)
+	GetBookById(orgID string, id string) (*models.Book, error)
+	DeleteBook(orgID string, id string) error
+	UpdateBook(orgID string, book *models.Book) error
 }
 
 type bookRepositoryImp struct {
@@ -33,37 +40,67 @@ func NewBookRepository(db *gorm.DB) BookRepository {
 // CreateBook creates a new book in the bookRepositoryImp.
 //
 // It takes a book pointer as a parameter and returns an error if there was an issue creating the book.
-// The function creates a new record in the database using the provided book object.
+// The function creates a new record in the database using the provided book object and credits
+// every name found in the author field as an author of the book.
+// The book is an edition of the work given by its work ID, or of a new work when it has none,
+// and starts with one copy.
 // If there is an error during the creation process, it returns the error.
 // Otherwise, it returns nil.
 func (r *bookRepositoryImp) CreateBook(book *models.Book) error {
-	if err := r.db.Create(book).Error; err != nil {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	copyID, err := pkg.GenerateRandomID()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := createBook(tx, book, &models.Copy{ID: copyID}); err != nil {
+		tx.Rollback()
 		return err
 	}
 
-	return nil
+	return tx.Commit().Error
 }
 
 // GetAllBooks retrieves all books from the bookRepositoryImp that match the provided filters.
 //
-// The function takes a userID string and a map of key-value pairs representing the filters to be applied.
+// The function takes an orgID string and a map of key-value pairs representing the filters to be applied.
 // The keys represent the fields to be filtered, and the values represent the values to match against.
 // The function returns a pointer to a slice of models.Book objects representing the retrieved books,
 // and an error if there was an issue retrieving the books.
 // If there is an error during the retrieval process, the function returns nil and the error.
-// The books are ordered by the creation date in descending order.
-func (r *bookRepositoryImp) GetAllBooks(userID string, filters map[string]interface{}) (*[]models.Book, error) {
+// The books are ordered by the creation date in descending order, or by their average rating first
+// when the "sort" filter is "rating".
+func (r *bookRepositoryImp) GetAllBooks(orgID string, filters map[string]interface{}) (*[]models.Book, error) {
 	var books []models.Book
-	query := r.db.Model(&models.Book{}).Where("user_id = ?", userID).Omit("libraries")
+	query := r.db.Model(&models.Book{}).Select(bookRatingSelectSQL).Where("organization_id = ?", orgID).Omit("libraries").Scopes(preloadBookAuthors).Preload("Tags")
 
 	for key, value := range filters {
 		if key == "read" {
 			query = query.Where("read = ?", value)
+		} else if key == "tags" {
+			query = filterBooksByTags(query, value.([]string), filters["tags_mode"])
+		} else if key == "tags_mode" || key == "sort" {
+			continue
+		} else if key == "min_rating" {
+			query = query.Where("(SELECT AVG(reviews.rating) FROM reviews WHERE reviews.book_id = books.id) >= ?", value)
+		} else if key == "author_id" {
+			query = query.Where("EXISTS (SELECT 1 FROM book_authors WHERE book_authors.book_id = books.id AND book_authors.author_id = ?)", value)
 		} else {
 			query = query.Where(fmt.Sprintf("%s LIKE ?", key), fmt.Sprintf("%%%s%%", value))
 		}
 	}
 
+	if filters["sort"] == "rating" {
+		query = query.Order("average_rating DESC NULLS LAST")
+	}
 	query = query.Order("created_at DESC")
 
 	if err := query.Find(&books).Error; err != nil {
@@ -75,13 +112,15 @@ func (r *bookRepositoryImp) GetAllBooks(userID string, filters map[string]interf
 
 // GetBookById retrieves a book from the bookRepositoryImp by its ID.
 //
-// It takes a string parameter `userID` representing the ID of the user and a string parameter `id` representing the ID of the book to retrieve.
+// It takes a string parameter `orgID` representing the ID of the organization and a string parameter `id` representing the ID of the book to retrieve.
 // The function returns a pointer to a models.Book object representing the retrieved book, and an error if there was an issue retrieving the book.
 // If the book is not found, it returns nil and an error with the message "book not found".
 // If there is any other error during the retrieval process, it returns nil and the error.
-func (r *bookRepositoryImp) GetBookById(userID, id string) (*models.Book, error) {
+func (r *bookRepositoryImp) GetBookById(orgID, id string) (*models.Book, error) {
 	var book models.Book
-	if err := r.db.First(&book, "id = ? AND user_id = ?", id, userID).Error; err != nil {
+	if err := r.db.Select(bookRatingSelectSQL).Scopes(preloadBookAuthors).Preload("Series.Series").Preload("Tags").Preload("Copies", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC")
+	}).First(&book, "id = ? AND organization_id = ?", id, orgID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, fmt.Errorf("book not found")
 		}
@@ -94,12 +133,12 @@ func (r *bookRepositoryImp) GetBookById(userID, id string) (*models.Book, error)
 // DeleteBook deletes a book from the bookRepositoryImp by its ID.
 //
 // Parameters:
-// - userID: a string representing the ID of the user.
+// - orgID: a string representing the ID of the organization.
 // - id: a string representing the ID of the book to be deleted.
 //
 // Returns:
 // - error: an error object if there was an issue deleting the book.
-func (r *bookRepositoryImp) DeleteBook(userID, id string) error {
+func (r *bookRepositoryImp) DeleteBook(orgID, id string) error {
 	tx := r.db.Begin()
 	defer func() {
 		if r := recover(); r != nil {
@@ -109,13 +148,83 @@ func (r *bookRepositoryImp) DeleteBook(userID, id string) error {
 	}()
 
 	// Delete the relation in the book_library table
-	if err := r.db.Exec("DELETE FROM book_library WHERE book_id = ?", id).Error; err != nil {
+	if err := tx.Exec("DELETE FROM book_library WHERE book_id = ? AND book_id IN (SELECT id FROM books WHERE organization_id = ?)", id, orgID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Delete the credits in the book_authors table
+	if err := tx.Exec("DELETE FROM book_authors WHERE book_id = ? AND book_id IN (SELECT id FROM books WHERE organization_id = ?)", id, orgID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Delete the relation in the book_series table
+	if err := tx.Exec("DELETE FROM book_series WHERE book_id = ? AND book_id IN (SELECT id FROM books WHERE organization_id = ?)", id, orgID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Delete the relation in the book_tag table
+	if err := tx.Exec("DELETE FROM book_tag WHERE book_id = ? AND book_id IN (SELECT id FROM books WHERE organization_id = ?)", id, orgID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Delete the reviews of the book
+	if err := tx.Exec("DELETE FROM reviews WHERE book_id = ? AND book_id IN (SELECT id FROM books WHERE organization_id = ?)", id, orgID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Delete the notes of the book and their relation in the note_tag table
+	if err := tx.Exec("DELETE FROM note_tag WHERE note_id IN (SELECT id FROM notes WHERE book_id = ? AND book_id IN (SELECT id FROM books WHERE organization_id = ?))", id, orgID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Exec("DELETE FROM notes WHERE book_id = ? AND book_id IN (SELECT id FROM books WHERE organization_id = ?)", id, orgID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Delete the reading sessions of the book
+	if err := tx.Exec("DELETE FROM reading_sessions WHERE book_id = ? AND book_id IN (SELECT id FROM books WHERE organization_id = ?)", id, orgID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Delete the activities about the book from the feeds
+	if err := tx.Exec("DELETE FROM activities WHERE book_id = ? AND book_id IN (SELECT id FROM books WHERE organization_id = ?)", id, orgID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Delete the holds of the book, which can never be served once it is gone
+	if err := tx.Exec("DELETE FROM holds WHERE book_id = ? AND book_id IN (SELECT CAST(id AS TEXT) FROM books WHERE organization_id = ?)", id, orgID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Keep the wishlist items bought as this book and the clubs reading it, without the book
+	if err := tx.Exec("UPDATE wishlist_items SET book_id = NULL WHERE book_id = ? AND book_id IN (SELECT id FROM books WHERE organization_id = ?)", id, orgID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Exec("UPDATE clubs SET book_id = NULL WHERE book_id = ? AND book_id IN (SELECT id FROM books WHERE organization_id = ?)", id, orgID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Delete the copies of the book
+	if err := tx.Exec("DELETE FROM copies WHERE book_id = ? AND book_id IN (SELECT id FROM books WHERE organization_id = ?)", id, orgID).Error; err != nil {
 		tx.Rollback()
 		return err
 	}
 
 	// Delete the book
-	result := tx.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Book{})
+	result := tx.Where("id = ? AND organization_id = ?", id, orgID).Delete(&models.Book{})
 	if result.Error != nil {
 		tx.Rollback()
 		return result.Error
@@ -127,32 +236,157 @@ func (r *bookRepositoryImp) DeleteBook(userID, id string) error {
 		return errors.New("book not found")
 	}
 
+	if err := deleteEmptyWorks(tx, orgID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	return tx.Commit().Error
 }
 
 // UpdateBook updates a book in the bookRepositoryImp.
 //
-// It takes a userID string and a book pointer as parameters. The userID represents the ID of the user, and the book pointer represents the book to be updated.
+// It takes an orgID string and a book pointer as parameters. The orgID represents the ID of the organization, and the book pointer represents the book to be updated.
 // The function updates the specified book in the database by updating its fields except for the ID and CreatedAt.
 // If the book is not found, it returns an error with the message "book not found".
 // Otherwise, it returns nil.
 //
 // Parameters:
-// - userID: a string representing the ID of the user.
+// - orgID: a string representing the ID of the organization.
 // - book: a pointer to a models.Book object representing the book to be updated.
 //
 // Returns:
 // - error: an error object if there was an issue updating the book.
-func (r *bookRepositoryImp) UpdateBook(userID string, book *models.Book) error {
-	result := r.db.Model(&models.Book{}).Omit("ID", "CreatedAt").Where("id = ? AND user_id = ?", book.ID, userID).Updates(book)
+func (r *bookRepositoryImp) UpdateBook(orgID string, book *models.Book) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	if book.WorkID != nil {
+		if err := ensureWorkExists(tx, orgID, *book.WorkID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	var existing models.Book
+	if err := tx.Where("id = ? AND organization_id = ?", book.ID, orgID).First(&existing).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("book not found")
+		}
+		return err
+	}
+
+	result := tx.Model(&models.Book{}).Omit("ID", "CreatedAt", "Authors", "Series", "Tags", "Copies").Where("id = ? AND organization_id = ?", book.ID, orgID).Updates(book)
 
 	if result.Error != nil {
+		tx.Rollback()
 		return result.Error
 	}
 
 	if result.RowsAffected == 0 {
+		tx.Rollback()
 		return fmt.Errorf("book not found")
 	}
 
-	return nil
+	// Keep the author credits in sync when the author name changes, leaving them alone when the name
+	// sent back is the one rebuilt from them
+	if book.Author != "" && book.Author != existing.Author {
+		if err := linkBookAuthors(tx, existing.OrganizationID, existing.UserID, existing.ID, authorCredits(book.Author), "author"); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	// Remember when the book was finished, unless the date was given
+	if book.Read && book.ReadAt == nil {
+		if err := tx.Model(&models.Book{}).Where("id = ? AND read_at IS NULL", book.ID).Update("read_at", time.Now()).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	// Moving the book to another work may leave its previous work without editions
+	if book.WorkID != nil {
+		if err := deleteEmptyWorks(tx, orgID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+// createBook creates a book as an edition of its work, or of a new work when it has none, credits
+// the names found in its author field and gives it its first copy, placed in the library of the copy if any.
+func createBook(tx *gorm.DB, book *models.Book, bookCopy *models.Copy) error {
+	if book.WorkID == nil {
+		work, err := createWork(tx, book)
+		if err != nil {
+			return err
+		}
+		book.WorkID = &work.ID
+	} else if err := ensureWorkExists(tx, book.OrganizationID.String(), *book.WorkID); err != nil {
+		return err
+	}
+
+	if book.Read && book.ReadAt == nil {
+		readAt := time.Now()
+		book.ReadAt = &readAt
+	}
+
+	if err := tx.Omit("Authors", "Series", "Tags", "Copies").Create(book).Error; err != nil {
+		return err
+	}
+
+	if err := linkBookAuthors(tx, book.OrganizationID, book.UserID, book.ID, authorCredits(book.Author), "author"); err != nil {
+		return err
+	}
+
+	bookCopy.BookID = book.ID
+	bookCopy.OrganizationID = book.OrganizationID
+	bookCopy.UserID = book.UserID
+
+	if err := placeCopy(tx, book.UserID.String(), bookCopy); err != nil {
+		return err
+	}
+
+	return tx.Omit("User").Create(bookCopy).Error
+}
+
+// preloadBookAuthors preloads the author credits of the books in display order.
+func preloadBookAuthors(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Authors", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC")
+		}).
+		Preload("Authors.Author")
+}
+
+// filterBooksByTags restricts the query to the books having the given normalized tag names.
+//
+// With the "or" mode a book must have at least one of the tags, otherwise it must have all of them.
+func filterBooksByTags(query *gorm.DB, tags []string, mode interface{}) *gorm.DB {
+	if mode == "or" {
+		return query.Where(`EXISTS (SELECT 1 FROM book_tag JOIN tags ON tags.id = book_tag.tag_id
+			WHERE book_tag.book_id = books.id AND tags.normalized_name IN ?)`, tags)
+	}
+
+	return query.Where(`(SELECT COUNT(DISTINCT tags.normalized_name) FROM book_tag JOIN tags ON tags.id = book_tag.tag_id
+		WHERE book_tag.book_id = books.id AND tags.normalized_name IN ?) = ?`, tags, len(tags))
+}
+
+// authorCredits builds the "author" credits for the names found in a free-text author string.
+func authorCredits(author string) []models.AuthorCredit {
+	credits := make([]models.AuthorCredit, 0)
+	for _, name := range pkg.SplitAuthorNames(author) {
+		credits = append(credits, models.AuthorCredit{Name: name, Role: "author"})
+	}
+
+	return credits
 }
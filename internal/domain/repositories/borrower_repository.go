@@ -0,0 +1,340 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"mybooks/internal/domain/models"
+	"mybooks/pkg"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type BorrowerRepository interface {
+	CreateBorrower(borrower *models.Borrower) error
+	GetAllBorrowers(orgID string, filters map[string]interface{}) (*[]models.Borrower, error)
+	GetBorrowerByID(orgID, id string) (*models.Borrower, error)
+	GetBorrowerLoans(orgID, id string) (*[]models.Loan, error)
+	GetBorrowerStats(orgID, id string) (*models.BorrowerStats, error)
+	UpdateBorrower(orgID string, borrower *models.Borrower) error
+	DeleteBorrower(orgID, id string) error
+	MergeBorrowers(orgID, targetID string, sourceIDs []string) error
+}
+
+type borrowerRepositoryImp struct {
+	db *gorm.DB
+}
+
+// NewBorrowerRepository creates a new instance of the BorrowerRepository interface.
+//
+// It takes a *gorm.DB parameter, which represents the database connection.
+// It returns a BorrowerRepository pointer, which is an implementation of the BorrowerRepository interface.
+func NewBorrowerRepository(db *gorm.DB) BorrowerRepository {
+	return &borrowerRepositoryImp{
+		db: db,
+	}
+}
+
+// CreateBorrower creates a new borrower in the database.
+//
+// Borrower names are unique per organization regardless of case.
+// It returns an error if the organization already has a borrower with the same name.
+func (r *borrowerRepositoryImp) CreateBorrower(borrower *models.Borrower) error {
+	borrower.NormalizedName = pkg.NormalizeBorrowerName(borrower.Name)
+
+	if err := ensureBorrowerNameIsFree(r.db, borrower); err != nil {
+		return err
+	}
+
+	return r.db.Omit("User").Create(borrower).Error
+}
+
+// GetAllBorrowers retrieves the borrowers of an organization ordered by name.
+//
+// The "name" filter matches any part of the borrower name (case-insensitive).
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+// - filters: a map of key-value pairs representing the filters to be applied.
+//
+// Returns:
+// - *[]models.Borrower: a pointer to a slice of models.Borrower objects representing the retrieved borrowers.
+// - error: an error object if there was an issue retrieving the borrowers.
+func (r *borrowerRepositoryImp) GetAllBorrowers(orgID string, filters map[string]interface{}) (*[]models.Borrower, error) {
+	var borrowers []models.Borrower
+	query := r.db.Where("organization_id = ?", orgID)
+
+	if name, ok := filters["name"]; ok {
+		query = query.Where("normalized_name LIKE ?", fmt.Sprintf("%%%s%%", name))
+	}
+
+	if err := query.Order("normalized_name ASC").Find(&borrowers).Error; err != nil {
+		return nil, err
+	}
+
+	return &borrowers, nil
+}
+
+// GetBorrowerByID retrieves a borrower of an organization by its ID.
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+// - id: a string representing the borrower ID.
+//
+// Returns:
+// - *models.Borrower: a pointer to the retrieved borrower.
+// - error: an error object if the borrower was not found or there was an issue retrieving it.
+func (r *borrowerRepositoryImp) GetBorrowerByID(orgID, id string) (*models.Borrower, error) {
+	var borrower models.Borrower
+
+	if err := r.db.First(&borrower, "id = ? AND organization_id = ?", id, orgID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("borrower not found")
+		}
+		return nil, err
+	}
+
+	return &borrower, nil
+}
+
+// GetBorrowerLoans retrieves the loan history of a borrower, most recent first.
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+// - id: a string representing the borrower ID.
+//
+// Returns:
+// - *[]models.Loan: a pointer to a slice of models.Loan representing the loans of the borrower.
+// - error: an error object if the borrower was not found or there was an issue retrieving the loans.
+func (r *borrowerRepositoryImp) GetBorrowerLoans(orgID, id string) (*[]models.Loan, error) {
+	if _, err := r.GetBorrowerByID(orgID, id); err != nil {
+		return nil, err
+	}
+
+	var loans []models.Loan
+
+	if err := r.db.Where("organization_id = ? AND borrower_contact_id = ?", orgID, id).Order("created_at DESC").Find(&loans).Error; err != nil {
+		return nil, err
+	}
+
+	return &loans, nil
+}
+
+// GetBorrowerStats summarizes the loans of a borrower.
+//
+// The return time of a loan is measured from its loan date, or from its creation when the loan date
+// is not a valid date, to the moment it was returned. The loan date is free text, so it is parsed here
+// rather than cast by the database, which would fail on a date such as "2024-02-30".
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+// - id: a string representing the borrower ID.
+//
+// Returns:
+// - *models.BorrowerStats: a pointer to the statistics of the borrower.
+// - error: an error object if the borrower was not found or there was an issue computing the statistics.
+func (r *borrowerRepositoryImp) GetBorrowerStats(orgID, id string) (*models.BorrowerStats, error) {
+	if _, err := r.GetBorrowerByID(orgID, id); err != nil {
+		return nil, err
+	}
+
+	var stats models.BorrowerStats
+
+	err := r.db.Model(&models.Loan{}).
+		Select(`COUNT(*) AS total_loans,
+			COUNT(*) FILTER (WHERE status IN ?) AS currently_holding,
+			COUNT(*) FILTER (WHERE NULLIF(due_date, '')::date < COALESCE(returned_at::date, CURRENT_DATE)) AS overdue_count`,
+			[]string{models.LoanStatusActive, models.LoanStatusReturnPending}).
+		Where("organization_id = ? AND borrower_contact_id = ?", orgID, id).
+		Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var returned []models.Loan
+	err = r.db.Select("loan_date, created_at, returned_at").
+		Where("organization_id = ? AND borrower_contact_id = ? AND returned_at IS NOT NULL", orgID, id).
+		Find(&returned).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if len(returned) > 0 {
+		var days float64
+		for _, loan := range returned {
+			lentAt := loan.CreatedAt
+			if loanDate, err := time.Parse("2006-01-02", loan.LoanDate); err == nil {
+				lentAt = loanDate
+			}
+			days += loan.ReturnedAt.Sub(lentAt).Hours() / 24
+		}
+
+		average := days / float64(len(returned))
+		stats.AverageReturnDays = &average
+	}
+
+	return &stats, nil
+}
+
+// UpdateBorrower updates the contact details of a borrower.
+//
+// It returns an error if the borrower was not found or another borrower of the organization already
+// has the new name. The name of the loans of the borrower is kept in sync.
+func (r *borrowerRepositoryImp) UpdateBorrower(orgID string, borrower *models.Borrower) error {
+	existing, err := r.GetBorrowerByID(orgID, borrower.ID.String())
+	if err != nil {
+		return err
+	}
+
+	borrower.NormalizedName = pkg.NormalizeBorrowerName(borrower.Name)
+	borrower.OrganizationID = existing.OrganizationID
+
+	if err := ensureBorrowerNameIsFree(r.db, borrower); err != nil {
+		return err
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(existing).Select("name", "normalized_name", "email", "phone", "notes").Updates(models.Borrower{
+			Name:           borrower.Name,
+			NormalizedName: borrower.NormalizedName,
+			Email:          borrower.Email,
+			Phone:          borrower.Phone,
+			Notes:          borrower.Notes,
+		}).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&models.Loan{}).Where("borrower_contact_id = ?", existing.ID).Update("borrower_name", borrower.Name).Error
+	})
+}
+
+// DeleteBorrower deletes a borrower of an organization.
+//
+// Borrowers with loans cannot be deleted, since the loan history would be lost; merge them into
+// another borrower instead.
+func (r *borrowerRepositoryImp) DeleteBorrower(orgID, id string) error {
+	borrower, err := r.GetBorrowerByID(orgID, id)
+	if err != nil {
+		return err
+	}
+
+	var count int64
+	if err := r.db.Model(&models.Loan{}).Where("borrower_contact_id = ?", borrower.ID).Count(&count).Error; err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return errors.New("borrower has loans, merge it into another borrower instead")
+	}
+
+	return r.db.Delete(borrower).Error
+}
+
+// MergeBorrowers moves the loans of the source borrowers to the target borrower and deletes the sources.
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+// - targetID: the ID of the borrower that is kept.
+// - sourceIDs: the IDs of the borrowers merged into the target.
+//
+// Returns:
+// - error: an error object if the target or every source was not found or there was an issue merging them.
+func (r *borrowerRepositoryImp) MergeBorrowers(orgID, targetID string, sourceIDs []string) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	var target models.Borrower
+	if err := tx.First(&target, "id = ? AND organization_id = ?", targetID, orgID).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("borrower not found")
+		}
+		return err
+	}
+
+	var sources []models.Borrower
+	if err := tx.Where("id IN ? AND organization_id = ? AND id <> ?", sourceIDs, orgID, target.ID).Find(&sources).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if len(sources) == 0 {
+		tx.Rollback()
+		return fmt.Errorf("borrower not found")
+	}
+
+	for _, source := range sources {
+		if err := tx.Model(&models.Loan{}).Where("borrower_contact_id = ?", source.ID).Updates(map[string]interface{}{
+			"borrower_contact_id": target.ID,
+			"borrower_name":       target.Name,
+		}).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if err := tx.Delete(&source).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+// findOrCreateBorrower returns the borrower of an organization with the given name (case-insensitive),
+// creating it when the organization does not have it yet.
+func findOrCreateBorrower(tx *gorm.DB, orgID, userID uuid.UUID, name string) (*models.Borrower, error) {
+	var borrower models.Borrower
+
+	normalizedName := pkg.NormalizeBorrowerName(name)
+	err := tx.Where("organization_id = ? AND normalized_name = ?", orgID, normalizedName).First(&borrower).Error
+	if err == nil {
+		return &borrower, nil
+	}
+
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	id, err := pkg.GenerateRandomID()
+	if err != nil {
+		return nil, err
+	}
+
+	borrower = models.Borrower{
+		ID:             id,
+		Name:           name,
+		NormalizedName: normalizedName,
+		OrganizationID: orgID,
+		UserID:         userID,
+	}
+
+	if err := tx.Omit("User").Create(&borrower).Error; err != nil {
+		return nil, err
+	}
+
+	return &borrower, nil
+}
+
+// ensureBorrowerNameIsFree returns an error if another borrower of the same organization already uses the name of the borrower.
+func ensureBorrowerNameIsFree(db *gorm.DB, borrower *models.Borrower) error {
+	var count int64
+	err := db.Model(&models.Borrower{}).
+		Where("organization_id = ? AND normalized_name = ? AND id <> ?", borrower.OrganizationID, borrower.NormalizedName, borrower.ID).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return fmt.Errorf("borrower with name %s already exists", borrower.Name)
+	}
+
+	return nil
+}
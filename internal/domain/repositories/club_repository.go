@@ -0,0 +1,768 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"mybooks/internal/domain/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ClubRepository interface {
+	CreateClub(club *models.Club) error
+	GetClubs(userID string) (*[]models.Club, error)
+	GetClubMembership(userID, clubID string) (*models.ClubMember, error)
+	UpdateClub(userID string, club *models.Club) error
+	DeleteClub(userID, clubID string) error
+	SetClubBook(userID, orgID, clubID, bookID string) error
+	ResetJoinCode(userID, clubID, code string) error
+	JoinClub(user *models.User, code string) (*models.Club, error)
+	GetClubMembers(userID, clubID string) (*[]models.ClubMember, error)
+	UpdateClubMember(userID, clubID, memberID, role string) error
+	RemoveClubMember(userID, clubID, memberID string) error
+	UpdateClubProgress(userID, clubID string, page int, visible *bool) error
+	GetClubSections(clubID string) (*[]models.ClubSection, error)
+	GetClubSection(clubID, sectionID string) (*models.ClubSection, error)
+	CreateClubSection(userID string, section *models.ClubSection) error
+	UpdateClubSection(userID string, section *models.ClubSection) error
+	DeleteClubSection(userID, clubID, sectionID string) error
+	GetClubPosts(sectionID string) (*[]models.ClubPost, error)
+	CreateClubPost(post *models.ClubPost) error
+	DeleteClubPost(userID, clubID, sectionID, postID string) error
+}
+
+type clubRepositoryImp struct {
+	db *gorm.DB
+}
+
+// NewClubRepository creates a new instance of the ClubRepository interface.
+//
+// It takes a *gorm.DB parameter, which represents the database connection.
+// It returns a ClubRepository pointer, which is an implementation of the ClubRepository interface.
+func NewClubRepository(db *gorm.DB) ClubRepository {
+	return &clubRepositoryImp{
+		db: db,
+	}
+}
+
+// CreateClub creates a book club, with the user creating it as its owner.
+//
+// Parameters:
+// - club: a pointer to the club, with its ID, join code and user.
+//
+// Returns:
+// - error: an error object if there was an issue creating the club.
+func (r *clubRepositoryImp) CreateClub(club *models.Club) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	if err := tx.Omit("User").Create(club).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	owner := models.ClubMember{ClubID: club.ID, UserID: club.UserID, Role: "owner"}
+	if err := tx.Omit("User", "Club").Create(&owner).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// GetClubs retrieves the book clubs of a user with their role and number of members, most recent first.
+//
+// Parameters:
+// - userID: a string representing the user ID.
+//
+// Returns:
+// - *[]models.Club: a pointer to a slice of models.Club.
+// - error: an error object if there was an issue retrieving the clubs.
+func (r *clubRepositoryImp) GetClubs(userID string) (*[]models.Club, error) {
+	clubs := make([]models.Club, 0)
+
+	err := r.db.
+		Select("clubs.*, club_members.role AS role, (SELECT COUNT(*) FROM club_members members WHERE members.club_id = clubs.id) AS members").
+		Joins("JOIN club_members ON club_members.club_id = clubs.id AND club_members.user_id = ?", userID).
+		Order("clubs.created_at DESC").
+		Find(&clubs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range clubs {
+		if clubs[i].Role != "owner" {
+			clubs[i].JoinCode = ""
+		}
+	}
+
+	return &clubs, nil
+}
+
+// GetClubMembership retrieves the membership of a user in a book club, with the club, its number of members
+// and the role of the user in it. The join code is only kept for the owners.
+//
+// Parameters:
+// - userID: a string representing the user ID.
+// - clubID: a string representing the ID of the club.
+//
+// Returns:
+// - *models.ClubMember: a pointer to the membership, with its club.
+// - error: an error object if the user is not a member of the club or there was an issue retrieving it.
+func (r *clubRepositoryImp) GetClubMembership(userID, clubID string) (*models.ClubMember, error) {
+	member, err := clubAccess(r.db, userID, clubID, "member")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.db.Model(&models.ClubMember{}).Where("club_id = ?", clubID).Count(&member.Club.Members).Error; err != nil {
+		return nil, err
+	}
+
+	if member.Role != "owner" {
+		member.Club.JoinCode = ""
+	}
+
+	return member, nil
+}
+
+// UpdateClub updates the name and description of a book club. Only an owner can update it.
+//
+// Parameters:
+// - userID: a string representing the ID of the user updating the club.
+// - club: a pointer to the club, with its ID and new name and description.
+//
+// Returns:
+// - error: an error object if there was an issue updating the club.
+func (r *clubRepositoryImp) UpdateClub(userID string, club *models.Club) error {
+	if _, err := clubAccess(r.db, userID, club.ID.String(), "owner"); err != nil {
+		return err
+	}
+
+	return r.db.Model(&models.Club{}).Where("id = ?", club.ID).Updates(map[string]interface{}{
+		"name":        club.Name,
+		"description": club.Description,
+	}).Error
+}
+
+// DeleteClub deletes a book club with its members, its schedule and its discussions. Only an owner can delete it.
+//
+// Parameters:
+// - userID: a string representing the ID of the user deleting the club.
+// - clubID: a string representing the ID of the club.
+//
+// Returns:
+// - error: an error object if there was an issue deleting the club.
+func (r *clubRepositoryImp) DeleteClub(userID, clubID string) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	if _, err := clubAccess(tx, userID, clubID, "owner"); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := deleteClubSchedule(tx, clubID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Where("club_id = ?", clubID).Delete(&models.ClubMember{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Where("id = ?", clubID).Delete(&models.Club{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// SetClubBook makes a book of the active organization of an owner the current book of a book club, copying
+// its title, author, cover and pages. The schedule and the discussions of the previous book are deleted and
+// the progress of every member starts over.
+//
+// Parameters:
+// - userID: a string representing the ID of the user setting the book.
+// - orgID: a string representing the ID of the active organization of the user.
+// - clubID: a string representing the ID of the club.
+// - bookID: a string representing the ID of the book.
+//
+// Returns:
+// - error: an error object if the book was not found or there was an issue setting it.
+func (r *clubRepositoryImp) SetClubBook(userID, orgID, clubID, bookID string) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	if _, err := clubAccess(tx, userID, clubID, "owner"); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var book models.Book
+	err := tx.Select("id", "title", "author", "cover", "pages").First(&book, "id = ? AND organization_id = ?", bookID, orgID).Error
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("book not found")
+		}
+		return err
+	}
+
+	if err := deleteClubSchedule(tx, clubID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Model(&models.Club{}).Where("id = ?", clubID).Updates(map[string]interface{}{
+		"book_id":     book.ID,
+		"book_title":  book.Title,
+		"book_author": book.Author,
+		"book_cover":  book.Cover,
+		"book_pages":  book.Pages,
+	}).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Model(&models.ClubMember{}).Where("club_id = ?", clubID).Updates(map[string]interface{}{
+		"page":                0,
+		"progress_updated_at": nil,
+	}).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// ResetJoinCode replaces the join code of a book club, so the previous one no longer lets anyone join.
+// Only an owner can reset it.
+//
+// Parameters:
+// - userID: a string representing the ID of the user resetting the code.
+// - clubID: a string representing the ID of the club.
+// - code: the new join code.
+//
+// Returns:
+// - error: an error object if there was an issue resetting the code.
+func (r *clubRepositoryImp) ResetJoinCode(userID, clubID, code string) error {
+	if _, err := clubAccess(r.db, userID, clubID, "owner"); err != nil {
+		return err
+	}
+
+	return r.db.Model(&models.Club{}).Where("id = ?", clubID).Update("join_code", code).Error
+}
+
+// JoinClub makes a user a member of the book club with a join code. Joining a club again keeps the
+// membership and progress of the user.
+//
+// Parameters:
+// - user: a pointer to the user joining the club.
+// - code: the join code of the club.
+//
+// Returns:
+// - *models.Club: a pointer to the club joined, without its join code.
+// - error: an error object if the code is invalid or there was an issue joining the club.
+func (r *clubRepositoryImp) JoinClub(user *models.User, code string) (*models.Club, error) {
+	var club models.Club
+	if err := r.db.First(&club, "join_code = ?", code).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("invalid join code")
+		}
+		return nil, err
+	}
+
+	page := 0
+	member := models.ClubMember{ClubID: club.ID, UserID: user.ID, Role: "member", Page: &page, ProgressVisible: true}
+	if err := r.db.Omit("User", "Club").Clauses(clause.OnConflict{DoNothing: true}).Create(&member).Error; err != nil {
+		return nil, err
+	}
+
+	if err := r.db.First(&member, "club_id = ? AND user_id = ?", club.ID, user.ID).Error; err != nil {
+		return nil, err
+	}
+
+	club.Role = member.Role
+	if club.Role != "owner" {
+		club.JoinCode = ""
+	}
+
+	return &club, nil
+}
+
+// GetClubMembers retrieves the members of a book club with their handle and display name, owners first.
+//
+// Parameters:
+// - userID: a string representing the ID of the user, who must be a member of the club.
+// - clubID: a string representing the ID of the club.
+//
+// Returns:
+// - *[]models.ClubMember: a pointer to a slice of models.ClubMember.
+// - error: an error object if there was an issue retrieving the members.
+func (r *clubRepositoryImp) GetClubMembers(userID, clubID string) (*[]models.ClubMember, error) {
+	members := make([]models.ClubMember, 0)
+
+	if _, err := clubAccess(r.db, userID, clubID, "member"); err != nil {
+		return nil, err
+	}
+
+	err := r.db.
+		Select("club_members.*, users.handle, users.display_name").
+		Joins("JOIN users ON users.id = club_members.user_id AND users.deleted_at IS NULL").
+		Where("club_members.club_id = ?", clubID).
+		Order("CASE club_members.role WHEN 'owner' THEN 0 ELSE 1 END, club_members.created_at ASC").
+		Find(&members).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &members, nil
+}
+
+// UpdateClubMember changes the role of a member of a book club.
+//
+// Only an owner can change roles, and the last owner of a club cannot be demoted.
+//
+// Parameters:
+// - userID: a string representing the ID of the user changing the role.
+// - clubID: a string representing the ID of the club.
+// - memberID: a string representing the user ID of the member.
+// - role: the new role of the member.
+//
+// Returns:
+// - error: an error object if there was an issue updating the member.
+func (r *clubRepositoryImp) UpdateClubMember(userID, clubID, memberID, role string) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	if _, err := clubAccess(tx, userID, clubID, "owner"); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	member, err := clubAccess(tx, memberID, clubID, "member")
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("member not found")
+	}
+
+	if member.Role == "owner" && role != "owner" {
+		if err := ensureAnotherClubOwner(tx, clubID, memberID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Model(&models.ClubMember{}).Where("club_id = ? AND user_id = ?", clubID, memberID).Update("role", role).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// RemoveClubMember removes a member from a book club, with their posts.
+//
+// An owner can remove any member and every member can remove themselves to leave the club.
+// The last owner of a club cannot leave it, the club must be deleted instead.
+//
+// Parameters:
+// - userID: a string representing the ID of the user removing the member.
+// - clubID: a string representing the ID of the club.
+// - memberID: a string representing the user ID of the member.
+//
+// Returns:
+// - error: an error object if there was an issue removing the member.
+func (r *clubRepositoryImp) RemoveClubMember(userID, clubID, memberID string) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	role := "owner"
+	if userID == memberID {
+		role = "member"
+	}
+
+	if _, err := clubAccess(tx, userID, clubID, role); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	member, err := clubAccess(tx, memberID, clubID, "member")
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("member not found")
+	}
+
+	if member.Role == "owner" {
+		if err := ensureAnotherClubOwner(tx, clubID, memberID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	err = tx.Where("user_id = ? AND section_id IN (?)", memberID, tx.Model(&models.ClubSection{}).Select("id").Where("club_id = ?", clubID)).
+		Delete(&models.ClubPost{}).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Where("club_id = ? AND user_id = ?", clubID, memberID).Delete(&models.ClubMember{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// UpdateClubProgress records the last page of the current book of a book club a member read, and whether
+// the other members see it.
+//
+// Parameters:
+// - userID: a string representing the user ID.
+// - clubID: a string representing the ID of the club.
+// - page: the last page read.
+// - visible: whether the other members see the progress, nil to keep it.
+//
+// Returns:
+// - error: an error object if the club has no current book, the page is beyond its last page or there was an issue recording the progress.
+func (r *clubRepositoryImp) UpdateClubProgress(userID, clubID string, page int, visible *bool) error {
+	member, err := clubAccess(r.db, userID, clubID, "member")
+	if err != nil {
+		return err
+	}
+
+	if member.Club.BookID == nil {
+		return errors.New("the club has no current book")
+	}
+
+	if member.Club.BookPages > 0 && page > member.Club.BookPages {
+		return fmt.Errorf("page must not be beyond the last page of the book (%d)", member.Club.BookPages)
+	}
+
+	updates := map[string]interface{}{
+		"page":                page,
+		"progress_updated_at": time.Now(),
+	}
+	if visible != nil {
+		updates["progress_visible"] = *visible
+	}
+
+	return r.db.Model(&models.ClubMember{}).Where("club_id = ? AND user_id = ?", clubID, userID).Updates(updates).Error
+}
+
+// GetClubSections retrieves the reading schedule of a book club with the number of posts of each section,
+// in the order of their dates.
+//
+// Parameters:
+// - clubID: a string representing the ID of the club.
+//
+// Returns:
+// - *[]models.ClubSection: a pointer to a slice of models.ClubSection.
+// - error: an error object if there was an issue retrieving the schedule.
+func (r *clubRepositoryImp) GetClubSections(clubID string) (*[]models.ClubSection, error) {
+	sections := make([]models.ClubSection, 0)
+
+	err := r.db.
+		Select("club_sections.*, (SELECT COUNT(*) FROM club_posts WHERE club_posts.section_id = club_sections.id) AS posts").
+		Where("club_id = ?", clubID).
+		Order("starts_on ASC, start_page ASC, created_at ASC").
+		Find(&sections).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &sections, nil
+}
+
+// GetClubSection retrieves a section of the reading schedule of a book club.
+//
+// Parameters:
+// - clubID: a string representing the ID of the club.
+// - sectionID: a string representing the ID of the section.
+//
+// Returns:
+// - *models.ClubSection: a pointer to the section.
+// - error: an error object if the section was not found or there was an issue retrieving it.
+func (r *clubRepositoryImp) GetClubSection(clubID, sectionID string) (*models.ClubSection, error) {
+	var section models.ClubSection
+
+	if err := r.db.First(&section, "id = ? AND club_id = ?", sectionID, clubID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("section not found")
+		}
+		return nil, err
+	}
+
+	return &section, nil
+}
+
+// CreateClubSection adds a section to the reading schedule of a book club. Only an owner can add it, once
+// the club has a current book.
+//
+// Parameters:
+// - userID: a string representing the ID of the user adding the section.
+// - section: a pointer to the section, with its ID and club.
+//
+// Returns:
+// - error: an error object if the section does not fit the book or there was an issue adding it.
+func (r *clubRepositoryImp) CreateClubSection(userID string, section *models.ClubSection) error {
+	member, err := clubAccess(r.db, userID, section.ClubID.String(), "owner")
+	if err != nil {
+		return err
+	}
+
+	if err := ensureSectionFitsBook(&member.Club, section); err != nil {
+		return err
+	}
+
+	return r.db.Create(section).Error
+}
+
+// UpdateClubSection updates the title, pages and dates of a section of the reading schedule of a book club.
+// Only an owner can update it.
+//
+// Parameters:
+// - userID: a string representing the ID of the user updating the section.
+// - section: a pointer to the section, with its ID, club and new values.
+//
+// Returns:
+// - error: an error object if the section was not found, does not fit the book or there was an issue updating it.
+func (r *clubRepositoryImp) UpdateClubSection(userID string, section *models.ClubSection) error {
+	member, err := clubAccess(r.db, userID, section.ClubID.String(), "owner")
+	if err != nil {
+		return err
+	}
+
+	if err := ensureSectionFitsBook(&member.Club, section); err != nil {
+		return err
+	}
+
+	result := r.db.Model(&models.ClubSection{}).Where("id = ? AND club_id = ?", section.ID, section.ClubID).Updates(map[string]interface{}{
+		"title":      section.Title,
+		"start_page": section.StartPage,
+		"end_page":   section.EndPage,
+		"starts_on":  section.StartsOn,
+		"ends_on":    section.EndsOn,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("section not found")
+	}
+
+	return nil
+}
+
+// DeleteClubSection deletes a section of the reading schedule of a book club with its discussion. Only an
+// owner can delete it.
+//
+// Parameters:
+// - userID: a string representing the ID of the user deleting the section.
+// - clubID: a string representing the ID of the club.
+// - sectionID: a string representing the ID of the section.
+//
+// Returns:
+// - error: an error object if the section was not found or there was an issue deleting it.
+func (r *clubRepositoryImp) DeleteClubSection(userID, clubID, sectionID string) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	if _, err := clubAccess(tx, userID, clubID, "owner"); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Where("section_id IN (?)", tx.Model(&models.ClubSection{}).Select("id").Where("id = ? AND club_id = ?", sectionID, clubID)).Delete(&models.ClubPost{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	result := tx.Where("id = ? AND club_id = ?", sectionID, clubID).Delete(&models.ClubSection{})
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return fmt.Errorf("section not found")
+	}
+
+	return tx.Commit().Error
+}
+
+// GetClubPosts retrieves the discussion of a section of the reading schedule of a book club with the handle
+// and display name of the authors, oldest first.
+//
+// Parameters:
+// - sectionID: a string representing the ID of the section.
+//
+// Returns:
+// - *[]models.ClubPost: a pointer to a slice of models.ClubPost, with the progress of their authors.
+// - error: an error object if there was an issue retrieving the posts.
+func (r *clubRepositoryImp) GetClubPosts(sectionID string) (*[]models.ClubPost, error) {
+	posts := make([]models.ClubPost, 0)
+
+	err := r.db.
+		Select("club_posts.*, users.handle, users.display_name").
+		Joins("JOIN users ON users.id = club_posts.user_id").
+		Where("club_posts.section_id = ?", sectionID).
+		Order("club_posts.created_at ASC").
+		Find(&posts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &posts, nil
+}
+
+// CreateClubPost adds a post to the discussion of a section of the reading schedule of a book club.
+//
+// Parameters:
+// - post: a pointer to the post, with its ID, section, user and the progress of the user.
+//
+// Returns:
+// - error: an error object if there was an issue adding the post.
+func (r *clubRepositoryImp) CreateClubPost(post *models.ClubPost) error {
+	return r.db.Omit("User").Create(post).Error
+}
+
+// DeleteClubPost deletes a post of the discussion of a section of a book club. Authors delete their own posts
+// and owners delete any post of their club.
+//
+// Parameters:
+// - userID: a string representing the ID of the user deleting the post.
+// - clubID: a string representing the ID of the club.
+// - sectionID: a string representing the ID of the section.
+// - postID: a string representing the ID of the post.
+//
+// Returns:
+// - error: an error object if the post was not found, belongs to another member or there was an issue deleting it.
+func (r *clubRepositoryImp) DeleteClubPost(userID, clubID, sectionID, postID string) error {
+	member, err := clubAccess(r.db, userID, clubID, "member")
+	if err != nil {
+		return err
+	}
+
+	var post models.ClubPost
+	err = r.db.
+		Joins("JOIN club_sections ON club_sections.id = club_posts.section_id").
+		First(&post, "club_posts.id = ? AND club_posts.section_id = ? AND club_sections.club_id = ?", postID, sectionID, clubID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("post not found")
+		}
+		return err
+	}
+
+	if post.UserID.String() != userID && member.Role != "owner" {
+		return errors.New("insufficient club permissions")
+	}
+
+	return r.db.Where("id = ?", post.ID).Delete(&models.ClubPost{}).Error
+}
+
+// clubAccess retrieves the membership of a user in a book club, with the club.
+//
+// It returns "club not found" when the user is not a member of the club, so the existence of other clubs is
+// not revealed, and "insufficient club permissions" when the role of the user is lower than the required role.
+func clubAccess(db *gorm.DB, userID, clubID, role string) (*models.ClubMember, error) {
+	var member models.ClubMember
+
+	if err := db.Preload("Club").First(&member, "club_id = ? AND user_id = ?", clubID, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("club not found")
+		}
+		return nil, err
+	}
+
+	if models.ClubRoles[member.Role] < models.ClubRoles[role] {
+		return nil, errors.New("insufficient club permissions")
+	}
+
+	member.Club.Role = member.Role
+
+	return &member, nil
+}
+
+// ensureAnotherClubOwner returns an error when the member is the only owner of the club.
+func ensureAnotherClubOwner(tx *gorm.DB, clubID, memberID string) error {
+	var owners int64
+
+	err := tx.Model(&models.ClubMember{}).
+		Where("club_id = ? AND user_id <> ? AND role = ?", clubID, memberID, "owner").
+		Count(&owners).Error
+	if err != nil {
+		return err
+	}
+
+	if owners == 0 {
+		return errors.New("a club must keep at least one owner")
+	}
+
+	return nil
+}
+
+// ensureSectionFitsBook returns an error when the club has no current book or the section ends beyond its last page.
+func ensureSectionFitsBook(club *models.Club, section *models.ClubSection) error {
+	if club.BookID == nil {
+		return errors.New("the club has no current book")
+	}
+
+	if club.BookPages > 0 && section.EndPage > club.BookPages {
+		return fmt.Errorf("end_page must not be beyond the last page of the book (%d)", club.BookPages)
+	}
+
+	return nil
+}
+
+// deleteClubSchedule deletes the sections of the schedule of a club with their discussions.
+func deleteClubSchedule(tx *gorm.DB, clubID string) error {
+	sections := tx.Model(&models.ClubSection{}).Select("id").Where("club_id = ?", clubID)
+
+	if err := tx.Where("section_id IN (?)", sections).Delete(&models.ClubPost{}).Error; err != nil {
+		return err
+	}
+
+	return tx.Where("club_id = ?", clubID).Delete(&models.ClubSection{}).Error
+}
@@ -0,0 +1,281 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"mybooks/internal/domain/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CopyRepository interface {
+	CreateCopy(bookCopy *models.Copy) error
+	GetBookCopies(orgID, bookID string) (*[]models.Copy, error)
+	UpdateCopy(userID, orgID string, bookCopy *models.Copy) error
+	DeleteCopy(orgID, id string) error
+}
+
+type copyRepositoryImp struct {
+	db *gorm.DB
+}
+
+// NewCopyRepository creates a new instance of the CopyRepository interface.
+//
+// It takes a *gorm.DB parameter, which represents the database connection.
+// It returns a CopyRepository pointer, which is an implementation of the CopyRepository interface.
+func NewCopyRepository(db *gorm.DB) CopyRepository {
+	return &copyRepositoryImp{
+		db: db,
+	}
+}
+
+// CreateCopy adds a copy to a book of an organization.
+//
+// When the copy is placed in a library, the book is added to the library too.
+// It returns an error if the book was not found or the copy cannot be placed in the library.
+func (r *copyRepositoryImp) CreateCopy(bookCopy *models.Copy) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	if err := tx.First(&models.Book{}, "id = ? AND organization_id = ?", bookCopy.BookID, bookCopy.OrganizationID).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("book not found")
+		}
+		return err
+	}
+
+	if err := placeCopy(tx, bookCopy.UserID.String(), bookCopy); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Omit("User").Create(bookCopy).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// GetBookCopies retrieves the copies of a book of an organization in the order they were added.
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+// - bookID: a string representing the book ID.
+//
+// Returns:
+// - *[]models.Copy: a pointer to a slice of models.Copy representing the copies of the book.
+// - error: an error object if the book was not found or there was an issue retrieving the copies.
+func (r *copyRepositoryImp) GetBookCopies(orgID, bookID string) (*[]models.Copy, error) {
+	if err := r.db.First(&models.Book{}, "id = ? AND organization_id = ?", bookID, orgID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("book not found")
+		}
+		return nil, err
+	}
+
+	var copies []models.Copy
+
+	if err := r.db.Where("book_id = ?", bookID).Order("created_at ASC").Find(&copies).Error; err != nil {
+		return nil, err
+	}
+
+	return &copies, nil
+}
+
+// UpdateCopy updates the condition, location, placement, acquisition and valuation details of a copy.
+//
+// Placing the copy in a library adds its book to the library, and a nil library ID takes the copy
+// out of its library. A copy leaving a library takes its book out of it, unless another copy of the
+// book is still placed there. The user must be at least an editor of the library.
+//
+// Parameters:
+// - userID: a string representing the ID of the user.
+// - orgID: a string representing the organization ID.
+// - bookCopy: a pointer to the copy with the new details.
+//
+// Returns:
+// - error: an error object if the copy was not found or cannot be placed in the library.
+func (r *copyRepositoryImp) UpdateCopy(userID, orgID string, bookCopy *models.Copy) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	var existing models.Copy
+	if err := tx.First(&existing, "id = ? AND organization_id = ?", bookCopy.ID, orgID).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("copy not found")
+		}
+		return err
+	}
+
+	bookCopy.BookID = existing.BookID
+	previousLibraryID := existing.LibraryID
+
+	// Only a copy moved to another library needs the access to it
+	moved := previousLibraryID == nil || bookCopy.LibraryID == nil || *previousLibraryID != *bookCopy.LibraryID
+	if moved {
+		if err := placeCopy(tx, userID, bookCopy); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Model(&existing).
+		Select("condition", "location", "library_id", "acquired_on", "acquired_from", "gift_from",
+			"price", "currency", "value", "value_currency", "valued_on", "notes").
+		Updates(models.Copy{
+			Condition:     bookCopy.Condition,
+			Location:      bookCopy.Location,
+			LibraryID:     bookCopy.LibraryID,
+			AcquiredOn:    bookCopy.AcquiredOn,
+			AcquiredFrom:  bookCopy.AcquiredFrom,
+			GiftFrom:      bookCopy.GiftFrom,
+			Price:         bookCopy.Price,
+			Currency:      bookCopy.Currency,
+			Value:         bookCopy.Value,
+			ValueCurrency: bookCopy.ValueCurrency,
+			ValuedOn:      bookCopy.ValuedOn,
+			Notes:         bookCopy.Notes,
+		}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if moved {
+		if err := unplaceCopy(tx, previousLibraryID, existing.BookID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+// DeleteCopy deletes a copy of a book of an organization.
+//
+// A copy that is lent cannot be deleted until it is returned, and the last copy of a book cannot be
+// deleted: the book itself is deleted instead. Deleting a copy takes its book out of the library of
+// the copy, unless another copy of the book is still placed there.
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+// - id: a string representing the copy ID.
+//
+// Returns:
+// - error: an error object if the copy was not found, is lent, is the last copy of its book or there was an issue deleting it.
+func (r *copyRepositoryImp) DeleteCopy(orgID, id string) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	var existing models.Copy
+	if err := tx.First(&existing, "id = ? AND organization_id = ?", id, orgID).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("copy not found")
+		}
+		return err
+	}
+
+	// Lock the book so that two copies deleted at the same time cannot both be taken for an extra copy
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&models.Book{}, "id = ?", existing.BookID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var count int64
+	if err := tx.Model(&models.Copy{}).Where("book_id = ?", existing.BookID).Count(&count).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if count <= 1 {
+		tx.Rollback()
+		return errors.New("cannot delete the last copy of a book, delete the book instead")
+	}
+
+	lent, err := copyLent(tx, existing.ID.String())
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if lent {
+		tx.Rollback()
+		return errors.New("copy is on loan")
+	}
+
+	if err := tx.Delete(&existing).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := unplaceCopy(tx, existing.LibraryID, existing.BookID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// placeCopy checks that the user can place the copy in its library and adds the book of the copy to
+// the library. A copy without library is not placed anywhere.
+func placeCopy(tx *gorm.DB, userID string, bookCopy *models.Copy) error {
+	if bookCopy.LibraryID == nil {
+		return nil
+	}
+
+	library, err := libraryAccess(tx, userID, bookCopy.LibraryID.String(), "editor")
+	if err != nil {
+		return err
+	}
+
+	if library.Rule != "" {
+		return errors.New("cannot add books to a smart library")
+	}
+
+	return appendBookToLibrary(tx, library.ID, bookCopy.BookID)
+}
+
+// unplaceCopy takes a book out of the library a copy of it left, unless another copy of the book is still
+// placed in the library, so that a library lists the books whose copies it holds.
+func unplaceCopy(tx *gorm.DB, libraryID *uuid.UUID, bookID uuid.UUID) error {
+	if libraryID == nil {
+		return nil
+	}
+
+	return tx.Exec(`DELETE FROM book_library WHERE library_id = ? AND book_id = ?
+		AND NOT EXISTS (SELECT 1 FROM copies WHERE copies.library_id = book_library.library_id AND copies.book_id = book_library.book_id)`,
+		libraryID, bookID).Error
+}
+
+// copyLent reports whether the copy has a loan that was not returned yet.
+func copyLent(db *gorm.DB, copyID string) (bool, error) {
+	var count int64
+
+	if err := db.Model(&models.Loan{}).
+		Where("copy_id = ? AND status IN ?", copyID, []string{models.LoanStatusActive, models.LoanStatusReturnPending}).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
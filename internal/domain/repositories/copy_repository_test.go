@@ -0,0 +1,192 @@
+package repositories
+
+import (
+	"mybooks/internal/domain/models"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// openTestDB connects to the PostgreSQL database of TEST_DATABASE_URL and creates the tables of the copies
+// and the libraries, or skips the test when no database is configured. Every test creates its own users,
+// organizations and books, so tests do not need an empty database.
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(url), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("connecting to the test database: %v", err)
+	}
+
+	err = db.AutoMigrate(&models.User{}, &models.Book{}, &models.Library{}, &models.BookLibrary{}, &models.Loan{},
+		&models.Author{}, &models.BookAuthor{}, &models.Series{}, &models.BookSeries{}, &models.Tag{},
+		&models.LibraryMember{}, &models.Organization{}, &models.OrganizationMember{}, &models.Borrower{},
+		&models.Work{}, &models.Copy{})
+	if err != nil {
+		t.Fatalf("migrating the test database: %v", err)
+	}
+
+	return db
+}
+
+// copyFixture is a user of an organization with two libraries and a book.
+type copyFixture struct {
+	user         models.User
+	organization models.Organization
+	libraries    [2]models.Library
+	book         models.Book
+}
+
+func newCopyFixture(t *testing.T, db *gorm.DB) *copyFixture {
+	t.Helper()
+
+	f := &copyFixture{}
+	f.user = models.User{ID: uuid.New(), Password: "secret"}
+	f.user.Email = f.user.ID.String() + "@example.com"
+	f.organization = models.Organization{ID: uuid.New(), Name: "Household", UserID: f.user.ID}
+	f.book = models.Book{ID: uuid.New(), Title: "Dune", Author: "Frank Herbert", OrganizationID: f.organization.ID, UserID: f.user.ID}
+	for i := range f.libraries {
+		f.libraries[i] = models.Library{ID: uuid.New(), Name: "Shelf", OrganizationID: f.organization.ID, UserID: f.user.ID}
+	}
+
+	member := models.OrganizationMember{OrganizationID: f.organization.ID, UserID: f.user.ID, Role: "owner"}
+	for _, value := range []interface{}{&f.user, &f.organization, &member, &f.libraries[0], &f.libraries[1], &f.book} {
+		if err := db.Omit("User", "Organization", "Children", "Books", "Libraries", "Authors", "Series", "Tags", "Copies").Create(value).Error; err != nil {
+			t.Fatalf("creating the fixture: %v", err)
+		}
+	}
+
+	return f
+}
+
+// inLibraries reports whether the book of the fixture is listed in each of its libraries.
+func (f *copyFixture) inLibraries(t *testing.T, db *gorm.DB) [2]bool {
+	t.Helper()
+
+	var listed [2]bool
+	for i, library := range f.libraries {
+		var count int64
+		if err := db.Model(&models.BookLibrary{}).Where("library_id = ? AND book_id = ?", library.ID, f.book.ID).Count(&count).Error; err != nil {
+			t.Fatalf("counting the books of the library: %v", err)
+		}
+		listed[i] = count > 0
+	}
+
+	return listed
+}
+
+func TestCopyPlacement(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewCopyRepository(db)
+
+	// A library is given by its index in the fixture, or -1 for no library
+	tests := []struct {
+		name   string
+		copies []int
+		action string
+		target int
+		want   [2]bool
+	}{
+		{
+			name:   "moving the only copy moves the book",
+			copies: []int{0},
+			action: "update",
+			target: 1,
+			want:   [2]bool{false, true},
+		},
+		{
+			name:   "moving a copy keeps the book where another copy is",
+			copies: []int{0, 0},
+			action: "update",
+			target: 1,
+			want:   [2]bool{true, true},
+		},
+		{
+			name:   "unplacing the only copy takes the book out",
+			copies: []int{0},
+			action: "update",
+			target: -1,
+			want:   [2]bool{false, false},
+		},
+		{
+			name:   "unplacing a copy keeps the book where another copy is",
+			copies: []int{0, 0},
+			action: "update",
+			target: -1,
+			want:   [2]bool{true, false},
+		},
+		{
+			name:   "updating a copy without moving it keeps the book",
+			copies: []int{0},
+			action: "update",
+			target: 0,
+			want:   [2]bool{true, false},
+		},
+		{
+			name:   "deleting a copy takes the book out",
+			copies: []int{0, -1},
+			action: "delete",
+			want:   [2]bool{false, false},
+		},
+		{
+			name:   "deleting a copy keeps the book where another copy is",
+			copies: []int{0, 0},
+			action: "delete",
+			want:   [2]bool{true, false},
+		},
+		{
+			name:   "deleting a copy keeps the book in the library of another copy",
+			copies: []int{0, 1},
+			action: "delete",
+			want:   [2]bool{false, true},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := newCopyFixture(t, db)
+
+			copies := make([]models.Copy, len(test.copies))
+			for i, library := range test.copies {
+				copies[i] = models.Copy{ID: uuid.New(), BookID: f.book.ID, OrganizationID: f.organization.ID, UserID: f.user.ID}
+				if library >= 0 {
+					copies[i].LibraryID = &f.libraries[library].ID
+				}
+
+				if err := repo.CreateCopy(&copies[i]); err != nil {
+					t.Fatalf("CreateCopy() returned error: %v", err)
+				}
+			}
+
+			switch test.action {
+			case "update":
+				moved := copies[0]
+				moved.LibraryID = nil
+				if test.target >= 0 {
+					moved.LibraryID = &f.libraries[test.target].ID
+				}
+				moved.Location = "Living room"
+
+				if err := repo.UpdateCopy(f.user.ID.String(), f.organization.ID.String(), &moved); err != nil {
+					t.Fatalf("UpdateCopy() returned error: %v", err)
+				}
+			case "delete":
+				if err := repo.DeleteCopy(f.organization.ID.String(), copies[0].ID.String()); err != nil {
+					t.Fatalf("DeleteCopy() returned error: %v", err)
+				}
+			}
+
+			if got := f.inLibraries(t, db); got != test.want {
+				t.Errorf("the book is listed in the libraries %v, want %v", got, test.want)
+			}
+		})
+	}
+}
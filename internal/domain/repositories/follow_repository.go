@@ -0,0 +1,202 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"mybooks/internal/domain/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FollowRepository interface {
+	GetUserByHandle(handle string) (*models.User, error)
+	Follow(follow *models.Follow) error
+	Unfollow(followerID, followedID string) error
+	GetFollowers(userID string, filters map[string]interface{}) (*[]models.FollowUser, error)
+	GetFollowing(userID string, filters map[string]interface{}) (*[]models.FollowUser, error)
+	ApproveFollower(userID, followerID string) error
+	RemoveFollower(userID, followerID string) error
+}
+
+type followRepositoryImp struct {
+	db *gorm.DB
+}
+
+// NewFollowRepository creates a new instance of the FollowRepository interface.
+//
+// It takes a *gorm.DB parameter, which represents the database connection.
+// It returns a FollowRepository pointer, which is an implementation of the FollowRepository interface.
+func NewFollowRepository(db *gorm.DB) FollowRepository {
+	return &followRepositoryImp{
+		db: db,
+	}
+}
+
+// GetUserByHandle retrieves the user with a handle, whether their profile is public or not.
+//
+// Parameters:
+// - handle: the handle of the user.
+//
+// Returns:
+// - *models.User: a pointer to the user.
+// - error: an error object if there is no user with the handle or there was an issue retrieving it.
+func (r *followRepositoryImp) GetUserByHandle(handle string) (*models.User, error) {
+	var user models.User
+	if err := r.db.First(&user, "handle = ?", handle).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// Follow makes a user follow another one. Following a user already followed keeps the existing follow,
+// and the follow is reloaded afterwards, so it holds its current status.
+//
+// Parameters:
+// - follow: a pointer to the follow, with the status of a new follow.
+//
+// Returns:
+// - error: an error object if there was an issue saving the follow.
+func (r *followRepositoryImp) Follow(follow *models.Follow) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	if err := tx.Omit("Follower", "Followed").Clauses(clause.OnConflict{DoNothing: true}).Create(follow).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var stored models.Follow
+	if err := tx.First(&stored, "follower_id = ? AND followed_id = ?", follow.FollowerID, follow.FollowedID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	*follow = stored
+
+	return tx.Commit().Error
+}
+
+// Unfollow stops a user from following another one, or cancels their pending follow request.
+//
+// Parameters:
+// - followerID: a string representing the ID of the follower.
+// - followedID: a string representing the ID of the followed user.
+//
+// Returns:
+// - error: an error object if the user was not followed or there was an issue removing the follow.
+func (r *followRepositoryImp) Unfollow(followerID, followedID string) error {
+	result := r.db.Where("follower_id = ? AND followed_id = ?", followerID, followedID).Delete(&models.Follow{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("follow not found")
+	}
+
+	return nil
+}
+
+// GetFollowers retrieves the users following a user, or asking to, most recent first.
+//
+// The "status" filter restricts the followers to the accepted or pending ones.
+//
+// Parameters:
+// - userID: a string representing the ID of the followed user.
+// - filters: a map of key-value pairs representing the filters to be applied.
+//
+// Returns:
+// - *[]models.FollowUser: a pointer to a slice of models.FollowUser representing the followers.
+// - error: an error object if there was an issue retrieving the followers.
+func (r *followRepositoryImp) GetFollowers(userID string, filters map[string]interface{}) (*[]models.FollowUser, error) {
+	return r.getFollowUsers("follows.followed_id = ?", "follows.follower_id", userID, filters)
+}
+
+// GetFollowing retrieves the users a user follows, or asked to, most recent first.
+//
+// The "status" filter restricts the followed users to the accepted or pending ones.
+//
+// Parameters:
+// - userID: a string representing the ID of the follower.
+// - filters: a map of key-value pairs representing the filters to be applied.
+//
+// Returns:
+// - *[]models.FollowUser: a pointer to a slice of models.FollowUser representing the followed users.
+// - error: an error object if there was an issue retrieving the followed users.
+func (r *followRepositoryImp) GetFollowing(userID string, filters map[string]interface{}) (*[]models.FollowUser, error) {
+	return r.getFollowUsers("follows.follower_id = ?", "follows.followed_id", userID, filters)
+}
+
+// ApproveFollower accepts the pending follow request of a user.
+//
+// Parameters:
+// - userID: a string representing the ID of the followed user.
+// - followerID: a string representing the ID of the user asking to follow.
+//
+// Returns:
+// - error: an error object if there is no such pending request or there was an issue accepting it.
+func (r *followRepositoryImp) ApproveFollower(userID, followerID string) error {
+	result := r.db.Model(&models.Follow{}).
+		Where("followed_id = ? AND follower_id = ? AND status = ?", userID, followerID, models.FollowStatusPending).
+		Update("status", models.FollowStatusAccepted)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("follow request not found")
+	}
+
+	return nil
+}
+
+// RemoveFollower removes a follower of a user, or declines their pending follow request.
+//
+// Parameters:
+// - userID: a string representing the ID of the followed user.
+// - followerID: a string representing the ID of the follower.
+//
+// Returns:
+// - error: an error object if the user is not a follower or there was an issue removing them.
+func (r *followRepositoryImp) RemoveFollower(userID, followerID string) error {
+	result := r.db.Where("followed_id = ? AND follower_id = ?", userID, followerID).Delete(&models.Follow{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("follower not found")
+	}
+
+	return nil
+}
+
+// getFollowUsers retrieves the users on the other side of the follows of a user, given the condition selecting
+// the follows of the user and the column holding the other user.
+func (r *followRepositoryImp) getFollowUsers(condition, otherColumn, userID string, filters map[string]interface{}) (*[]models.FollowUser, error) {
+	users := make([]models.FollowUser, 0)
+
+	query := r.db.Model(&models.Follow{}).
+		Select("users.id, users.handle, users.display_name, follows.status, follows.created_at").
+		Joins("JOIN users ON users.id = "+otherColumn+" AND users.deleted_at IS NULL").
+		Where(condition, userID)
+
+	if status, ok := filters["status"]; ok {
+		query = query.Where("follows.status = ?", status)
+	}
+
+	if err := query.Order("follows.created_at DESC").Scan(&users).Error; err != nil {
+		return nil, err
+	}
+
+	return &users, nil
+}
@@ -0,0 +1,202 @@
+package repositories
+
+import (
+	"fmt"
+	"mybooks/internal/domain/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GoalRepository interface {
+	SetGoal(goal *models.ReadingGoal) error
+	GetGoals(userID, orgID string, filters map[string]interface{}) (*[]models.ReadingGoal, error)
+	DeleteGoal(userID, orgID string, year int, kind string) error
+	GetReadBooks(orgID string, from, to time.Time) (*[]models.Book, error)
+	GetReadingTime(userID, orgID string, from, to time.Time) (int64, error)
+	GetReadingDates(userID, orgID, timezone string, before time.Time) ([]string, error)
+}
+
+type goalRepositoryImp struct {
+	db *gorm.DB
+}
+
+// NewGoalRepository creates a new instance of the GoalRepository interface.
+//
+// It takes a *gorm.DB parameter, which represents the database connection.
+// It returns a GoalRepository pointer, which is an implementation of the GoalRepository interface.
+func NewGoalRepository(db *gorm.DB) GoalRepository {
+	return &goalRepositoryImp{
+		db: db,
+	}
+}
+
+// SetGoal sets the target of a reading goal of a user for a year, replacing the previous target of the same kind.
+// The goal is reloaded afterwards, so it holds the ID and creation date of the stored goal.
+//
+// Parameters:
+// - goal: a pointer to the goal.
+//
+// Returns:
+// - error: an error object if there was an issue saving the goal.
+func (r *goalRepositoryImp) SetGoal(goal *models.ReadingGoal) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	err := tx.Omit("User").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "year"}, {Name: "kind"}, {Name: "organization_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"target", "updated_at"}),
+	}).Create(goal).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var stored models.ReadingGoal
+	err = tx.First(&stored, "year = ? AND kind = ? AND organization_id = ? AND user_id = ?", goal.Year, goal.Kind, goal.OrganizationID, goal.UserID).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	*goal = stored
+
+	return tx.Commit().Error
+}
+
+// GetGoals retrieves the reading goals of a user in an organization, most recent year first.
+//
+// The "year" filter restricts the goals to a year.
+//
+// Parameters:
+// - userID: a string representing the user ID.
+// - orgID: a string representing the organization ID.
+// - filters: a map of key-value pairs representing the filters to be applied.
+//
+// Returns:
+// - *[]models.ReadingGoal: a pointer to a slice of models.ReadingGoal representing the goals.
+// - error: an error object if there was an issue retrieving the goals.
+func (r *goalRepositoryImp) GetGoals(userID, orgID string, filters map[string]interface{}) (*[]models.ReadingGoal, error) {
+	var goals []models.ReadingGoal
+
+	query := r.db.Where("user_id = ? AND organization_id = ?", userID, orgID)
+
+	if year, ok := filters["year"]; ok {
+		query = query.Where("year = ?", year)
+	}
+
+	if err := query.Order("year DESC, kind ASC").Find(&goals).Error; err != nil {
+		return nil, err
+	}
+
+	return &goals, nil
+}
+
+// DeleteGoal deletes a reading goal of a user in an organization.
+//
+// Parameters:
+// - userID: a string representing the user ID.
+// - orgID: a string representing the organization ID.
+// - year: the year of the goal.
+// - kind: the kind of the goal, "books" or "pages".
+//
+// Returns:
+// - error: an error object if the goal was not found or there was an issue deleting it.
+func (r *goalRepositoryImp) DeleteGoal(userID, orgID string, year int, kind string) error {
+	result := r.db.
+		Where("user_id = ? AND organization_id = ? AND year = ? AND kind = ?", userID, orgID, year, kind).
+		Delete(&models.ReadingGoal{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("goal not found")
+	}
+
+	return nil
+}
+
+// GetReadBooks retrieves the books of an organization marked as read from and before the given times,
+// in the order they were read.
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+// - from: the start of the period.
+// - to: the end of the period, excluded.
+//
+// Returns:
+// - *[]models.Book: a pointer to a slice of models.Book with their title, author, pages and read date.
+// - error: an error object if there was an issue retrieving the books.
+func (r *goalRepositoryImp) GetReadBooks(orgID string, from, to time.Time) (*[]models.Book, error) {
+	var books []models.Book
+
+	err := r.db.Select("id", "title", "author", "pages", "read", "read_at", "work_id").
+		Where("organization_id = ? AND read = true AND read_at >= ? AND read_at < ?", orgID, from, to).
+		Order("read_at ASC").
+		Find(&books).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &books, nil
+}
+
+// GetReadingTime sums up the duration, in seconds, of the stopped reading sessions of a user on the books of
+// an organization started from and before the given times.
+//
+// Parameters:
+// - userID: a string representing the user ID.
+// - orgID: a string representing the organization ID.
+// - from: the start of the period.
+// - to: the end of the period, excluded.
+//
+// Returns:
+// - int64: the reading time in seconds.
+// - error: an error object if there was an issue summing up the sessions.
+func (r *goalRepositoryImp) GetReadingTime(userID, orgID string, from, to time.Time) (int64, error) {
+	var duration int64
+
+	err := r.db.Model(&models.ReadingSession{}).
+		Select("COALESCE(SUM(reading_sessions.duration), 0)").
+		Joins("JOIN books ON books.id = reading_sessions.book_id").
+		Where("reading_sessions.user_id = ? AND books.organization_id = ?", userID, orgID).
+		Where("reading_sessions.ended_at IS NOT NULL AND reading_sessions.started_at >= ? AND reading_sessions.started_at < ?", from, to).
+		Scan(&duration).Error
+
+	return duration, err
+}
+
+// GetReadingDates retrieves the days, as YYYY-MM-DD dates in the given timezone, on which a user started a
+// reading session on the books of an organization before the given time, in chronological order.
+//
+// Parameters:
+// - userID: a string representing the user ID.
+// - orgID: a string representing the organization ID.
+// - timezone: the name of the timezone of the user, such as "Europe/Paris".
+// - before: the end of the period, excluded.
+//
+// Returns:
+// - []string: the dates the user read on.
+// - error: an error object if there was an issue retrieving the dates.
+func (r *goalRepositoryImp) GetReadingDates(userID, orgID, timezone string, before time.Time) ([]string, error) {
+	dates := make([]string, 0)
+
+	err := r.db.Model(&models.ReadingSession{}).
+		Distinct("TO_CHAR(reading_sessions.started_at AT TIME ZONE ?, 'YYYY-MM-DD') AS date", timezone).
+		Joins("JOIN books ON books.id = reading_sessions.book_id").
+		Where("reading_sessions.user_id = ? AND books.organization_id = ?", userID, orgID).
+		Where("reading_sessions.ended_at IS NOT NULL AND reading_sessions.started_at < ?", before).
+		Order("date ASC").
+		Pluck("date", &dates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return dates, nil
+}
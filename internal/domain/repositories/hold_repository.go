@@ -0,0 +1,345 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"mybooks/internal/domain/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type HoldRepository interface {
+	CreateHold(libraryID string, hold *models.Hold) error
+	GetHolds(userID string) (*[]models.Hold, error)
+	GetBookHolds(orgID, bookID string) (*[]models.Hold, error)
+	CancelHold(userID, holdID string) (*models.Hold, error)
+	ExpireHolds() ([]models.Hold, error)
+}
+
+type holdRepositoryImp struct {
+	db *gorm.DB
+}
+
+func NewHoldRepository(db *gorm.DB) HoldRepository {
+	return &holdRepositoryImp{
+		db: db,
+	}
+}
+
+// holdSelectSQL selects the holds with the title of their book and, for the holds still in the queue,
+// their position in it.
+const holdSelectSQL = `holds.*, books.title AS book_title,
+	CASE WHEN holds.status IN ('waiting', 'ready') THEN (
+		SELECT COUNT(*) FROM holds queue
+		WHERE queue.book_id = holds.book_id AND queue.status IN ('waiting', 'ready') AND queue.created_at <= holds.created_at
+	) ELSE 0 END AS position`
+
+// CreateHold places a hold on a borrowed book of a library shared with the user.
+//
+// The hold belongs to the organization owning the book, so its members can see the queue.
+// It returns an error if the user cannot access the library, the book is not in the library, the book
+// belongs to the active organization of the user, the book is available, or the user is already
+// borrowing the book or waiting for it.
+func (r *holdRepositoryImp) CreateHold(libraryID string, hold *models.Hold) error {
+	userID := hold.UserID.String()
+
+	if _, err := libraryAccess(r.db, userID, libraryID, "viewer"); err != nil {
+		return err
+	}
+
+	var book models.Book
+	err := r.db.
+		Joins("JOIN book_library ON book_library.book_id = books.id AND book_library.library_id = ?", libraryID).
+		First(&book, "books.id = ?", hold.BookID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("book not found")
+		}
+
+		return err
+	}
+
+	if book.OrganizationID == hold.OrganizationID {
+		return errors.New("cannot borrow a book of your own organization")
+	}
+
+	var count int64
+	if err := r.db.Model(&models.Loan{}).
+		Where("book_id = ? AND borrower_id = ? AND status IN ?", hold.BookID, userID, []string{models.LoanStatusActive, models.LoanStatusReturnPending}).
+		Count(&count).Error; err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return errors.New("book already borrowed by you")
+	}
+
+	holds, err := openHolds(r.db, hold.BookID)
+	if err != nil {
+		return err
+	}
+
+	for _, open := range holds {
+		if open.UserID == hold.UserID {
+			return errors.New("book already on hold for you")
+		}
+	}
+
+	if err := ensureBookHasCopies(r.db, hold.BookID); err != nil {
+		return err
+	}
+
+	borrowed, err := bookBorrowed(r.db, hold.BookID)
+	if err != nil {
+		return err
+	}
+
+	if !borrowed && len(holds) == 0 {
+		return errors.New("book is available, request it instead")
+	}
+
+	hold.OrganizationID = book.OrganizationID
+
+	return r.db.Omit("User").Create(hold).Error
+}
+
+// GetHolds retrieves the holds placed by a user, most recent first.
+//
+// Parameters:
+// - userID: the ID of the user whose holds are being retrieved.
+//
+// Returns:
+// - *[]models.Hold: a pointer to a slice of models.Hold with the position of the open holds in their queue.
+// - error: an error if there was a problem retrieving the holds.
+func (r *holdRepositoryImp) GetHolds(userID string) (*[]models.Hold, error) {
+	var holds []models.Hold
+
+	err := r.db.
+		Select(holdSelectSQL).
+		Joins("JOIN books ON CAST(books.id AS TEXT) = holds.book_id").
+		Where("holds.user_id = ?", userID).
+		Order("holds.created_at DESC").
+		Find(&holds).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &holds, nil
+}
+
+// GetBookHolds retrieves the queue of a book of an organization, in the order the holds were placed.
+//
+// Parameters:
+// - orgID: the ID of the organization owning the book.
+// - bookID: the ID of the book.
+//
+// Returns:
+// - *[]models.Hold: a pointer to a slice of models.Hold with the waiting and ready holds and their users.
+// - error: an error if the book was not found or there was a problem retrieving the holds.
+func (r *holdRepositoryImp) GetBookHolds(orgID, bookID string) (*[]models.Hold, error) {
+	if err := r.db.First(&models.Book{}, "id = ? AND organization_id = ?", bookID, orgID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("book not found")
+		}
+
+		return nil, err
+	}
+
+	var holds []models.Hold
+
+	err := r.db.
+		Select(holdSelectSQL).
+		Joins("JOIN books ON CAST(books.id AS TEXT) = holds.book_id").
+		Preload("User").
+		Where("holds.book_id = ? AND holds.status IN ?", bookID, []string{models.HoldStatusWaiting, models.HoldStatusReady}).
+		Order("holds.created_at").
+		Find(&holds).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &holds, nil
+}
+
+// CancelHold removes a user from the queue of a book.
+//
+// When the cancelled hold was ready, the next hold in the queue becomes ready and is returned so
+// its user can be notified.
+//
+// Parameters:
+// - userID: the ID of the user who placed the hold.
+// - holdID: the ID of the hold being cancelled.
+//
+// Returns:
+// - *models.Hold: the hold that became ready, or nil.
+// - error: an error if the hold was not found or is not in the queue anymore.
+func (r *holdRepositoryImp) CancelHold(userID, holdID string) (*models.Hold, error) {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	var hold models.Hold
+	if err := tx.Where("id = ? AND user_id = ?", holdID, userID).First(&hold).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("hold not found")
+		}
+
+		return nil, err
+	}
+
+	if hold.Status != models.HoldStatusWaiting && hold.Status != models.HoldStatusReady {
+		tx.Rollback()
+		return nil, errors.New("hold is not in the queue")
+	}
+
+	if err := tx.Model(&hold).Update("status", models.HoldStatusCancelled).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	next, err := advanceHolds(tx, hold.BookID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return next, tx.Commit().Error
+}
+
+// ExpireHolds expires the ready holds that were not picked up in time and makes the next hold in the
+// queue of their books ready.
+//
+// Returns:
+// - []models.Hold: the holds that became ready, so their users can be notified.
+// - error: an error if there was a problem updating the holds.
+func (r *holdRepositoryImp) ExpireHolds() ([]models.Hold, error) {
+	var bookIDs []string
+
+	err := r.db.Model(&models.Hold{}).
+		Distinct("book_id").
+		Where("(status = ? AND expires_at <= ?) OR (status = ? AND NOT EXISTS (SELECT 1 FROM holds ready WHERE ready.book_id = holds.book_id AND ready.status = ?))",
+			models.HoldStatusReady, time.Now(), models.HoldStatusWaiting, models.HoldStatusReady).
+		Pluck("book_id", &bookIDs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ready := make([]models.Hold, 0)
+	for _, bookID := range bookIDs {
+		err := r.db.Transaction(func(tx *gorm.DB) error {
+			next, err := advanceHolds(tx, bookID)
+			if err != nil {
+				return err
+			}
+
+			if next != nil {
+				ready = append(ready, *next)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return ready, err
+		}
+	}
+
+	return ready, nil
+}
+
+// advanceHolds expires the ready hold of a book that was not picked up in time and, when the book is
+// not borrowed and no hold is ready, makes the first waiting hold ready.
+//
+// It returns the hold that became ready, with its user and the title of its book, or nil.
+func advanceHolds(tx *gorm.DB, bookID string) (*models.Hold, error) {
+	now := time.Now()
+
+	if err := tx.Model(&models.Hold{}).
+		Where("book_id = ? AND status = ? AND expires_at <= ?", bookID, models.HoldStatusReady, now).
+		Update("status", models.HoldStatusExpired).Error; err != nil {
+		return nil, err
+	}
+
+	var count int64
+	if err := tx.Model(&models.Hold{}).Where("book_id = ? AND status = ?", bookID, models.HoldStatusReady).Count(&count).Error; err != nil {
+		return nil, err
+	}
+
+	if count > 0 {
+		return nil, nil
+	}
+
+	borrowed, err := bookBorrowed(tx, bookID)
+	if err != nil || borrowed {
+		return nil, err
+	}
+
+	var hold models.Hold
+	err = tx.
+		Select("holds.*, books.title AS book_title").
+		Joins("JOIN books ON CAST(books.id AS TEXT) = holds.book_id").
+		Preload("User").
+		Where("holds.book_id = ? AND holds.status = ?", bookID, models.HoldStatusWaiting).
+		Order("holds.created_at").
+		First(&hold).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	expiresAt := now.Add(models.HoldPickupTTL)
+	if err := tx.Model(&hold).Updates(map[string]interface{}{
+		"status":     models.HoldStatusReady,
+		"expires_at": expiresAt,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	hold.Status = models.HoldStatusReady
+	hold.ExpiresAt = &expiresAt
+
+	return &hold, nil
+}
+
+// openHolds retrieves the holds of a book that are waiting or ready and not expired yet.
+func openHolds(db *gorm.DB, bookID string) ([]models.Hold, error) {
+	var holds []models.Hold
+
+	err := db.
+		Where("book_id = ? AND (status = ? OR (status = ? AND expires_at > ?))",
+			bookID, models.HoldStatusWaiting, models.HoldStatusReady, time.Now()).
+		Order("created_at").
+		Find(&holds).Error
+
+	return holds, err
+}
+
+// ensureNotOnHold returns "book is on hold" when the book has a queue, unless the hold of the
+// borrower is the one that is ready.
+func ensureNotOnHold(db *gorm.DB, bookID string, borrowerID *uuid.UUID) error {
+	holds, err := openHolds(db, bookID)
+	if err != nil {
+		return err
+	}
+
+	if len(holds) == 0 {
+		return nil
+	}
+
+	for _, hold := range holds {
+		if hold.Status == models.HoldStatusReady && borrowerID != nil && hold.UserID == *borrowerID {
+			return nil
+		}
+	}
+
+	return errors.New("book is on hold")
+}
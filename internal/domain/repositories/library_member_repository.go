@@ -0,0 +1,438 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"mybooks/internal/domain/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LibraryMemberRepository interface {
+	GetLibraryMembers(userID, libraryID string) (*[]models.LibraryMember, error)
+	UpdateLibraryMember(userID, libraryID, memberID, role string) error
+	RemoveLibraryMember(userID, libraryID, memberID string) error
+	CreateInvitation(userID string, invitation *models.LibraryInvitation, token *models.ValidationToken) error
+	GetLibraryInvitations(userID, libraryID string) (*[]models.LibraryInvitation, error)
+	RevokeInvitation(userID, libraryID, invitationID string) error
+	GetPendingInvitations(email string) (*[]models.LibraryInvitation, error)
+	RespondToInvitation(user *models.User, token string, accept bool) error
+}
+
+type libraryMemberRepositoryImp struct {
+	db *gorm.DB
+}
+
+// NewLibraryMemberRepository creates a new instance of the LibraryMemberRepository interface.
+//
+// It takes a *gorm.DB parameter, which represents the database connection.
+// It returns a LibraryMemberRepository pointer, which is an implementation of the LibraryMemberRepository interface.
+func NewLibraryMemberRepository(db *gorm.DB) LibraryMemberRepository {
+	return &libraryMemberRepositoryImp{
+		db: db,
+	}
+}
+
+// GetLibraryMembers retrieves the members of a library with their users, owners first.
+//
+// Parameters:
+// - userID: a string representing the ID of the user, who must be a member of the library.
+// - libraryID: a string representing the ID of the library.
+//
+// Returns:
+// - *[]models.LibraryMember: a pointer to a slice of models.LibraryMember objects.
+// - error: an error object if there was an issue retrieving the members.
+func (r *libraryMemberRepositoryImp) GetLibraryMembers(userID, libraryID string) (*[]models.LibraryMember, error) {
+	var members []models.LibraryMember
+
+	library, err := libraryAccess(r.db, userID, libraryID, "viewer")
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.
+		Preload("User").
+		Where("library_id = ?", library.ID).
+		Order("CASE role WHEN 'owner' THEN 0 WHEN 'editor' THEN 1 ELSE 2 END, created_at ASC").
+		Find(&members).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &members, nil
+}
+
+// UpdateLibraryMember changes the role of a member of a library.
+//
+// Only an owner can change roles, and the last owner of a library cannot be demoted.
+//
+// Parameters:
+// - userID: a string representing the ID of the user changing the role.
+// - libraryID: a string representing the ID of the library.
+// - memberID: a string representing the user ID of the member.
+// - role: the new role of the member.
+//
+// Returns:
+// - error: an error object if there was an issue updating the member.
+func (r *libraryMemberRepositoryImp) UpdateLibraryMember(userID, libraryID, memberID, role string) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	library, err := libraryAccess(tx, userID, libraryID, "owner")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	member, err := getLibraryMember(tx, library.ID.String(), memberID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if member.Role == "owner" && role != "owner" {
+		if err := ensureAnotherOwner(tx, library.ID.String(), memberID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Model(&models.LibraryMember{}).Where("library_id = ? AND user_id = ?", library.ID, memberID).Update("role", role).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// RemoveLibraryMember removes a member from a library.
+//
+// An owner can remove any member and every member can remove themselves to leave the library.
+// The last owner of a library cannot leave it, the library must be deleted instead.
+//
+// Parameters:
+// - userID: a string representing the ID of the user removing the member.
+// - libraryID: a string representing the ID of the library.
+// - memberID: a string representing the user ID of the member.
+//
+// Returns:
+// - error: an error object if there was an issue removing the member.
+func (r *libraryMemberRepositoryImp) RemoveLibraryMember(userID, libraryID, memberID string) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	role := "owner"
+	if userID == memberID {
+		role = "viewer"
+	}
+
+	library, err := libraryAccess(tx, userID, libraryID, role)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	member, err := getLibraryMember(tx, library.ID.String(), memberID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if member.Role == "owner" {
+		if err := ensureAnotherOwner(tx, library.ID.String(), memberID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Where("library_id = ? AND user_id = ?", library.ID, memberID).Delete(&models.LibraryMember{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// CreateInvitation invites an email address to a library.
+//
+// Only an owner can invite. The invitation is rejected when the email already belongs to a member,
+// and any pending invitation of the same email to the library is revoked first. The validation token
+// is issued by the inviting user, since the invited email may not have an account yet.
+//
+// Parameters:
+// - userID: a string representing the ID of the inviting user.
+// - invitation: a pointer to the invitation to create.
+// - token: a pointer to the validation token used to accept or decline the invitation.
+//
+// Returns:
+// - error: an error object if there was an issue creating the invitation.
+func (r *libraryMemberRepositoryImp) CreateInvitation(userID string, invitation *models.LibraryInvitation, token *models.ValidationToken) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	library, err := libraryAccess(tx, userID, invitation.LibraryID.String(), "owner")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var members int64
+	err = tx.Model(&models.LibraryMember{}).
+		Joins("JOIN users ON users.id = library_members.user_id").
+		Where("library_members.library_id = ? AND LOWER(users.email) = LOWER(?)", library.ID, invitation.Email).
+		Count(&members).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if members > 0 {
+		tx.Rollback()
+		return fmt.Errorf("%s is already a member of the library", invitation.Email)
+	}
+
+	if err := revokePendingInvitations(tx, "library_id = ? AND LOWER(email) = LOWER(?)", library.ID, invitation.Email); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Create(token).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Omit("Library").Create(invitation).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// GetLibraryInvitations retrieves the pending invitations of a library.
+//
+// Parameters:
+// - userID: a string representing the ID of the user, who must be an owner of the library.
+// - libraryID: a string representing the ID of the library.
+//
+// Returns:
+// - *[]models.LibraryInvitation: a pointer to a slice of models.LibraryInvitation objects.
+// - error: an error object if there was an issue retrieving the invitations.
+func (r *libraryMemberRepositoryImp) GetLibraryInvitations(userID, libraryID string) (*[]models.LibraryInvitation, error) {
+	var invitations []models.LibraryInvitation
+
+	library, err := libraryAccess(r.db, userID, libraryID, "owner")
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.
+		Where("library_id = ? AND status = ? AND expires_at > ?", library.ID, "pending", time.Now()).
+		Order("created_at DESC").
+		Find(&invitations).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &invitations, nil
+}
+
+// RevokeInvitation revokes a pending invitation of a library and invalidates its token.
+//
+// Parameters:
+// - userID: a string representing the ID of the user, who must be an owner of the library.
+// - libraryID: a string representing the ID of the library.
+// - invitationID: a string representing the ID of the invitation.
+//
+// Returns:
+// - error: an error object if there was an issue revoking the invitation.
+func (r *libraryMemberRepositoryImp) RevokeInvitation(userID, libraryID, invitationID string) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	library, err := libraryAccess(tx, userID, libraryID, "owner")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var invitation models.LibraryInvitation
+	if err := tx.First(&invitation, "id = ? AND library_id = ? AND status = ?", invitationID, library.ID, "pending").Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("invitation not found")
+		}
+		return err
+	}
+
+	if err := revokePendingInvitations(tx, "id = ?", invitation.ID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// GetPendingInvitations retrieves the pending invitations sent to an email address, with their libraries.
+//
+// Parameters:
+// - email: a string representing the email of the invited user.
+//
+// Returns:
+// - *[]models.LibraryInvitation: a pointer to a slice of models.LibraryInvitation objects.
+// - error: an error object if there was an issue retrieving the invitations.
+func (r *libraryMemberRepositoryImp) GetPendingInvitations(email string) (*[]models.LibraryInvitation, error) {
+	var invitations []models.LibraryInvitation
+
+	err := r.db.
+		Preload("Library").
+		Where("LOWER(email) = LOWER(?) AND status = ? AND expires_at > ?", email, "pending", time.Now()).
+		Order("created_at DESC").
+		Find(&invitations).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &invitations, nil
+}
+
+// RespondToInvitation accepts or declines an invitation to a library.
+//
+// The token must be a valid, unexpired library invitation token and the invitation must have been
+// sent to the email of the user. Accepting it makes the user a member with the invited role; a user
+// who already is a member keeps their current role. The token is invalidated either way.
+//
+// Parameters:
+// - user: a pointer to the user responding to the invitation.
+// - token: a string representing the invitation token.
+// - accept: true to accept the invitation, false to decline it.
+//
+// Returns:
+// - error: an error object if there was an issue responding to the invitation.
+func (r *libraryMemberRepositoryImp) RespondToInvitation(user *models.User, token string, accept bool) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	var validationToken models.ValidationToken
+	err := tx.Where("token = ? AND type = ?", token, "library_invitation").First(&validationToken).Error
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("invitation not found")
+		}
+		return err
+	}
+
+	if !validationToken.Valid || time.Now().After(validationToken.ExpiresAt) {
+		tx.Rollback()
+		return errors.New("invalid or expired invitation")
+	}
+
+	var invitation models.LibraryInvitation
+	err = tx.First(&invitation, "token = ? AND status = ? AND LOWER(email) = LOWER(?)", token, "pending", user.Email).Error
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("invitation not found")
+		}
+		return err
+	}
+
+	status := "declined"
+	if accept {
+		status = "accepted"
+
+		member := models.LibraryMember{
+			LibraryID: invitation.LibraryID,
+			UserID:    user.ID,
+			Role:      invitation.Role,
+		}
+
+		if err := tx.Omit("User", "Library").Clauses(clause.OnConflict{DoNothing: true}).Create(&member).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Model(&models.LibraryInvitation{}).Where("id = ?", invitation.ID).Update("status", status).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Model(&models.ValidationToken{}).Where("token = ?", token).Update("valid", false).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// getLibraryMember retrieves a member of a library.
+func getLibraryMember(tx *gorm.DB, libraryID, memberID string) (*models.LibraryMember, error) {
+	var member models.LibraryMember
+
+	if err := tx.First(&member, "library_id = ? AND user_id = ?", libraryID, memberID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("member not found")
+		}
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+// ensureAnotherOwner returns an error when the member is the only owner of the library.
+func ensureAnotherOwner(tx *gorm.DB, libraryID, memberID string) error {
+	var owners int64
+
+	err := tx.Model(&models.LibraryMember{}).
+		Where("library_id = ? AND user_id <> ? AND role = ?", libraryID, memberID, "owner").
+		Count(&owners).Error
+	if err != nil {
+		return err
+	}
+
+	if owners == 0 {
+		return errors.New("a library must keep at least one owner")
+	}
+
+	return nil
+}
+
+// revokePendingInvitations revokes the pending invitations matching the condition and invalidates their tokens.
+func revokePendingInvitations(tx *gorm.DB, condition string, args ...interface{}) error {
+	pending := tx.Model(&models.LibraryInvitation{}).Where("status = ?", "pending").Where(condition, args...)
+
+	if err := tx.Model(&models.ValidationToken{}).Where("token IN (?)", pending.Select("token")).Update("valid", false).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&models.LibraryInvitation{}).Where("status = ?", "pending").Where(condition, args...).Update("status", "revoked").Error
+}
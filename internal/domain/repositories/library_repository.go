@@ -4,19 +4,30 @@ import (
 	"errors"
 	"fmt"
 	"mybooks/internal/domain/models"
+	"mybooks/internal/domain/rules"
+	"strings"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type LibraryRepository interface {
 	CreateLibrary(library *models.Library) error
-	GetAllLibraries(userID string) (*[]models.Library, error)
+	GetAllLibraries(userID, orgID string) (*[]models.Library, error)
 	GetLibraryByID(userID, id string) (*models.Library, error)
 	UpdateLibrary(userID string, library *models.Library) error
-	DeleteLibrary(userID, id string) error
-	AddBookToLibrary(userID, libraryID, bookID string) error
+	DeleteLibrary(userID, id, childStrategy string) error
+	MoveLibrary(userID, id string, parentID *uuid.UUID) error
+	GetLibrarySubtree(userID, id string) (*[]models.Library, error)
+	GetBooksInLibraries(libraryIDs []uuid.UUID) (*[]models.Book, error)
+	MoveBookInLibrary(userID, libraryID, bookID string, index int) error
+	ReorderLibraryBooks(userID, libraryID string, bookIDs []uuid.UUID) error
+	AddBookToLibrary(userID, orgID, libraryID, bookID string) error
 	RemoveBookFromLibrary(userID, libraryID, bookID string) error
+	GetBooksByRule(orgID string, rule rules.Expr) (*[]models.Book, error)
+	SetLibraryVisibility(userID, id string, public bool) error
+	GetPublicLibrary(handle, id string) (*models.Library, error)
 }
 
 type libraryRepositoryImp struct {
@@ -36,25 +47,73 @@ func NewLibraryRepository(db *gorm.DB) LibraryRepository {
 // CreateLibrary creates a new library in the database.
 //
 // It takes a pointer to a Library struct as a parameter and returns an error.
+// The creator of the library becomes its first owner. If the library has a parent,
+// the parent must belong to the same organization and the creator must be at least an editor of it.
 func (r *libraryRepositoryImp) CreateLibrary(library *models.Library) error {
-	return r.db.Create(library).Error
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	if library.ParentID != nil {
+		parent, err := libraryAccess(tx, library.UserID.String(), library.ParentID.String(), "editor")
+		if err != nil {
+			tx.Rollback()
+			if strings.Contains(err.Error(), "library not found") {
+				return fmt.Errorf("parent library not found")
+			}
+			return err
+		}
+
+		if parent.OrganizationID != library.OrganizationID {
+			tx.Rollback()
+			return fmt.Errorf("parent library not found")
+		}
+	}
+
+	if err := tx.Omit("Children").Create(library).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	member := models.LibraryMember{
+		LibraryID: library.ID,
+		UserID:    library.UserID,
+		Role:      "owner",
+	}
+
+	if err := tx.Omit("User", "Library").Create(&member).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
 }
 
 // GetAllLibraries retrieves all libraries from the library repository.
 //
-// It takes a userID as a parameter and returns a pointer to a slice of models.Library objects representing the retrieved libraries.
+// It takes a userID and an orgID as parameters and returns a pointer to a slice of models.Library objects representing the retrieved libraries.
+// The libraries of the organization and the libraries shared with the user are returned, together with the role of the user in them.
 // If there is an error during the retrieval process, the function returns nil and the error.
 //
 // Parameters:
 // - userID: a string representing the user ID.
+// - orgID: a string representing the ID of the active organization.
 //
 // Returns:
 // - *[]models.Library: a pointer to a slice of models.Library objects representing the retrieved libraries.
 // - error: an error object if there was an issue retrieving the libraries.
-func (r *libraryRepositoryImp) GetAllLibraries(userID string) (*[]models.Library, error) {
+func (r *libraryRepositoryImp) GetAllLibraries(userID, orgID string) (*[]models.Library, error) {
 	var libraries []models.Library
 
-	err := r.db.Where("user_id = ?", userID).Find(&libraries).Error
+	err := r.db.
+		Select("libraries.*, access.role AS role").
+		Joins("JOIN ("+libraryRolesSQL+") access ON access.library_id = libraries.id", userID, userID).
+		Where("libraries.organization_id = ? OR libraries.id IN (SELECT library_id FROM library_members WHERE user_id = ?)", orgID, userID).
+		Find(&libraries).Error
 	if err != nil {
 		return nil, err
 	}
@@ -66,6 +125,8 @@ func (r *libraryRepositoryImp) GetAllLibraries(userID string) (*[]models.Library
 //
 // It takes a userID and an id as parameters. The function returns a pointer to a models.Library object
 // representing the retrieved library, and an error if there was an issue retrieving the library.
+// The books of the library are returned in their manual order, whoever owns them.
+// The user must be a member of the library.
 //
 // Parameters:
 // - userID: a string representing the user ID.
@@ -75,31 +136,43 @@ func (r *libraryRepositoryImp) GetAllLibraries(userID string) (*[]models.Library
 // - *models.Library: a pointer to the retrieved library.
 // - error: an error object if there was an issue retrieving the library.
 func (r *libraryRepositoryImp) GetLibraryByID(userID, id string) (*models.Library, error) {
-	var library models.Library
+	library, err := libraryAccess(r.db, userID, id, "viewer")
+	if err != nil {
+		return nil, err
+	}
 
-	if err := r.db.Preload("Books").First(&library, "id = ? AND user_id = ?", id, userID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("library not found")
-		}
+	err = r.db.
+		Select("books.*").
+		Joins("JOIN book_library ON book_library.book_id = books.id").
+		Where("book_library.library_id = ?", library.ID).
+		Order("book_library.position ASC, books.created_at ASC").
+		Find(&library.Books).Error
+	if err != nil {
 		return nil, err
 	}
 
-	return &library, nil
+	return library, nil
 }
 
 // DeleteLibrary deletes a library from the libraryRepositoryImp by its ID.
 //
-// It takes a userID and an id as parameters. The function deletes the library from the repository
-// based on the provided ID. It uses the GORM library to perform the delete operation.
-// The function returns an error if there was an issue deleting the library.
+// It takes a userID, an id and a child strategy as parameters. The strategy decides what
+// happens to the child libraries:
+// - "reject": the library is not deleted if it has children.
+// - "cascade": the whole subtree is deleted. The books themselves are kept.
+// - "reparent": the children are moved to the parent of the deleted library.
+//
+// Only an owner can delete a library, and a cascade is rejected when the user does not
+// own every library of the subtree. Members and invitations are deleted with the library.
 //
 // Parameters:
 // - userID: a string representing the user ID.
 // - id: a string representing the ID of the library to delete.
+// - childStrategy: a string representing what to do with the child libraries.
 //
 // Returns:
 // - error: an error object if there was an issue deleting the library.
-func (r *libraryRepositoryImp) DeleteLibrary(userID, id string) error {
+func (r *libraryRepositoryImp) DeleteLibrary(userID, id, childStrategy string) error {
 	tx := r.db.Begin()
 	defer func() {
 		if r := recover(); r != nil {
@@ -108,23 +181,83 @@ func (r *libraryRepositoryImp) DeleteLibrary(userID, id string) error {
 		}
 	}()
 
+	library, err := libraryAccess(tx, userID, id, "owner")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	libraryIDs := []uuid.UUID{library.ID}
+
+	switch childStrategy {
+	case "cascade":
+		var children int64
+		if err := tx.Raw(`WITH RECURSIVE access AS (`+libraryRolesSQL+`), subtree AS (
+			SELECT id FROM libraries WHERE parent_id = ?
+			UNION ALL
+			SELECT libraries.id FROM libraries JOIN subtree ON libraries.parent_id = subtree.id
+		) SELECT COUNT(*) FROM subtree WHERE NOT EXISTS (
+			SELECT 1 FROM access WHERE access.library_id = subtree.id AND access.role = 'owner')`, userID, userID, library.ID).Scan(&children).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if children > 0 {
+			tx.Rollback()
+			return errors.New("library has child libraries you do not own")
+		}
+
+		ids, err := subtreeIDs(tx, userID, library.ID.String())
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		libraryIDs = ids
+	case "reparent":
+		if err := tx.Model(&models.Library{}).Where("parent_id = ?", library.ID).Update("parent_id", library.ParentID).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	default:
+		var children int64
+		if err := tx.Model(&models.Library{}).Where("parent_id = ?", library.ID).Count(&children).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if children > 0 {
+			tx.Rollback()
+			return errors.New("library has child libraries")
+		}
+	}
+
 	// Delete the relation in the book_library table
-	if err := tx.Exec("DELETE FROM book_library WHERE library_id = ?", id).Error; err != nil {
+	if err := tx.Exec("DELETE FROM book_library WHERE library_id IN ?", libraryIDs).Error; err != nil {
 		tx.Rollback()
 		return err
 	}
 
-	// Delete the library
-	result := tx.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Library{})
-	if result.Error != nil {
+	// Take the copies placed in the libraries out of them
+	if err := tx.Model(&models.Copy{}).Where("library_id IN ?", libraryIDs).Update("library_id", nil).Error; err != nil {
 		tx.Rollback()
-		return result.Error
+		return err
 	}
 
-	// Check if no rows were affected (library not found)
-	if result.RowsAffected == 0 {
+	// Delete the members and the invitations of the libraries
+	if err := tx.Where("library_id IN ?", libraryIDs).Delete(&models.LibraryMember{}).Error; err != nil {
 		tx.Rollback()
-		return errors.New("library not found")
+		return err
+	}
+
+	if err := tx.Where("library_id IN ?", libraryIDs).Delete(&models.LibraryInvitation{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Delete the libraries
+	if err := tx.Where("id IN ?", libraryIDs).Delete(&models.Library{}).Error; err != nil {
+		tx.Rollback()
+		return err
 	}
 
 	return tx.Commit().Error
@@ -135,30 +268,57 @@ func (r *libraryRepositoryImp) DeleteLibrary(userID, id string) error {
 // It takes a userID and a library as parameters and returns an error.
 // The function updates the library in the repository based on the provided ID.
 // It uses the GORM library to perform the update operation.
+// The user must be at least an editor of the library.
 // The function returns an error if there was an issue updating the library.
 func (r *libraryRepositoryImp) UpdateLibrary(userID string, library *models.Library) error {
-	result := r.db.Model(&models.Library{}).Omit("ID", "CreatedAt").Where("id = ? AND user_id = ?", library.ID, userID).Updates(library)
-	if result.Error != nil {
-		return result.Error
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	if _, err := libraryAccess(tx, userID, library.ID.String(), "editor"); err != nil {
+		tx.Rollback()
+		return err
 	}
 
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("library not found")
+	// The columns are selected so that an empty description or rule is written too, turning a smart library back into a manual one
+	if err := tx.Model(&models.Library{}).Select("Name", "Description", "Rule", "UpdatedAt").Where("id = ?", library.ID).Updates(library).Error; err != nil {
+		tx.Rollback()
+		return err
 	}
 
-	return nil
+	// A smart library computes its books from the rule, so the manual list and the copies placed in it are dropped
+	if library.Rule != "" {
+		if err := tx.Exec("DELETE FROM book_library WHERE library_id = ?", library.ID).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if err := tx.Model(&models.Copy{}).Where("library_id = ?", library.ID).Update("library_id", nil).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
 }
 
 // AddBookToLibrary adds a book to a library in the library repository.
 //
+// The user must be at least an editor of the library and can only add books of their active organization.
+//
 // Parameters:
 // - userID: a string representing the ID of the user.
+// - orgID: a string representing the ID of the active organization.
 // - libraryID: a string representing the ID of the library.
 // - bookID: a string representing the ID of the book.
 //
 // Returns:
 // - error: an error object if there was an issue adding the book to the library.
-func (r *libraryRepositoryImp) AddBookToLibrary(userID, libraryID, bookID string) error {
+func (r *libraryRepositoryImp) AddBookToLibrary(userID, orgID, libraryID, bookID string) error {
 	libUUID, err := uuid.Parse(libraryID)
 	if err != nil {
 		return err
@@ -177,28 +337,28 @@ func (r *libraryRepositoryImp) AddBookToLibrary(userID, libraryID, bookID string
 		}
 	}()
 
-	var library models.Library
-	err = tx.First(&library, "id = ? AND user_id = ?", libUUID, userID).Error
+	library, err := libraryAccess(tx, userID, libUUID.String(), "editor")
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return fmt.Errorf("library not found")
-		}
 		tx.Rollback()
 		return err
 	}
 
+	if library.Rule != "" {
+		tx.Rollback()
+		return errors.New("cannot add books to a smart library")
+	}
+
 	var book models.Book
-	err = tx.First(&book, "id = ? AND user_id = ?", bookUUID, userID).Error
+	err = tx.First(&book, "id = ? AND organization_id = ?", bookUUID, orgID).Error
 	if err != nil {
+		tx.Rollback()
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return fmt.Errorf("book not found")
 		}
-
-		tx.Rollback()
 		return err
 	}
 
-	if err := tx.Model(&library).Association("Books").Append(&book); err != nil {
+	if err := appendBookToLibrary(tx, library.ID, book.ID); err != nil {
 		tx.Rollback()
 		return err
 	}
@@ -208,6 +368,9 @@ func (r *libraryRepositoryImp) AddBookToLibrary(userID, libraryID, bookID string
 
 // RemoveBookFromLibrary removes a book from a library in the library repository.
 //
+// The user must be at least an editor of the library and can remove any book of it,
+// including the books added by other members.
+//
 // Parameters:
 // - userID: a string representing the ID of the user.
 // - libraryID: a string representing the ID of the library.
@@ -234,32 +397,804 @@ func (r *libraryRepositoryImp) RemoveBookFromLibrary(userID, libraryID, bookID s
 		}
 	}()
 
-	var library models.Library
-	err = tx.First(&library, "id = ? AND user_id = ?", libUUID, userID).Error
+	library, err := libraryAccess(tx, userID, libUUID.String(), "editor")
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return fmt.Errorf("library not found")
-		}
-
 		tx.Rollback()
 		return err
 	}
 
+	if library.Rule != "" {
+		tx.Rollback()
+		return errors.New("cannot remove books from a smart library")
+	}
+
 	var book models.Book
-	err = tx.First(&book, "id = ? AND user_id = ?", bookUUID, userID).Error
+	err = tx.First(&book, "id = ? AND id IN (SELECT book_id FROM book_library WHERE library_id = ?)", bookUUID, library.ID).Error
 	if err != nil {
+		tx.Rollback()
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return fmt.Errorf("book not found")
 		}
+		return err
+	}
+
+	if err := tx.Model(library).Association("Books").Delete(&book); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// The copies of the book placed in the library are not there anymore
+	if err := tx.Model(&models.Copy{}).Where("book_id = ? AND library_id = ?", book.ID, library.ID).Update("library_id", nil).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// MoveLibrary moves a library, with its whole subtree, under another library.
+//
+// A nil parentID moves the library to the root. A library cannot be moved under itself
+// or one of its descendants, since that would create a cycle. The user must be an owner
+// of the library and at least an editor of the new parent, which must belong to the same organization.
+//
+// Parameters:
+// - userID: a string representing the ID of the user.
+// - id: a string representing the ID of the library to move.
+// - parentID: a pointer to the ID of the new parent library, or nil.
+//
+// Returns:
+// - error: an error object if there was an issue moving the library.
+func (r *libraryRepositoryImp) MoveLibrary(userID, id string, parentID *uuid.UUID) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	library, err := libraryAccess(tx, userID, id, "owner")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if parentID != nil {
+		parent, err := libraryAccess(tx, userID, parentID.String(), "editor")
+		if err != nil {
+			tx.Rollback()
+			if strings.Contains(err.Error(), "library not found") {
+				return fmt.Errorf("parent library not found")
+			}
+			return err
+		}
+
+		if parent.OrganizationID != library.OrganizationID {
+			tx.Rollback()
+			return fmt.Errorf("parent library not found")
+		}
+
+		// Walk up from the new parent over every library, including the ones the user cannot see
+		var cycles int64
+		if err := tx.Raw(`WITH RECURSIVE ancestors AS (
+			SELECT id, parent_id FROM libraries WHERE id = ?
+			UNION
+			SELECT libraries.id, libraries.parent_id FROM libraries JOIN ancestors ON libraries.id = ancestors.parent_id
+		) SELECT COUNT(*) FROM ancestors WHERE id = ?`, *parentID, library.ID).Scan(&cycles).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if cycles > 0 {
+			tx.Rollback()
+			return errors.New("cannot move a library into itself or one of its descendants")
+		}
+	}
+
+	if err := tx.Model(&models.Library{}).Where("id = ?", library.ID).Update("parent_id", parentID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// GetLibrarySubtree retrieves a library and all of its descendants the user is a member of.
+//
+// Parameters:
+// - userID: a string representing the ID of the user.
+// - id: a string representing the ID of the root library of the subtree.
+//
+// Returns:
+// - *[]models.Library: a pointer to a slice of models.Library objects, starting with the root library.
+// - error: an error object if there was an issue retrieving the libraries.
+func (r *libraryRepositoryImp) GetLibrarySubtree(userID, id string) (*[]models.Library, error) {
+	var libraries []models.Library
+
+	ids, err := subtreeIDs(r.db, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("library not found")
+	}
+
+	err = r.db.
+		Select("libraries.*, access.role AS role").
+		Joins("JOIN ("+libraryRolesSQL+") access ON access.library_id = libraries.id", userID, userID).
+		Where("libraries.id IN ?", ids).
+		Find(&libraries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the root library first
+	for i := range libraries {
+		if libraries[i].ID == ids[0] {
+			libraries[0], libraries[i] = libraries[i], libraries[0]
+		}
+	}
+
+	return &libraries, nil
+}
+
+// GetBooksInLibraries retrieves the distinct books manually added to any of the given libraries.
+//
+// Parameters:
+// - libraryIDs: a slice of library IDs.
+//
+// Returns:
+// - *[]models.Book: a pointer to a slice of models.Book objects ordered by title.
+// - error: an error object if there was an issue retrieving the books.
+func (r *libraryRepositoryImp) GetBooksInLibraries(libraryIDs []uuid.UUID) (*[]models.Book, error) {
+	var books []models.Book
+
+	err := r.db.
+		Where("id IN (SELECT book_id FROM book_library WHERE library_id IN ?)", libraryIDs).
+		Order("title ASC").
+		Find(&books).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &books, nil
+}
+
+// MoveBookInLibrary moves a book of a library to the given index of its manual order.
+//
+// Only the position of the moved book is rewritten: it takes the midpoint between the positions
+// of its new neighbours. When the neighbours are too close to split, the whole library is renumbered.
+// An index past the end moves the book to the end.
+//
+// Parameters:
+// - userID: a string representing the ID of the user.
+// - libraryID: a string representing the ID of the library.
+// - bookID: a string representing the ID of the book.
+// - index: the zero-based index the book is moved to.
+//
+// Returns:
+// - error: an error object if there was an issue moving the book.
+func (r *libraryRepositoryImp) MoveBookInLibrary(userID, libraryID, bookID string, index int) error {
+	bookUUID, err := uuid.Parse(bookID)
+	if err != nil {
+		return err
+	}
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	library, err := r.getManualLibrary(tx, userID, libraryID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	positions, err := libraryPositions(tx, library.ID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	order := make([]models.BookLibrary, 0, len(positions))
+	found := false
+	for _, position := range positions {
+		if position.BookID == bookUUID {
+			found = true
+			continue
+		}
+		order = append(order, position)
+	}
+
+	if !found {
+		tx.Rollback()
+		return fmt.Errorf("book not found")
+	}
+
+	if index > len(order) {
+		index = len(order)
+	}
+
+	moved := models.BookLibrary{LibraryID: library.ID, BookID: bookUUID}
+	order = append(order[:index], append([]models.BookLibrary{moved}, order[index:]...)...)
+
+	if newPosition, ok := positionBetween(order, index); ok {
+		order[index].Position = newPosition
+		err = updateLibraryPositions(tx, []models.BookLibrary{order[index]})
+	} else {
+		err = updateLibraryPositions(tx, renumberPositions(order))
+	}
+
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// ReorderLibraryBooks sets the manual order of every book of a library.
+//
+// The bookIDs must contain every book of the library exactly once. The longest run of books that
+// already are in the requested relative order keeps its positions, and only the other books get
+// new positions between them, so a small change in a large library rewrites a few rows.
+//
+// Parameters:
+// - userID: a string representing the ID of the user.
+// - libraryID: a string representing the ID of the library.
+// - bookIDs: a slice of book IDs in the requested order.
+//
+// Returns:
+// - error: an error object if there was an issue reordering the books.
+func (r *libraryRepositoryImp) ReorderLibraryBooks(userID, libraryID string, bookIDs []uuid.UUID) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
 
+	library, err := r.getManualLibrary(tx, userID, libraryID)
+	if err != nil {
 		tx.Rollback()
 		return err
 	}
 
-	if err := tx.Model(&library).Association("Books").Delete(&book); err != nil {
+	positions, err := libraryPositions(tx, library.ID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	current := make(map[uuid.UUID]float64)
+	for _, position := range positions {
+		current[position.BookID] = position.Position
+	}
+
+	if len(bookIDs) != len(positions) {
+		tx.Rollback()
+		return errors.New("book_ids must contain every book of the library exactly once")
+	}
+
+	order := make([]models.BookLibrary, 0, len(bookIDs))
+	seen := make(map[uuid.UUID]bool)
+	for _, bookID := range bookIDs {
+		position, ok := current[bookID]
+		if !ok || seen[bookID] {
+			tx.Rollback()
+			return errors.New("book_ids must contain every book of the library exactly once")
+		}
+
+		seen[bookID] = true
+		order = append(order, models.BookLibrary{LibraryID: library.ID, BookID: bookID, Position: position})
+	}
+
+	changed, ok := reorderPositions(order)
+	if !ok {
+		changed = renumberPositions(order)
+	}
+
+	if err := updateLibraryPositions(tx, changed); err != nil {
 		tx.Rollback()
 		return err
 	}
 
 	return tx.Commit().Error
 }
+
+// getManualLibrary retrieves a library the user can edit and rejects smart libraries, whose order is computed.
+func (r *libraryRepositoryImp) getManualLibrary(tx *gorm.DB, userID, libraryID string) (*models.Library, error) {
+	library, err := libraryAccess(tx, userID, libraryID, "editor")
+	if err != nil {
+		return nil, err
+	}
+
+	if library.Rule != "" {
+		return nil, errors.New("cannot reorder books of a smart library")
+	}
+
+	return library, nil
+}
+
+// publicBookColumns are the only columns of the books of a public library read for visitors. The read
+// status, ratings, notes, copies and prices of the books stay private.
+var publicBookColumns = []string{
+	"books.id", "books.title", "books.author", "books.description", "books.cover", "books.genre",
+	"books.isbn", "books.published_date", "books.language", "books.pages",
+}
+
+// SetLibraryVisibility makes a library public or private. A public library can be seen by anyone from the
+// public profile of the user who created it, as long as that profile is public too.
+//
+// Only an owner of the library can change its visibility.
+//
+// Parameters:
+// - userID: a string representing the ID of the user.
+// - id: a string representing the ID of the library.
+// - public: whether the library is public.
+//
+// Returns:
+// - error: an error object if there was an issue changing the visibility of the library.
+func (r *libraryRepositoryImp) SetLibraryVisibility(userID, id string, public bool) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	if _, err := libraryAccess(tx, userID, id, "owner"); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Model(&models.Library{}).Where("id = ?", id).Update("public", public).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// GetPublicLibrary retrieves a public library of a public profile, with its public child libraries and the
+// books added to it.
+//
+// Only the books of the organization owning the library are returned, in their manual order, with the
+// public columns only: the books other members of a shared library added from their own organizations
+// stay private. The books of a smart library are not loaded, since they come from its rule.
+//
+// Parameters:
+// - handle: the handle of the public profile.
+// - id: a string representing the ID of the library.
+//
+// Returns:
+// - *models.Library: a pointer to the library.
+// - error: an error object if there is no such public library or there was an issue retrieving it.
+func (r *libraryRepositoryImp) GetPublicLibrary(handle, id string) (*models.Library, error) {
+	libraryID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("library not found")
+	}
+
+	var library models.Library
+	err = r.db.
+		Select("libraries.*").
+		Joins("JOIN users ON users.id = libraries.user_id AND users.deleted_at IS NULL").
+		Where("users.handle = ? AND users.public = true AND libraries.public = true", handle).
+		First(&library, "libraries.id = ?", libraryID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("library not found")
+		}
+		return nil, err
+	}
+
+	err = r.db.
+		Where("parent_id = ? AND user_id = ? AND public = true", library.ID, library.UserID).
+		Order("name ASC").
+		Find(&library.Children).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if library.Rule != "" {
+		return &library, nil
+	}
+
+	err = r.db.
+		Select(publicBookColumns).
+		Joins("JOIN book_library ON book_library.book_id = books.id").
+		Where("book_library.library_id = ? AND books.organization_id = ?", library.ID, library.OrganizationID).
+		Order("book_library.position ASC, books.created_at ASC").
+		Find(&library.Books).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &library, nil
+}
+
+// libraryAccess retrieves a library the user can access, with the role of the user in it.
+//
+// It returns "library not found" when the user cannot access the library, so the existence
+// of other libraries is not revealed, and "insufficient library permissions" when the role of
+// the user is lower than the required role.
+func libraryAccess(db *gorm.DB, userID, libraryID, role string) (*models.Library, error) {
+	var library models.Library
+
+	err := db.
+		Select("libraries.*, access.role AS role").
+		Joins("JOIN ("+libraryRolesSQL+") access ON access.library_id = libraries.id", userID, userID).
+		First(&library, "libraries.id = ?", libraryID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("library not found")
+		}
+		return nil, err
+	}
+
+	if models.LibraryRoles[library.Role] < models.LibraryRoles[role] {
+		return nil, errors.New("insufficient library permissions")
+	}
+
+	return &library, nil
+}
+
+// libraryRolesSQL selects the highest role of a user in every library they can access, either as a member
+// of the library or as a member of the organization owning it: owners and admins of an organization own
+// its libraries and the other members edit them. It takes the ID of the user twice.
+const libraryRolesSQL = `SELECT library_id, (ARRAY['viewer', 'editor', 'owner'])[MAX(rank)] AS role FROM (
+	SELECT library_id, CASE role WHEN 'owner' THEN 3 WHEN 'editor' THEN 2 ELSE 1 END AS rank
+	FROM library_members WHERE user_id = ?
+	UNION ALL
+	SELECT libraries.id, CASE WHEN organization_members.role IN ('owner', 'admin') THEN 3 ELSE 2 END
+	FROM libraries JOIN organization_members ON organization_members.organization_id = libraries.organization_id
+	WHERE organization_members.user_id = ?
+) roles GROUP BY library_id`
+
+// appendBookToLibrary adds a book after the last book of a library. A book already in the library keeps its position.
+func appendBookToLibrary(tx *gorm.DB, libraryID, bookID uuid.UUID) error {
+	var last float64
+	if err := tx.Model(&models.BookLibrary{}).Where("library_id = ?", libraryID).Select("COALESCE(MAX(position), 0)").Scan(&last).Error; err != nil {
+		return err
+	}
+
+	link := models.BookLibrary{
+		LibraryID: libraryID,
+		BookID:    bookID,
+		Position:  last + models.LibraryPositionGap,
+	}
+
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&link).Error
+}
+
+// minPositionGap is the smallest distance between two positions before a library is renumbered.
+const minPositionGap = 1e-6
+
+// libraryPositions returns the books of a library with their positions in manual order.
+func libraryPositions(tx *gorm.DB, libraryID uuid.UUID) ([]models.BookLibrary, error) {
+	var positions []models.BookLibrary
+
+	if err := tx.Where("library_id = ?", libraryID).Order("position ASC, book_id ASC").Find(&positions).Error; err != nil {
+		return nil, err
+	}
+
+	return positions, nil
+}
+
+// positionBetween returns a position for the book at the given index that sorts it between its neighbours.
+// Positions are always positive, since 0 marks the books placed before manual ordering existed: a book moved
+// first gets half the position of the book after it.
+// It returns false when the neighbours are too close to fit a new position.
+func positionBetween(order []models.BookLibrary, index int) (float64, bool) {
+	hasPrevious := index > 0
+	hasNext := index < len(order)-1
+
+	previous := 0.0
+	if hasPrevious {
+		previous = order[index-1].Position
+	}
+
+	switch {
+	case hasNext:
+		next := order[index+1].Position
+		if next-previous < 2*minPositionGap {
+			return 0, false
+		}
+		return previous + (next-previous)/2, true
+	case hasPrevious:
+		return previous + models.LibraryPositionGap, true
+	default:
+		return models.LibraryPositionGap, true
+	}
+}
+
+// reorderPositions assigns positions to the books of order so that they sort in the slice order.
+//
+// The books forming the longest increasing run of current positions keep them, and the books in
+// between are spread evenly across the gaps, the books before the first kept one between 0 and it
+// so that positions stay positive. It returns only the books whose position changed, or false when
+// a gap is too small and the library must be renumbered.
+func reorderPositions(order []models.BookLibrary) ([]models.BookLibrary, bool) {
+	keep := longestIncreasingPositions(order)
+	changed := make([]models.BookLibrary, 0)
+
+	for start := 0; start < len(order); {
+		if keep[start] {
+			start++
+			continue
+		}
+
+		end := start
+		for end < len(order) && !keep[end] {
+			end++
+		}
+
+		count := float64(end - start)
+		var lower, upper float64
+		switch {
+		case start > 0 && end < len(order):
+			lower, upper = order[start-1].Position, order[end].Position
+		case start > 0:
+			lower = order[start-1].Position
+			upper = lower + (count+1)*models.LibraryPositionGap
+		case end < len(order):
+			lower, upper = 0, order[end].Position
+		default:
+			lower, upper = 0, (count+1)*models.LibraryPositionGap
+		}
+
+		step := (upper - lower) / (count + 1)
+		if step < minPositionGap {
+			return nil, false
+		}
+
+		for i := start; i < end; i++ {
+			order[i].Position = lower + step*float64(i-start+1)
+			changed = append(changed, order[i])
+		}
+
+		start = end
+	}
+
+	return changed, true
+}
+
+// longestIncreasingPositions marks the books forming the longest strictly increasing run of positions.
+func longestIncreasingPositions(order []models.BookLibrary) []bool {
+	tails := make([]int, 0)
+	previous := make([]int, len(order))
+
+	for i := range order {
+		low, high := 0, len(tails)
+		for low < high {
+			middle := (low + high) / 2
+			if order[tails[middle]].Position < order[i].Position {
+				low = middle + 1
+			} else {
+				high = middle
+			}
+		}
+
+		previous[i] = -1
+		if low > 0 {
+			previous[i] = tails[low-1]
+		}
+
+		if low == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[low] = i
+		}
+	}
+
+	keep := make([]bool, len(order))
+	if len(tails) > 0 {
+		for i := tails[len(tails)-1]; i >= 0; i = previous[i] {
+			keep[i] = true
+		}
+	}
+
+	return keep
+}
+
+// renumberPositions spreads the books of order evenly, LibraryPositionGap apart, and returns all of them.
+func renumberPositions(order []models.BookLibrary) []models.BookLibrary {
+	for i := range order {
+		order[i].Position = float64(i+1) * models.LibraryPositionGap
+	}
+
+	return order
+}
+
+// updateLibraryPositions stores the positions of the given books.
+func updateLibraryPositions(tx *gorm.DB, positions []models.BookLibrary) error {
+	for _, position := range positions {
+		err := tx.Model(&models.BookLibrary{}).
+			Where("library_id = ? AND book_id = ?", position.LibraryID, position.BookID).
+			Update("position", position.Position).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// subtreeIDs returns the ID of a library followed by the IDs of all of its descendants.
+// The walk only descends through the libraries the user can access.
+func subtreeIDs(db *gorm.DB, userID, id string) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+
+	err := db.Raw(`WITH RECURSIVE access AS (`+libraryRolesSQL+`), subtree AS (
+		SELECT libraries.id, 0 AS depth FROM libraries
+		JOIN access ON access.library_id = libraries.id
+		WHERE libraries.id = ?
+		UNION ALL
+		SELECT libraries.id, subtree.depth + 1 FROM libraries JOIN subtree ON libraries.parent_id = subtree.id
+		JOIN access ON access.library_id = libraries.id
+	) SELECT id FROM subtree ORDER BY depth`, userID, userID, id).Scan(&ids).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// GetBooksByRule retrieves the books of an organization matching the rule of a smart library.
+//
+// The rule is translated to a SQL condition on the books table, so the membership is always
+// computed from the current state of the books. The books are ordered by title.
+// A shared smart library is evaluated against the books of the organization owning it.
+//
+// Parameters:
+// - orgID: a string representing the ID of the organization whose books are matched.
+// - rule: the parsed rule of the smart library.
+//
+// Returns:
+// - *[]models.Book: a pointer to a slice of models.Book objects matching the rule.
+// - error: an error object if there was an issue retrieving the books.
+func (r *libraryRepositoryImp) GetBooksByRule(orgID string, rule rules.Expr) (*[]models.Book, error) {
+	var books []models.Book
+
+	condition, args, err := ruleToSQL(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.db.Where("organization_id = ?", orgID).Where(condition, args...).Order("title ASC").Find(&books).Error; err != nil {
+		return nil, err
+	}
+
+	return &books, nil
+}
+
+// ruleColumns maps the rule fields stored in the books table to their column.
+var ruleColumns = map[string]string{
+	"title":          "books.title",
+	"author":         "books.author",
+	"description":    "books.description",
+	"genre":          "books.genre",
+	"isbn":           "books.isbn",
+	"language":       "books.language",
+	"published_date": "books.published_date",
+	"pages":          "books.pages",
+	"read":           "books.read",
+}
+
+// ruleSubqueries maps the rule fields stored in related tables to an EXISTS condition on the books table.
+// The "%s" placeholder receives the comparison on the name of the related row.
+var ruleSubqueries = map[string]string{
+	"tag": `EXISTS (SELECT 1 FROM book_tag JOIN tags ON tags.id = book_tag.tag_id
+		WHERE book_tag.book_id = books.id AND %s)`,
+	"series": `EXISTS (SELECT 1 FROM book_series JOIN series ON series.id = book_series.series_id
+		WHERE book_series.book_id = books.id AND %s)`,
+}
+
+// ruleSubqueryColumns maps the rule fields stored in related tables to the compared column.
+var ruleSubqueryColumns = map[string]string{
+	"tag":    "tags.name",
+	"series": "series.name",
+}
+
+// ruleToSQL translates a parsed rule into a SQL condition and its arguments.
+func ruleToSQL(expr rules.Expr) (string, []interface{}, error) {
+	switch e := expr.(type) {
+	case *rules.Binary:
+		left, leftArgs, err := ruleToSQL(e.Left)
+		if err != nil {
+			return "", nil, err
+		}
+
+		right, rightArgs, err := ruleToSQL(e.Right)
+		if err != nil {
+			return "", nil, err
+		}
+
+		operator := "AND"
+		if e.Operator == rules.Or {
+			operator = "OR"
+		}
+
+		return "(" + left + " " + operator + " " + right + ")", append(leftArgs, rightArgs...), nil
+	case *rules.Not:
+		condition, args, err := ruleToSQL(e.Expr)
+		if err != nil {
+			return "", nil, err
+		}
+
+		return "NOT (" + condition + ")", args, nil
+	case *rules.Comparison:
+		return comparisonToSQL(e)
+	default:
+		return "", nil, fmt.Errorf("unsupported rule expression")
+	}
+}
+
+// comparisonToSQL translates a single comparison of a rule into a SQL condition and its arguments.
+//
+// String comparisons are case-insensitive. Negated comparisons on tags and series mean
+// "the book has no tag (or series) with this name".
+func comparisonToSQL(c *rules.Comparison) (string, []interface{}, error) {
+	if subquery, ok := ruleSubqueries[c.Field]; ok {
+		value, _ := c.Value.(string)
+		column := ruleSubqueryColumns[c.Field]
+
+		switch c.Operator {
+		case rules.Equal:
+			return fmt.Sprintf(subquery, "LOWER("+column+") = LOWER(?)"), []interface{}{value}, nil
+		case rules.NotEqual:
+			return "NOT " + fmt.Sprintf(subquery, "LOWER("+column+") = LOWER(?)"), []interface{}{value}, nil
+		case rules.Contains:
+			return fmt.Sprintf(subquery, "LOWER("+column+") LIKE ?"), []interface{}{containsPattern(value)}, nil
+		}
+
+		return "", nil, fmt.Errorf("operator %s is not allowed for field %s", c.Operator, c.Field)
+	}
+
+	column, ok := ruleColumns[c.Field]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown field %s", c.Field)
+	}
+
+	switch value := c.Value.(type) {
+	case string:
+		switch c.Operator {
+		case rules.Equal:
+			return "LOWER(" + column + ") = LOWER(?)", []interface{}{value}, nil
+		case rules.NotEqual:
+			return "LOWER(" + column + ") <> LOWER(?)", []interface{}{value}, nil
+		case rules.Contains:
+			return "LOWER(" + column + ") LIKE ?", []interface{}{containsPattern(value)}, nil
+		}
+	case float64, bool:
+		operators := map[string]string{
+			rules.Equal:          "=",
+			rules.NotEqual:       "<>",
+			rules.Less:           "<",
+			rules.LessOrEqual:    "<=",
+			rules.Greater:        ">",
+			rules.GreaterOrEqual: ">=",
+		}
+
+		if operator, ok := operators[c.Operator]; ok {
+			return column + " " + operator + " ?", []interface{}{value}, nil
+		}
+	}
+
+	return "", nil, fmt.Errorf("operator %s is not allowed for field %s", c.Operator, c.Field)
+}
+
+// containsPattern builds a case-insensitive LIKE pattern matching the value anywhere in a column.
+func containsPattern(value string) string {
+	replacer := strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_")
+	return "%" + replacer.Replace(strings.ToLower(value)) + "%"
+}
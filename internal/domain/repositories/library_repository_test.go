@@ -0,0 +1,223 @@
+package repositories
+
+import (
+	"fmt"
+	"mybooks/internal/domain/models"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// libraryOrder builds the books of a library in the given order with the given positions. The ID of a book is
+// its index, so that tests can tell which books were moved.
+func libraryOrder(positions ...float64) []models.BookLibrary {
+	order := make([]models.BookLibrary, len(positions))
+	for i, position := range positions {
+		order[i] = models.BookLibrary{BookID: libraryBookID(i), Position: position}
+	}
+
+	return order
+}
+
+func libraryBookID(i int) uuid.UUID {
+	return uuid.MustParse(fmt.Sprintf("00000000-0000-0000-0000-%012d", i))
+}
+
+func TestPositionBetween(t *testing.T) {
+	tests := []struct {
+		name  string
+		order []models.BookLibrary
+		index int
+		want  float64
+		ok    bool
+	}{
+		{
+			name:  "only book",
+			order: libraryOrder(0),
+			index: 0,
+			want:  1024,
+			ok:    true,
+		},
+		{
+			name:  "head takes half the position of the first book",
+			order: libraryOrder(0, 1024, 2048),
+			index: 0,
+			want:  512,
+			ok:    true,
+		},
+		{
+			name:  "tail goes a gap after the last book",
+			order: libraryOrder(1024, 2048, 0),
+			index: 2,
+			want:  3072,
+			ok:    true,
+		},
+		{
+			name:  "middle takes the midpoint of its neighbours",
+			order: libraryOrder(1024, 0, 2048),
+			index: 1,
+			want:  1536,
+			ok:    true,
+		},
+		{
+			name:  "exhausted gap in the middle",
+			order: libraryOrder(1024, 0, 1024+1e-6),
+			index: 1,
+			ok:    false,
+		},
+		{
+			name:  "exhausted gap at the head",
+			order: libraryOrder(0, 1e-6),
+			index: 0,
+			ok:    false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := positionBetween(test.order, test.index)
+			if ok != test.ok {
+				t.Fatalf("positionBetween() = %v, %v, want ok %v", got, ok, test.ok)
+			}
+
+			if ok && got != test.want {
+				t.Errorf("positionBetween() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestReorderPositions(t *testing.T) {
+	tests := []struct {
+		name    string
+		order   []models.BookLibrary
+		want    []float64
+		changed []int
+		ok      bool
+	}{
+		{
+			name:    "already in order",
+			order:   libraryOrder(1024, 2048, 3072),
+			want:    []float64{1024, 2048, 3072},
+			changed: []int{},
+			ok:      true,
+		},
+		{
+			name:    "last book moved to the head",
+			order:   libraryOrder(3072, 1024, 2048),
+			want:    []float64{512, 1024, 2048},
+			changed: []int{0},
+			ok:      true,
+		},
+		{
+			name:    "first book moved to the tail",
+			order:   libraryOrder(2048, 3072, 1024),
+			want:    []float64{2048, 3072, 4096},
+			changed: []int{2},
+			ok:      true,
+		},
+		{
+			name:    "book moved in the middle",
+			order:   libraryOrder(1024, 3072, 2048, 4096),
+			want:    []float64{1024, 1536, 2048, 4096},
+			changed: []int{1},
+			ok:      true,
+		},
+		{
+			name:    "full reverse keeps one book and spreads the others before it",
+			order:   libraryOrder(4096, 3072, 2048, 1024),
+			want:    []float64{256, 512, 768, 1024},
+			changed: []int{0, 1, 2},
+			ok:      true,
+		},
+		{
+			name:  "exhausted gap",
+			order: libraryOrder(1024, 5000, 1024+5e-7),
+			ok:    false,
+		},
+		{
+			name:  "equal positions",
+			order: libraryOrder(0, 0, 0),
+			ok:    false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			changed, ok := reorderPositions(test.order)
+			if ok != test.ok {
+				t.Fatalf("reorderPositions() returned ok %v, want %v", ok, test.ok)
+			}
+
+			if !ok {
+				return
+			}
+
+			positions := make([]float64, len(test.order))
+			for i, book := range test.order {
+				positions[i] = book.Position
+			}
+
+			if !reflect.DeepEqual(positions, test.want) {
+				t.Errorf("reorderPositions() set the positions %v, want %v", positions, test.want)
+			}
+
+			changedIDs := make([]uuid.UUID, 0, len(changed))
+			for _, book := range changed {
+				changedIDs = append(changedIDs, book.BookID)
+			}
+
+			wantIDs := make([]uuid.UUID, 0, len(test.changed))
+			for _, i := range test.changed {
+				wantIDs = append(wantIDs, libraryBookID(i))
+			}
+
+			if !reflect.DeepEqual(changedIDs, wantIDs) {
+				t.Errorf("reorderPositions() changed the books %v, want %v", changedIDs, wantIDs)
+			}
+		})
+	}
+}
+
+func TestLongestIncreasingPositions(t *testing.T) {
+	tests := []struct {
+		name  string
+		order []models.BookLibrary
+		want  []bool
+	}{
+		{
+			name:  "empty",
+			order: libraryOrder(),
+			want:  []bool{},
+		},
+		{
+			name:  "increasing",
+			order: libraryOrder(1, 2, 3),
+			want:  []bool{true, true, true},
+		},
+		{
+			name:  "decreasing keeps the last book",
+			order: libraryOrder(3, 2, 1),
+			want:  []bool{false, false, true},
+		},
+		{
+			name:  "run interrupted by moved books",
+			order: libraryOrder(5, 1, 2, 8, 3, 4),
+			want:  []bool{false, true, true, false, true, true},
+		},
+		{
+			name:  "equal positions are not increasing",
+			order: libraryOrder(1, 1, 2),
+			want:  []bool{false, true, true},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := longestIncreasingPositions(test.order); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("longestIncreasingPositions() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -4,14 +4,19 @@ import (
 	"errors"
 	"fmt"
 	"mybooks/internal/domain/models"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type LoanRepository interface {
 	CreateLoan(loan *models.Loan) error
-	GetAllLoans(userID string) (*[]models.Loan, error)
-	ReturnLoan(userID, loanID string) error
+	RequestLoan(libraryID string, loan *models.Loan) error
+	GetAllLoans(userID, orgID string) (*[]models.Loan, error)
+	ApproveLoan(orgID, loanID, loanDate string) error
+	DeclineLoan(orgID, loanID string) error
+	ReturnLoan(userID, orgID, loanID string) (*models.Hold, error)
 }
 
 type loanRepositoryImp struct {
@@ -27,10 +32,23 @@ func NewLoanRepository(db *gorm.DB) LoanRepository {
 // CreateLoan creates a new loan in the database.
 //
 // It takes a pointer to a models.Loan object as a parameter, which represents the loan to be created.
-// It returns an error if there was a problem creating the loan, such as a book not found or a book already borrowed.
+// The loan is made of the copy given by ID, or of the first copy of the book that is not lent, to a borrower
+// of the contact book: either the one given by ID, or the one with the borrower name (case-insensitive),
+// which is created when the organization does not have it yet.
+// It returns an error if there was a problem creating the loan, such as a book, copy or borrower not found,
+// every copy already borrowed or a book on hold for a borrower waiting for it.
 // If the loan is created successfully, it returns nil.
 func (r *loanRepositoryImp) CreateLoan(loan *models.Loan) error {
-	if err := r.db.First(&models.Book{}, "id = ?", loan.BookID).Error; err != nil {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	if err := tx.First(&models.Book{}, "id = ? AND organization_id = ?", loan.BookID, loan.OrganizationID).Error; err != nil {
+		tx.Rollback()
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return fmt.Errorf("book not found")
 		}
@@ -38,48 +56,416 @@ func (r *loanRepositoryImp) CreateLoan(loan *models.Loan) error {
 		return err
 	}
 
-	var existingLoan models.Loan
-	if err := r.db.Where("book_id = ? AND is_returned = false", loan.BookID).First(&existingLoan).Error; err == nil {
-		return errors.New("book already borrowed")
+	if err := ensureNotOnHold(tx, loan.BookID, nil); err != nil {
+		tx.Rollback()
+		return err
 	}
 
-	if err := r.db.Create(loan).Error; err != nil {
+	if loan.CopyID != nil {
+		if err := tx.First(&models.Copy{}, "id = ? AND book_id = ?", loan.CopyID, loan.BookID).Error; err != nil {
+			tx.Rollback()
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("copy not found")
+			}
+
+			return err
+		}
+
+		lent, err := copyLent(tx, loan.CopyID.String())
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if lent {
+			tx.Rollback()
+			return errors.New("copy already borrowed")
+		}
+	} else {
+		bookCopy, err := availableCopy(tx, loan.BookID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		loan.CopyID = &bookCopy.ID
+	}
+
+	var borrower *models.Borrower
+	if loan.BorrowerContactID != nil {
+		borrower = new(models.Borrower)
+		if err := tx.First(borrower, "id = ? AND organization_id = ?", loan.BorrowerContactID, loan.OrganizationID).Error; err != nil {
+			tx.Rollback()
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("borrower not found")
+			}
+
+			return err
+		}
+	} else {
+		var err error
+		borrower, err = findOrCreateBorrower(tx, loan.OrganizationID, loan.UserID, strings.TrimSpace(loan.BorrowerName))
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	loan.BorrowerContactID = &borrower.ID
+	loan.BorrowerName = borrower.Name
+
+	if err := tx.Omit("BorrowerContact").Create(loan).Error; err != nil {
+		tx.Rollback()
 		return err
 	}
 
-	return nil
+	return tx.Commit().Error
 }
 
-// GetAllLoans retrieves all loans for a given user from the loan repository.
+// RequestLoan creates a borrow request for a book of a library shared with the borrower.
+//
+// The request belongs to the organization owning the book, so its members can approve or decline it.
+// It returns an error if the borrower cannot access the library, the book is not in the library, the book
+// belongs to the active organization of the borrower, the book is already borrowed, the book is on hold for
+// another borrower or the borrower already requested it.
+func (r *loanRepositoryImp) RequestLoan(libraryID string, loan *models.Loan) error {
+	borrowerID := loan.BorrowerID.String()
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	if _, err := libraryAccess(tx, borrowerID, libraryID, "viewer"); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var book models.Book
+	err := tx.
+		Joins("JOIN book_library ON book_library.book_id = books.id AND book_library.library_id = ?", libraryID).
+		First(&book, "books.id = ?", loan.BookID).Error
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("book not found")
+		}
+
+		return err
+	}
+
+	if book.OrganizationID == loan.OrganizationID {
+		tx.Rollback()
+		return errors.New("cannot borrow a book of your own organization")
+	}
+
+	if err := ensureBookAvailable(tx, loan.BookID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := ensureNotOnHold(tx, loan.BookID, loan.BorrowerID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var count int64
+	if err := tx.Model(&models.Loan{}).
+		Where("book_id = ? AND borrower_id = ? AND status = ?", loan.BookID, borrowerID, models.LoanStatusRequested).
+		Count(&count).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if count > 0 {
+		tx.Rollback()
+		return errors.New("book already requested")
+	}
+
+	loan.OrganizationID = book.OrganizationID
+	loan.UserID = book.UserID
+
+	// The unique index on the pending requests catches a request made at the same time
+	if err := tx.Omit("BorrowerContact").Create(loan).Error; err != nil {
+		tx.Rollback()
+		if strings.Contains(err.Error(), models.LoanRequestIndex) {
+			return errors.New("book already requested")
+		}
+
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// GetAllLoans retrieves the loans of an organization together with the books the user borrowed from others.
 //
 // Parameters:
-// - userID: the ID of the user whose loans are being retrieved.
+// - userID: the ID of the user, whose borrowed books are included.
+// - orgID: the ID of the organization whose loans are being retrieved.
 //
 // Returns:
-// - *[]models.Loan: a pointer to a slice of models.Loan representing the loans for the user, or nil if there are no loans.
+// - *[]models.Loan: a pointer to a slice of models.Loan representing the loans, or nil if there are no loans.
 // - error: an error if there was a problem retrieving the loans.
-func (r *loanRepositoryImp) GetAllLoans(userID string) (*[]models.Loan, error) {
+func (r *loanRepositoryImp) GetAllLoans(userID, orgID string) (*[]models.Loan, error) {
 	var loans []models.Loan
 
-	if err := r.db.Where("user_id = ?", userID).Find(&loans).Error; err != nil {
+	if err := r.db.Preload("BorrowerContact").Where("organization_id = ? OR borrower_id = ?", orgID, userID).Order("created_at DESC").Find(&loans).Error; err != nil {
 		return nil, err
 	}
 
 	return &loans, nil
 }
 
-// ReturnLoan updates the "is_returned" field of a loan record in the database.
+// ApproveLoan approves a borrow request, which lends the first copy of the book that is not lent
+// from the given date and fulfills the ready hold of the borrower.
+//
+// Parameters:
+// - orgID: the ID of the organization owning the book.
+// - loanID: the ID of the requested loan.
+// - loanDate: the date the loan starts.
+//
+// Returns:
+// - error: an error if the loan was not found, is not requested, or the book is already borrowed or on hold.
+func (r *loanRepositoryImp) ApproveLoan(orgID, loanID, loanDate string) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	loan, err := getOrganizationLoan(tx, orgID, loanID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if loan.Status != models.LoanStatusRequested {
+		tx.Rollback()
+		return errors.New("loan is not requested")
+	}
+
+	bookCopy, err := availableCopy(tx, loan.BookID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := ensureNotOnHold(tx, loan.BookID, loan.BorrowerID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Model(&models.Hold{}).
+		Where("book_id = ? AND user_id = ? AND status = ?", loan.BookID, loan.BorrowerID, models.HoldStatusReady).
+		Update("status", models.HoldStatusFulfilled).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Model(loan).Updates(map[string]interface{}{
+		"status":    models.LoanStatusActive,
+		"loan_date": loanDate,
+		"copy_id":   bookCopy.ID,
+	}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// The other requests for the book cannot be served anymore
+	if err := tx.Model(&models.Loan{}).
+		Where("book_id = ? AND status = ? AND id <> ?", loan.BookID, models.LoanStatusRequested, loan.ID).
+		Update("status", models.LoanStatusDeclined).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// DeclineLoan declines a borrow request.
 //
 // Parameters:
-// - userID: the ID of the user who is returning the loan.
+// - orgID: the ID of the organization owning the book.
+// - loanID: the ID of the requested loan.
+//
+// Returns:
+// - error: an error if the loan was not found or is not requested.
+func (r *loanRepositoryImp) DeclineLoan(orgID, loanID string) error {
+	loan, err := getOrganizationLoan(r.db, orgID, loanID)
+	if err != nil {
+		return err
+	}
+
+	if loan.Status != models.LoanStatusRequested {
+		return errors.New("loan is not requested")
+	}
+
+	return r.db.Model(loan).Update("status", models.LoanStatusDeclined).Error
+}
+
+// ReturnLoan marks a loan as returned.
+//
+// When the loan belongs to the organization the loan is returned right away, which also confirms a return
+// marked by the borrower, and the first hold waiting for the book becomes ready. When the user is the
+// borrower the return is pending until the lender confirms it.
+//
+// Parameters:
+// - userID: the ID of the user returning the loan.
+// - orgID: the ID of the active organization of the user.
 // - loanID: the ID of the loan being returned.
 //
 // Returns:
-// - error: an error if there was a problem updating the loan record.
-func (r *loanRepositoryImp) ReturnLoan(userID, loanID string) error {
-	if err := r.db.Model(&models.Loan{}).Where("id = ? AND user_id = ?", loanID, userID).Update("is_returned", true).Error; err != nil {
+// - *models.Hold: the hold that became ready, so its user can be notified, or nil.
+// - error: an error if the loan was not found or is not active.
+func (r *loanRepositoryImp) ReturnLoan(userID, orgID, loanID string) (*models.Hold, error) {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	var loan models.Loan
+	if err := tx.Where("id = ? AND (organization_id = ? OR borrower_id = ?)", loanID, orgID, userID).First(&loan).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("loan not found")
+		}
+
+		return nil, err
+	}
+
+	if loan.OrganizationID.String() != orgID {
+		if loan.Status != models.LoanStatusActive {
+			tx.Rollback()
+			return nil, errors.New("loan is not active")
+		}
+
+		if err := tx.Model(&loan).Updates(map[string]interface{}{
+			"status":      models.LoanStatusReturnPending,
+			"returned_at": time.Now(),
+		}).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		return nil, tx.Commit().Error
+	}
+
+	if loan.Status != models.LoanStatusActive && loan.Status != models.LoanStatusReturnPending {
+		tx.Rollback()
+		return nil, errors.New("loan is not active")
+	}
+
+	// A return marked by the borrower keeps the moment the borrower gave the book back
+	if err := tx.Model(&loan).Updates(map[string]interface{}{
+		"status":      models.LoanStatusReturned,
+		"is_returned": true,
+		"returned_at": gorm.Expr("COALESCE(returned_at, ?)", time.Now()),
+	}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	next, err := advanceHolds(tx, loan.BookID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return next, tx.Commit().Error
+}
+
+// getOrganizationLoan retrieves a loan of an organization.
+func getOrganizationLoan(db *gorm.DB, orgID, loanID string) (*models.Loan, error) {
+	var loan models.Loan
+
+	if err := db.Where("id = ? AND organization_id = ?", loanID, orgID).First(&loan).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("loan not found")
+		}
+
+		return nil, err
+	}
+
+	return &loan, nil
+}
+
+// ensureBookAvailable returns "book has no copies" when the book has no copy to lend, and "book already
+// borrowed" when every copy of the book is lent.
+func ensureBookAvailable(db *gorm.DB, bookID string) error {
+	if err := ensureBookHasCopies(db, bookID); err != nil {
+		return err
+	}
+
+	borrowed, err := bookBorrowed(db, bookID)
+	if err != nil {
+		return err
+	}
+
+	if borrowed {
+		return errors.New("book already borrowed")
+	}
+
+	return nil
+}
+
+// bookBorrowed reports whether every copy of the book is lent.
+func bookBorrowed(db *gorm.DB, bookID string) (bool, error) {
+	var count int64
+
+	if err := availableCopies(db, bookID).Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count == 0, nil
+}
+
+// availableCopy returns the first copy of the book that is not lent, "book has no copies" when the book
+// has no copy to lend or "book already borrowed" when every copy is lent.
+func availableCopy(db *gorm.DB, bookID string) (*models.Copy, error) {
+	if err := ensureBookHasCopies(db, bookID); err != nil {
+		return nil, err
+	}
+
+	var bookCopy models.Copy
+
+	if err := availableCopies(db, bookID).Order("created_at ASC").First(&bookCopy).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("book already borrowed")
+		}
+
+		return nil, err
+	}
+
+	return &bookCopy, nil
+}
+
+// ensureBookHasCopies returns "book has no copies" when the book has no copy at all, so that it is not taken
+// for a book whose copies are all lent.
+func ensureBookHasCopies(db *gorm.DB, bookID string) error {
+	var count int64
+
+	if err := db.Model(&models.Copy{}).Where("book_id = ?", bookID).Count(&count).Error; err != nil {
 		return err
 	}
 
+	if count == 0 {
+		return errors.New("book has no copies")
+	}
+
 	return nil
 }
+
+// availableCopies builds the query of the copies of the book that are not lent.
+func availableCopies(db *gorm.DB, bookID string) *gorm.DB {
+	return db.Model(&models.Copy{}).
+		Where("book_id = ? AND NOT EXISTS (SELECT 1 FROM loans WHERE loans.copy_id = copies.id AND loans.status IN ?)",
+			bookID, []string{models.LoanStatusActive, models.LoanStatusReturnPending})
+}
@@ -0,0 +1,505 @@
+package repositories
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mybooks/internal/domain/models"
+	"mybooks/pkg"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// noteSearchVectorSQL is the full-text search document of a note. The search index is built on the
+// same expression, so both must be kept in sync.
+const noteSearchVectorSQL = "to_tsvector('simple', notes.text || ' ' || notes.comment)"
+
+var (
+	clippingTitleAside = regexp.MustCompile(`\([^)]*\)|\[[^\]]*\]`)
+	clippingTitleWord  = regexp.MustCompile(`[\p{L}\p{N}]+`)
+)
+
+type NoteRepository interface {
+	CreateNote(orgID string, note *models.Note) error
+	GetBookNotes(userID, orgID, bookID string) (*models.Book, *[]models.Note, error)
+	SearchNotes(userID, orgID string, filters map[string]interface{}) (*[]models.Note, error)
+	UpdateNote(userID, orgID string, note *models.Note) error
+	DeleteNote(userID, orgID, id string) error
+	ImportNotes(userID, orgID uuid.UUID, source string, clippings []pkg.Clipping) (*models.NoteImportResult, error)
+}
+
+type noteRepositoryImp struct {
+	db *gorm.DB
+}
+
+// NewNoteRepository creates a new instance of the NoteRepository interface.
+//
+// It takes a *gorm.DB parameter, which represents the database connection.
+// It returns a NoteRepository pointer, which is an implementation of the NoteRepository interface.
+func NewNoteRepository(db *gorm.DB) NoteRepository {
+	return &noteRepositoryImp{
+		db: db,
+	}
+}
+
+// CreateNote creates a note on a book of an organization and tags it, creating the tags the
+// organization does not have yet.
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+// - note: a pointer to the note.
+//
+// Returns:
+// - error: an error object if the book was not found or there was an issue creating the note.
+func (r *noteRepositoryImp) CreateNote(orgID string, note *models.Note) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	var book models.Book
+	if err := tx.First(&book, "id = ? AND organization_id = ?", note.BookID, orgID).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("book not found")
+		}
+		return err
+	}
+
+	if err := tx.Omit("Tags", "User").Create(note).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := setNoteTags(tx, book.OrganizationID, note); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// GetBookNotes retrieves a book of an organization and the notes of a user on it, in reading order.
+//
+// Parameters:
+// - userID: a string representing the user ID.
+// - orgID: a string representing the organization ID.
+// - bookID: a string representing the book ID.
+//
+// Returns:
+// - *models.Book: a pointer to the book.
+// - *[]models.Note: a pointer to a slice of models.Note representing the notes.
+// - error: an error object if the book was not found or there was an issue retrieving the notes.
+func (r *noteRepositoryImp) GetBookNotes(userID, orgID, bookID string) (*models.Book, *[]models.Note, error) {
+	var book models.Book
+	if err := r.db.First(&book, "id = ? AND organization_id = ?", bookID, orgID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, fmt.Errorf("book not found")
+		}
+		return nil, nil, err
+	}
+
+	var notes []models.Note
+	err := r.db.Preload("Tags").
+		Where("book_id = ? AND user_id = ?", book.ID, userID).
+		Order("page ASC, location ASC, created_at ASC").
+		Find(&notes).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &book, &notes, nil
+}
+
+// SearchNotes retrieves the notes of a user on the books of an organization that match the provided filters.
+//
+// The "q" filter is a full-text search on the text and comment of the notes, in which case the best
+// matches come first. Otherwise the notes are ordered by creation date in descending order. The "tag"
+// filter matches a normalized tag name and the "kind" filter the kind of the notes.
+//
+// Parameters:
+// - userID: a string representing the user ID.
+// - orgID: a string representing the organization ID.
+// - filters: a map of key-value pairs representing the filters to be applied.
+//
+// Returns:
+// - *[]models.Note: a pointer to a slice of models.Note representing the notes.
+// - error: an error object if there was an issue retrieving the notes.
+func (r *noteRepositoryImp) SearchNotes(userID, orgID string, filters map[string]interface{}) (*[]models.Note, error) {
+	var notes []models.Note
+
+	query := r.db.Model(&models.Note{}).
+		Select("notes.*, books.title AS book_title").
+		Joins("JOIN books ON books.id = notes.book_id").
+		Where("notes.user_id = ? AND books.organization_id = ?", userID, orgID).
+		Preload("Tags")
+
+	if kind, ok := filters["kind"]; ok {
+		query = query.Where("notes.kind = ?", kind)
+	}
+
+	if tag, ok := filters["tag"]; ok {
+		query = query.Where(`EXISTS (SELECT 1 FROM note_tag JOIN tags ON tags.id = note_tag.tag_id
+			WHERE note_tag.note_id = notes.id AND tags.normalized_name = ?)`, tag)
+	}
+
+	if search, ok := filters["q"]; ok {
+		query = query.
+			Where(noteSearchVectorSQL+" @@ websearch_to_tsquery('simple', ?)", search).
+			Clauses(clause.OrderBy{Expression: gorm.Expr("ts_rank("+noteSearchVectorSQL+", websearch_to_tsquery('simple', ?)) DESC, notes.created_at DESC", search)})
+	} else {
+		query = query.Order("notes.created_at DESC")
+	}
+
+	if err := query.Find(&notes).Error; err != nil {
+		return nil, err
+	}
+
+	return &notes, nil
+}
+
+// UpdateNote updates a note of a user on a book of an organization. When the note has tags they replace
+// the previous tags, and an empty list removes them all.
+//
+// Parameters:
+// - userID: a string representing the user ID.
+// - orgID: a string representing the organization ID.
+// - note: a pointer to the note with the new details.
+//
+// Returns:
+// - error: an error object if the note was not found or there was an issue updating it.
+func (r *noteRepositoryImp) UpdateNote(userID, orgID string, note *models.Note) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	var book models.Book
+	err := tx.Select("books.*").Joins("JOIN notes ON notes.book_id = books.id").
+		First(&book, "notes.id = ? AND notes.user_id = ? AND books.organization_id = ?", note.ID, userID, orgID).Error
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("note not found")
+		}
+		return err
+	}
+
+	err = tx.Model(&models.Note{}).
+		Where("id = ?", note.ID).
+		Select("kind", "text", "comment", "page", "location").
+		Updates(models.Note{Kind: note.Kind, Text: note.Text, Comment: note.Comment, Page: note.Page, Location: note.Location}).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if note.Tags != nil {
+		if err := tx.Exec("DELETE FROM note_tag WHERE note_id = ?", note.ID).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if err := setNoteTags(tx, book.OrganizationID, note); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+// DeleteNote deletes a note of a user on a book of an organization.
+//
+// Parameters:
+// - userID: a string representing the user ID.
+// - orgID: a string representing the organization ID.
+// - id: a string representing the note ID.
+//
+// Returns:
+// - error: an error object if the note was not found or there was an issue deleting it.
+func (r *noteRepositoryImp) DeleteNote(userID, orgID, id string) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	// Delete the relation in the note_tag table
+	if err := tx.Exec(`DELETE FROM note_tag WHERE note_id IN (SELECT id FROM notes WHERE id = ? AND user_id = ?
+		AND book_id IN (SELECT id FROM books WHERE organization_id = ?))`, id, userID, orgID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	result := tx.
+		Where("id = ? AND user_id = ? AND book_id IN (SELECT id FROM books WHERE organization_id = ?)", id, userID, orgID).
+		Delete(&models.Note{})
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return fmt.Errorf("note not found")
+	}
+
+	return tx.Commit().Error
+}
+
+// ImportNotes imports the highlights and notes of an e-reader as notes of a user on the books of an organization.
+//
+// Each clipping is matched to a book of the organization with the same title, ignoring case, subtitles and
+// parenthesized parts such as series names, and sharing an author with it when both have one. A placeholder
+// book is created for the clippings that match no book. The clippings already imported from the same source,
+// and the duplicates within the clippings, are recognized by their fingerprint and left out, as are bookmarks
+// and clippings without text.
+//
+// Parameters:
+// - userID: the ID of the user importing the clippings.
+// - orgID: the ID of the organization.
+// - source: the name of the e-reader, "kindle" or "kobo".
+// - clippings: the clippings to import.
+//
+// Returns:
+// - *models.NoteImportResult: the counts of imported, duplicated and skipped clippings and of created books.
+// - error: an error object if there was an issue importing the clippings, in which case nothing is imported.
+func (r *noteRepositoryImp) ImportNotes(userID, orgID uuid.UUID, source string, clippings []pkg.Clipping) (*models.NoteImportResult, error) {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	var books []models.Book
+	if err := tx.Select("id", "title", "author").Where("organization_id = ?", orgID).Find(&books).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var fingerprints []string
+	err := tx.Model(&models.Note{}).
+		Where("user_id = ? AND source = ? AND book_id IN (SELECT id FROM books WHERE organization_id = ?)", userID, source, orgID).
+		Pluck("fingerprint", &fingerprints).Error
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for _, fingerprint := range fingerprints {
+		seen[fingerprint] = true
+	}
+
+	result := &models.NoteImportResult{}
+	for _, clipping := range clippings {
+		clipping.Text = truncateRunes(strings.TrimSpace(clipping.Text), 10000)
+		clipping.Comment = truncateRunes(strings.TrimSpace(clipping.Comment), 10000)
+		if clipping.Kind == "bookmark" || clipping.Text == "" {
+			result.Skipped++
+			continue
+		}
+
+		fingerprint := clippingFingerprint(source, clipping)
+		if seen[fingerprint] {
+			result.Duplicates++
+			continue
+		}
+		seen[fingerprint] = true
+
+		book := matchClippingBook(books, clipping)
+		if book == nil {
+			placeholder, err := createClippingBook(tx, userID, orgID, clipping)
+			if err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+
+			books = append(books, *placeholder)
+			book = placeholder
+			result.BooksCreated++
+		}
+
+		id, err := pkg.GenerateRandomID()
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		note := models.Note{
+			ID:          id,
+			BookID:      book.ID,
+			Kind:        clipping.Kind,
+			Text:        clipping.Text,
+			Comment:     clipping.Comment,
+			Page:        clipping.Page,
+			Location:    truncateRunes(clipping.Location, 50),
+			Source:      source,
+			Fingerprint: fingerprint,
+			UserID:      userID,
+			CreatedAt:   clipping.AddedAt,
+		}
+		if err := tx.Omit("Tags", "User").Create(&note).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		result.Imported++
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// setNoteTags tags a note with the tags of the note, matched by name within the organization and created
+// when the organization does not have them yet. The tags of the note are filled with the stored tags.
+func setNoteTags(tx *gorm.DB, orgID uuid.UUID, note *models.Note) error {
+	for i := range note.Tags {
+		tag := &note.Tags[i]
+
+		id, err := pkg.GenerateRandomID()
+		if err != nil {
+			return err
+		}
+
+		tag.ID = id
+		tag.OrganizationID = orgID
+		tag.UserID = note.UserID
+
+		if err := findOrCreateTag(tx, tag); err != nil {
+			return err
+		}
+
+		if err := tx.Exec("INSERT INTO note_tag (note_id, tag_id) VALUES (?, ?) ON CONFLICT DO NOTHING", note.ID, tag.ID).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// matchClippingBook finds the book a clipping was taken from among the books of an organization. The titles
+// must match and, when both the clipping and the book have an author, they must share an author's last name.
+func matchClippingBook(books []models.Book, clipping pkg.Clipping) *models.Book {
+	title := clippingTitleKey(clipping.Title)
+	authors := clippingAuthorKeys(clipping.Author)
+
+	for i := range books {
+		if clippingTitleKey(books[i].Title) != title {
+			continue
+		}
+
+		bookAuthors := clippingAuthorKeys(books[i].Author)
+		if len(authors) == 0 || len(bookAuthors) == 0 {
+			return &books[i]
+		}
+		for author := range authors {
+			if bookAuthors[author] {
+				return &books[i]
+			}
+		}
+	}
+
+	return nil
+}
+
+// createClippingBook creates a placeholder book, with a copy, for the clippings of a book the organization does
+// not have yet. The book only has the title and author found in the clipping.
+func createClippingBook(tx *gorm.DB, userID, orgID uuid.UUID, clipping pkg.Clipping) (*models.Book, error) {
+	bookID, err := pkg.GenerateRandomID()
+	if err != nil {
+		return nil, err
+	}
+
+	copyID, err := pkg.GenerateRandomID()
+	if err != nil {
+		return nil, err
+	}
+
+	title := truncateRunes(strings.TrimSpace(clipping.Title), 100)
+	if title == "" {
+		title = "Untitled"
+	}
+	author := truncateRunes(strings.TrimSpace(clipping.Author), 100)
+	if author == "" {
+		author = "Unknown"
+	}
+
+	book := &models.Book{
+		ID:             bookID,
+		Title:          title,
+		Author:         author,
+		OrganizationID: orgID,
+		UserID:         userID,
+	}
+	if err := createBook(tx, book, &models.Copy{ID: copyID}); err != nil {
+		return nil, err
+	}
+
+	return book, nil
+}
+
+// clippingTitleKey normalizes a book title for matching: parenthesized and bracketed parts and the subtitle
+// are removed, and only the lowercase words are kept.
+func clippingTitleKey(title string) string {
+	title = clippingTitleAside.ReplaceAllString(title, " ")
+	if main, _, found := strings.Cut(title, ":"); found && strings.TrimSpace(main) != "" {
+		title = main
+	}
+
+	return strings.Join(clippingTitleWord.FindAllString(strings.ToLower(title), -1), " ")
+}
+
+// clippingAuthorKeys returns the lowercase last names of the authors of an author string. "Unknown" authors
+// are left out.
+func clippingAuthorKeys(author string) map[string]bool {
+	keys := make(map[string]bool)
+	for _, name := range pkg.SplitAuthorNames(author) {
+		words := strings.Fields(strings.ToLower(name))
+		if last := words[len(words)-1]; last != "unknown" {
+			keys[last] = true
+		}
+	}
+
+	return keys
+}
+
+// clippingFingerprint identifies a clipping of a source: by its ID on the e-reader when it has one, otherwise
+// by its book, kind, location and text.
+func clippingFingerprint(source string, clipping pkg.Clipping) string {
+	value := strings.Join([]string{source, clipping.SourceID}, "\x00")
+	if clipping.SourceID == "" {
+		value = strings.Join([]string{source, clipping.Title, clipping.Author, clipping.Kind, clipping.Location, clipping.Text}, "\x00")
+	}
+
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// truncateRunes shortens a text to at most the given number of characters.
+func truncateRunes(text string, length int) string {
+	if runes := []rune(text); len(runes) > length {
+		return strings.TrimSpace(string(runes[:length]))
+	}
+
+	return text
+}
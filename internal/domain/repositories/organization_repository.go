@@ -0,0 +1,498 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"mybooks/internal/domain/models"
+
+	"gorm.io/gorm"
+)
+
+type OrganizationRepository interface {
+	GetActiveOrganization(userID, organizationID string) (*models.Organization, error)
+	CreateOrganization(organization *models.Organization) error
+	GetAllOrganizations(userID string) (*[]models.Organization, error)
+	UpdateOrganization(userID string, organization *models.Organization) error
+	DeleteOrganization(userID, id string) error
+	GetOrganizationMembers(userID, id string) (*[]models.OrganizationMember, error)
+	AddOrganizationMember(userID, id, email, role string) error
+	UpdateOrganizationMember(userID, id, memberID, role string) error
+	RemoveOrganizationMember(userID, id, memberID string) error
+}
+
+type organizationRepositoryImp struct {
+	db *gorm.DB
+}
+
+// NewOrganizationRepository creates a new instance of the OrganizationRepository interface.
+//
+// It takes a *gorm.DB parameter, which represents the database connection.
+// It returns an OrganizationRepository pointer, which is an implementation of the OrganizationRepository interface.
+func NewOrganizationRepository(db *gorm.DB) OrganizationRepository {
+	return &organizationRepositoryImp{
+		db: db,
+	}
+}
+
+// GetActiveOrganization retrieves the organization a request acts on, with the role of the user in it.
+//
+// An empty organizationID selects the personal organization of the user.
+//
+// Parameters:
+// - userID: a string representing the ID of the user.
+// - organizationID: a string representing the ID of the selected organization, or an empty string.
+//
+// Returns:
+// - *models.Organization: a pointer to the active organization.
+// - error: an error object if the user is not a member of the organization.
+func (r *organizationRepositoryImp) GetActiveOrganization(userID, organizationID string) (*models.Organization, error) {
+	if organizationID != "" {
+		return organizationAccess(r.db, userID, organizationID, "member")
+	}
+
+	var organization models.Organization
+
+	err := r.db.
+		Select("organizations.*, organization_members.role AS role").
+		Joins("JOIN organization_members ON organization_members.organization_id = organizations.id AND organization_members.user_id = ?", userID).
+		First(&organization, "organizations.personal = ? AND organizations.user_id = ?", true, userID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("organization not found")
+		}
+		return nil, err
+	}
+
+	return &organization, nil
+}
+
+// CreateOrganization creates a new organization and makes its creator the owner.
+//
+// It takes a pointer to an Organization struct as a parameter and returns an error.
+func (r *organizationRepositoryImp) CreateOrganization(organization *models.Organization) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	if err := createOrganization(tx, organization); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// GetAllOrganizations retrieves the organizations the user is a member of, personal organization first.
+//
+// Parameters:
+// - userID: a string representing the user ID.
+//
+// Returns:
+// - *[]models.Organization: a pointer to a slice of models.Organization objects with the role of the user.
+// - error: an error object if there was an issue retrieving the organizations.
+func (r *organizationRepositoryImp) GetAllOrganizations(userID string) (*[]models.Organization, error) {
+	var organizations []models.Organization
+
+	err := r.db.
+		Select("organizations.*, organization_members.role AS role").
+		Joins("JOIN organization_members ON organization_members.organization_id = organizations.id").
+		Where("organization_members.user_id = ?", userID).
+		Order("organizations.personal DESC, organizations.name ASC").
+		Find(&organizations).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &organizations, nil
+}
+
+// UpdateOrganization renames an organization.
+//
+// Parameters:
+// - userID: a string representing the ID of the user, who must be at least an admin of the organization.
+// - organization: a pointer to a models.Organization object representing the organization to be updated.
+//
+// Returns:
+// - error: an error object if there was an issue updating the organization.
+func (r *organizationRepositoryImp) UpdateOrganization(userID string, organization *models.Organization) error {
+	if _, err := organizationAccess(r.db, userID, organization.ID.String(), "admin"); err != nil {
+		return err
+	}
+
+	return r.db.Model(&models.Organization{}).Where("id = ?", organization.ID).Update("name", organization.Name).Error
+}
+
+// DeleteOrganization deletes an organization and its members.
+//
+// Only an owner can delete an organization. Personal organizations cannot be deleted, and an
+// organization that still has books, libraries, loans or wishlist items must be emptied first.
+// Everything else it holds, from its contacts and exchange rates to its reading goals and shared
+// links, is deleted with it.
+//
+// Parameters:
+// - userID: a string representing the ID of the user.
+// - id: a string representing the ID of the organization to delete.
+//
+// Returns:
+// - error: an error object if there was an issue deleting the organization.
+func (r *organizationRepositoryImp) DeleteOrganization(userID, id string) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	organization, err := organizationAccess(tx, userID, id, "owner")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if organization.Personal {
+		tx.Rollback()
+		return errors.New("cannot delete a personal organization")
+	}
+
+	for _, model := range []interface{}{&models.Book{}, &models.Library{}, &models.Loan{}, &models.WishlistItem{}} {
+		var count int64
+		if err := tx.Model(model).Where("organization_id = ?", organization.ID).Count(&count).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if count > 0 {
+			tx.Rollback()
+			return errors.New("organization is not empty, delete or move its books, libraries, loans and wishlist items first")
+		}
+	}
+
+	// Delete the authors, series, tags, works and holds left without books, and the settings and shares of the organization
+	for _, model := range []interface{}{
+		&models.Author{}, &models.Series{}, &models.Tag{}, &models.Work{}, &models.Hold{}, &models.Borrower{},
+		&models.ExchangeRate{}, &models.WishlistShare{}, &models.ReadingGoal{}, &models.YearReviewShare{},
+	} {
+		if err := tx.Where("organization_id = ?", organization.ID).Delete(model).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Where("organization_id = ?", organization.ID).Delete(&models.OrganizationMember{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Where("id = ?", organization.ID).Delete(&models.Organization{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// GetOrganizationMembers retrieves the members of an organization with their users, owners first.
+//
+// Parameters:
+// - userID: a string representing the ID of the user, who must be a member of the organization.
+// - id: a string representing the ID of the organization.
+//
+// Returns:
+// - *[]models.OrganizationMember: a pointer to a slice of models.OrganizationMember objects.
+// - error: an error object if there was an issue retrieving the members.
+func (r *organizationRepositoryImp) GetOrganizationMembers(userID, id string) (*[]models.OrganizationMember, error) {
+	var members []models.OrganizationMember
+
+	organization, err := organizationAccess(r.db, userID, id, "member")
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.
+		Preload("User").
+		Where("organization_id = ?", organization.ID).
+		Order("CASE role WHEN 'owner' THEN 0 WHEN 'admin' THEN 1 ELSE 2 END, created_at ASC").
+		Find(&members).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &members, nil
+}
+
+// AddOrganizationMember adds a registered user to an organization by email.
+//
+// Admins can add members and admins, and only owners can add owners.
+// Personal organizations cannot have other members.
+//
+// Parameters:
+// - userID: a string representing the ID of the user adding the member.
+// - id: a string representing the ID of the organization.
+// - email: a string representing the email of the user to add.
+// - role: the role of the new member.
+//
+// Returns:
+// - error: an error object if there was an issue adding the member.
+func (r *organizationRepositoryImp) AddOrganizationMember(userID, id, email, role string) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	organization, err := organizationAccess(tx, userID, id, "admin")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if organization.Personal {
+		tx.Rollback()
+		return errors.New("cannot add members to a personal organization")
+	}
+
+	if err := ensureCanGrantRole(organization, role); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var user models.User
+	if err := tx.Where("LOWER(email) = LOWER(?)", email).First(&user).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("user not found")
+		}
+		return err
+	}
+
+	var members int64
+	if err := tx.Model(&models.OrganizationMember{}).Where("organization_id = ? AND user_id = ?", organization.ID, user.ID).Count(&members).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if members > 0 {
+		tx.Rollback()
+		return fmt.Errorf("%s is already a member of the organization", email)
+	}
+
+	member := models.OrganizationMember{
+		OrganizationID: organization.ID,
+		UserID:         user.ID,
+		Role:           role,
+	}
+
+	if err := tx.Omit("User", "Organization").Create(&member).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// UpdateOrganizationMember changes the role of a member of an organization.
+//
+// Admins can change the roles of members and admins, and only owners can promote or demote owners.
+// The last owner of an organization cannot be demoted.
+//
+// Parameters:
+// - userID: a string representing the ID of the user changing the role.
+// - id: a string representing the ID of the organization.
+// - memberID: a string representing the user ID of the member.
+// - role: the new role of the member.
+//
+// Returns:
+// - error: an error object if there was an issue updating the member.
+func (r *organizationRepositoryImp) UpdateOrganizationMember(userID, id, memberID, role string) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	organization, err := organizationAccess(tx, userID, id, "admin")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	member, err := getOrganizationMember(tx, organization.ID.String(), memberID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := ensureCanGrantRole(organization, member.Role); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := ensureCanGrantRole(organization, role); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if member.Role == "owner" && role != "owner" {
+		if err := ensureAnotherOrganizationOwner(tx, organization.ID.String(), memberID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Model(&models.OrganizationMember{}).Where("organization_id = ? AND user_id = ?", organization.ID, memberID).Update("role", role).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// RemoveOrganizationMember removes a member from an organization.
+//
+// Admins can remove members and admins, only owners can remove owners, and every member can
+// remove themselves to leave the organization. The last owner of an organization cannot leave it.
+//
+// Parameters:
+// - userID: a string representing the ID of the user removing the member.
+// - id: a string representing the ID of the organization.
+// - memberID: a string representing the user ID of the member.
+//
+// Returns:
+// - error: an error object if there was an issue removing the member.
+func (r *organizationRepositoryImp) RemoveOrganizationMember(userID, id, memberID string) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	role := "admin"
+	if userID == memberID {
+		role = "member"
+	}
+
+	organization, err := organizationAccess(tx, userID, id, role)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	member, err := getOrganizationMember(tx, organization.ID.String(), memberID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if userID != memberID {
+		if err := ensureCanGrantRole(organization, member.Role); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if member.Role == "owner" {
+		if err := ensureAnotherOrganizationOwner(tx, organization.ID.String(), memberID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Where("organization_id = ? AND user_id = ?", organization.ID, memberID).Delete(&models.OrganizationMember{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// createOrganization creates an organization and makes the user who created it its owner.
+func createOrganization(tx *gorm.DB, organization *models.Organization) error {
+	if err := tx.Omit("User").Create(organization).Error; err != nil {
+		return err
+	}
+
+	member := models.OrganizationMember{
+		OrganizationID: organization.ID,
+		UserID:         organization.UserID,
+		Role:           "owner",
+	}
+
+	return tx.Omit("User", "Organization").Create(&member).Error
+}
+
+// organizationAccess retrieves an organization the user is a member of, with the role of the user in it.
+//
+// It returns "organization not found" when the user is not a member of the organization and
+// "insufficient organization permissions" when the role of the user is lower than the required role.
+func organizationAccess(db *gorm.DB, userID, organizationID, role string) (*models.Organization, error) {
+	var organization models.Organization
+
+	err := db.
+		Select("organizations.*, organization_members.role AS role").
+		Joins("JOIN organization_members ON organization_members.organization_id = organizations.id AND organization_members.user_id = ?", userID).
+		First(&organization, "organizations.id = ?", organizationID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("organization not found")
+		}
+		return nil, err
+	}
+
+	if models.OrganizationRoles[organization.Role] < models.OrganizationRoles[role] {
+		return nil, errors.New("insufficient organization permissions")
+	}
+
+	return &organization, nil
+}
+
+// ensureCanGrantRole returns an error when an admin tries to grant, change or remove the owner role.
+func ensureCanGrantRole(organization *models.Organization, role string) error {
+	if role == "owner" && organization.Role != "owner" {
+		return errors.New("insufficient organization permissions")
+	}
+
+	return nil
+}
+
+// getOrganizationMember retrieves a member of an organization.
+func getOrganizationMember(tx *gorm.DB, organizationID, memberID string) (*models.OrganizationMember, error) {
+	var member models.OrganizationMember
+
+	if err := tx.First(&member, "organization_id = ? AND user_id = ?", organizationID, memberID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("member not found")
+		}
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+// ensureAnotherOrganizationOwner returns an error when the member is the only owner of the organization.
+func ensureAnotherOrganizationOwner(tx *gorm.DB, organizationID, memberID string) error {
+	var owners int64
+
+	err := tx.Model(&models.OrganizationMember{}).
+		Where("organization_id = ? AND user_id <> ? AND role = ?", organizationID, memberID, "owner").
+		Count(&owners).Error
+	if err != nil {
+		return err
+	}
+
+	if owners == 0 {
+		return errors.New("an organization must keep at least one owner")
+	}
+
+	return nil
+}
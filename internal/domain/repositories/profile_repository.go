@@ -0,0 +1,120 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"mybooks/internal/domain/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ProfileRepository interface {
+	UpdateTimezone(userID uuid.UUID, timezone string) error
+	UpdatePublicProfile(user *models.User) error
+	GetPublicProfile(handle string) (*models.User, *[]models.Library, error)
+}
+
+type profileRepositoryImp struct {
+	db *gorm.DB
+}
+
+// NewProfileRepository creates a new instance of the ProfileRepository interface.
+//
+// It takes a *gorm.DB parameter, which represents the database connection.
+// It returns a ProfileRepository pointer, which is an implementation of the ProfileRepository interface.
+func NewProfileRepository(db *gorm.DB) ProfileRepository {
+	return &profileRepositoryImp{
+		db: db,
+	}
+}
+
+// UpdateTimezone updates the timezone of a user.
+//
+// Parameters:
+// - userID: a UUID representing the ID of the user.
+// - timezone: the name of the timezone, such as "Europe/Paris".
+//
+// Returns:
+// - error: an error object if there was an issue updating the timezone.
+func (r *profileRepositoryImp) UpdateTimezone(userID uuid.UUID, timezone string) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Update("timezone", timezone).Error
+}
+
+// UpdatePublicProfile updates the handle, display name, bio and visibility of the profile of a user.
+//
+// The handle is unique among all users, deleted ones included, and a user without a handle has a nil handle.
+//
+// Parameters:
+// - user: a pointer to the user, with the new values of the profile.
+//
+// Returns:
+// - error: an error object if the handle is already taken or there was an issue updating the profile.
+func (r *profileRepositoryImp) UpdatePublicProfile(user *models.User) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	if user.Handle != nil {
+		var count int64
+		err := tx.Model(&models.User{}).Unscoped().
+			Where("handle = ? AND id <> ?", *user.Handle, user.ID).
+			Count(&count).Error
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if count > 0 {
+			tx.Rollback()
+			return fmt.Errorf("handle %s is already taken", *user.Handle)
+		}
+	}
+
+	err := tx.Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+		"handle":       user.Handle,
+		"display_name": user.DisplayName,
+		"bio":          user.Bio,
+		"public":       user.Public,
+	}).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// GetPublicProfile retrieves a public profile by its handle, with the public libraries of the user ordered by name.
+//
+// Parameters:
+// - handle: the handle of the profile.
+//
+// Returns:
+// - *models.User: a pointer to the user owning the profile.
+// - *[]models.Library: a pointer to a slice of models.Library representing the public libraries of the user.
+// - error: an error object if there is no public profile with the handle or there was an issue retrieving it.
+func (r *profileRepositoryImp) GetPublicProfile(handle string) (*models.User, *[]models.Library, error) {
+	var user models.User
+	if err := r.db.First(&user, "handle = ? AND public = true", handle).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, fmt.Errorf("profile not found")
+		}
+		return nil, nil, err
+	}
+
+	var libraries []models.Library
+	err := r.db.
+		Where("user_id = ? AND public = true", user.ID).
+		Order("name ASC").
+		Find(&libraries).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &user, &libraries, nil
+}
@@ -0,0 +1,401 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"mybooks/internal/domain/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// readingSessionPagesSQL is the number of pages read during a reading session, zero when it has no end page.
+const readingSessionPagesSQL = "COALESCE(GREATEST(reading_sessions.end_page - reading_sessions.start_page, 0), 0)"
+
+type ReadingSessionRepository interface {
+	StartReadingSession(orgID string, maxDuration time.Duration, session *models.ReadingSession) error
+	StopReadingSession(userID, orgID, bookID string, endPage *int, maxDuration time.Duration) (*models.ReadingSession, error)
+	GetReadingSessions(userID, orgID string, filters map[string]interface{}) (*[]models.ReadingSession, error)
+	GetBookReadingSessions(userID, orgID, bookID string) (*models.Book, *[]models.ReadingSession, error)
+	GetReadingDays(userID, orgID, timezone string, from, to time.Time) (*[]models.ReadingDay, error)
+	UpdateReadingSession(userID, orgID string, session *models.ReadingSession) error
+	DeleteReadingSession(userID, orgID, id string) error
+	StopForgottenReadingSessions(maxDuration time.Duration) (int64, error)
+}
+
+type readingSessionRepositoryImp struct {
+	db *gorm.DB
+}
+
+// NewReadingSessionRepository creates a new instance of the ReadingSessionRepository interface.
+//
+// It takes a *gorm.DB parameter, which represents the database connection.
+// It returns a ReadingSessionRepository pointer, which is an implementation of the ReadingSessionRepository interface.
+func NewReadingSessionRepository(db *gorm.DB) ReadingSessionRepository {
+	return &readingSessionRepositoryImp{
+		db: db,
+	}
+}
+
+// StartReadingSession starts a reading session of a user on a book of an organization.
+//
+// A session of the user forgotten in progress for longer than the maximum duration is stopped first. When the
+// session has no start page it starts at the end page of the previous session of the user on the book.
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+// - maxDuration: the longest a reading session may last.
+// - session: a pointer to the session.
+//
+// Returns:
+// - error: an error object if the book was not found, the user is already reading or there was an issue
+// starting the session.
+func (r *readingSessionRepositoryImp) StartReadingSession(orgID string, maxDuration time.Duration, session *models.ReadingSession) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	if err := tx.First(&models.Book{}, "id = ? AND organization_id = ?", session.BookID, orgID).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("book not found")
+		}
+		return err
+	}
+
+	if _, err := stopForgottenReadingSessions(tx.Where("user_id = ?", session.UserID), maxDuration); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var count int64
+	if err := tx.Model(&models.ReadingSession{}).Where("user_id = ? AND ended_at IS NULL", session.UserID).Count(&count).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if count > 0 {
+		tx.Rollback()
+		return errors.New("a reading session is already in progress")
+	}
+
+	if session.StartPage == 0 {
+		var previous models.ReadingSession
+		err := tx.Where("book_id = ? AND user_id = ? AND end_page IS NOT NULL", session.BookID, session.UserID).
+			Order("started_at DESC").
+			Limit(1).
+			Find(&previous).Error
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if previous.EndPage != nil {
+			session.StartPage = *previous.EndPage
+		}
+	}
+
+	// The unique index on the sessions in progress catches a session started at the same time
+	if err := tx.Omit("User").Create(session).Error; err != nil {
+		tx.Rollback()
+		if strings.Contains(err.Error(), models.ReadingSessionInProgressIndex) {
+			return errors.New("a reading session is already in progress")
+		}
+
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// StopReadingSession stops the reading session of a user in progress on a book of an organization.
+//
+// A session in progress for longer than the maximum duration was forgotten: it ends at its start time plus
+// the maximum duration and is marked as stopped automatically.
+//
+// Parameters:
+// - userID: a string representing the user ID.
+// - orgID: a string representing the organization ID.
+// - bookID: a string representing the book ID.
+// - endPage: the page the user stopped at, or nil if unknown.
+// - maxDuration: the longest a reading session may last.
+//
+// Returns:
+// - *models.ReadingSession: a pointer to the stopped session.
+// - error: an error object if no session is in progress on the book, the end page is before the start page
+// or there was an issue stopping the session.
+func (r *readingSessionRepositoryImp) StopReadingSession(userID, orgID, bookID string, endPage *int, maxDuration time.Duration) (*models.ReadingSession, error) {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	var session models.ReadingSession
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("book_id IN (SELECT id FROM books WHERE organization_id = ?)", orgID).
+		First(&session, "book_id = ? AND user_id = ? AND ended_at IS NULL", bookID, userID).Error
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("no reading session in progress on this book")
+		}
+		return nil, err
+	}
+
+	if endPage != nil && *endPage < session.StartPage {
+		tx.Rollback()
+		return nil, errors.New("end page must not be before the start page")
+	}
+
+	endedAt := time.Now()
+	if endedAt.Sub(session.StartedAt) > maxDuration {
+		endedAt = session.StartedAt.Add(maxDuration)
+		session.AutoStopped = true
+	}
+
+	session.EndPage = endPage
+	session.EndedAt = &endedAt
+	session.Duration = int64(endedAt.Sub(session.StartedAt).Seconds())
+
+	err = tx.Model(&session).
+		Select("end_page", "ended_at", "duration", "auto_stopped").
+		Updates(&session).Error
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// GetReadingSessions retrieves the reading sessions of a user on the books of an organization, most recent first.
+//
+// The "book_id" filter restricts the sessions to a book, and the "from" and "to" filters to the sessions
+// started from and before the given times.
+//
+// Parameters:
+// - userID: a string representing the user ID.
+// - orgID: a string representing the organization ID.
+// - filters: a map of key-value pairs representing the filters to be applied.
+//
+// Returns:
+// - *[]models.ReadingSession: a pointer to a slice of models.ReadingSession representing the sessions.
+// - error: an error object if there was an issue retrieving the sessions.
+func (r *readingSessionRepositoryImp) GetReadingSessions(userID, orgID string, filters map[string]interface{}) (*[]models.ReadingSession, error) {
+	var sessions []models.ReadingSession
+
+	query := r.db.Scopes(organizationReadingSessions(orgID)).Where("reading_sessions.user_id = ?", userID)
+
+	if bookID, ok := filters["book_id"]; ok {
+		query = query.Where("reading_sessions.book_id = ?", bookID)
+	}
+	if from, ok := filters["from"]; ok {
+		query = query.Where("reading_sessions.started_at >= ?", from)
+	}
+	if to, ok := filters["to"]; ok {
+		query = query.Where("reading_sessions.started_at < ?", to)
+	}
+
+	if err := query.Order("reading_sessions.started_at DESC").Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+
+	return &sessions, nil
+}
+
+// GetBookReadingSessions retrieves a book of an organization and the reading sessions of a user on it,
+// most recent first.
+//
+// Parameters:
+// - userID: a string representing the user ID.
+// - orgID: a string representing the organization ID.
+// - bookID: a string representing the book ID.
+//
+// Returns:
+// - *models.Book: a pointer to the book.
+// - *[]models.ReadingSession: a pointer to a slice of models.ReadingSession representing the sessions.
+// - error: an error object if the book was not found or there was an issue retrieving the sessions.
+func (r *readingSessionRepositoryImp) GetBookReadingSessions(userID, orgID, bookID string) (*models.Book, *[]models.ReadingSession, error) {
+	var book models.Book
+	if err := r.db.First(&book, "id = ? AND organization_id = ?", bookID, orgID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, fmt.Errorf("book not found")
+		}
+		return nil, nil, err
+	}
+
+	var sessions []models.ReadingSession
+	err := r.db.Where("book_id = ? AND user_id = ?", book.ID, userID).
+		Order("started_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &book, &sessions, nil
+}
+
+// GetReadingDays sums up the reading time and the pages read by a user on the books of an organization, per day,
+// for the stopped sessions started from and before the given times. A session counts for the day it started,
+// in the timezone of the user. Days without reading are left out.
+//
+// Parameters:
+// - userID: a string representing the user ID.
+// - orgID: a string representing the organization ID.
+// - timezone: the name of the timezone of the user, such as "Europe/Paris".
+// - from: the start of the period.
+// - to: the end of the period, excluded.
+//
+// Returns:
+// - *[]models.ReadingDay: a pointer to a slice of models.ReadingDay in chronological order.
+// - error: an error object if there was an issue retrieving the reading days.
+func (r *readingSessionRepositoryImp) GetReadingDays(userID, orgID, timezone string, from, to time.Time) (*[]models.ReadingDay, error) {
+	var days []models.ReadingDay
+
+	err := r.db.Model(&models.ReadingSession{}).
+		Select(`TO_CHAR(reading_sessions.started_at AT TIME ZONE ?, 'YYYY-MM-DD') AS date,
+			SUM(reading_sessions.duration) AS duration, SUM(`+readingSessionPagesSQL+`) AS pages, COUNT(*) AS sessions`, timezone).
+		Joins("JOIN books ON books.id = reading_sessions.book_id").
+		Where("reading_sessions.user_id = ? AND books.organization_id = ?", userID, orgID).
+		Where("reading_sessions.ended_at IS NOT NULL AND reading_sessions.started_at >= ? AND reading_sessions.started_at < ?", from, to).
+		Group("date").
+		Order("date ASC").
+		Scan(&days).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &days, nil
+}
+
+// UpdateReadingSession corrects the pages and the duration of a stopped reading session of a user on a book of
+// an organization. The session then ends at its start time plus its duration.
+//
+// Parameters:
+// - userID: a string representing the user ID.
+// - orgID: a string representing the organization ID.
+// - session: a pointer to the session with the new details.
+//
+// Returns:
+// - error: an error object if the session was not found, is in progress, the end page is before the start page
+// or there was an issue updating it.
+func (r *readingSessionRepositoryImp) UpdateReadingSession(userID, orgID string, session *models.ReadingSession) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	var stored models.ReadingSession
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("book_id IN (SELECT id FROM books WHERE organization_id = ?)", orgID).
+		First(&stored, "id = ? AND user_id = ?", session.ID, userID).Error
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("reading session not found")
+		}
+		return err
+	}
+
+	if stored.EndedAt == nil {
+		tx.Rollback()
+		return errors.New("reading session is in progress, stop it instead")
+	}
+
+	if session.EndPage != nil && *session.EndPage < session.StartPage {
+		tx.Rollback()
+		return errors.New("end page must not be before the start page")
+	}
+
+	endedAt := stored.StartedAt.Add(time.Duration(session.Duration) * time.Second)
+
+	err = tx.Model(&models.ReadingSession{}).
+		Where("id = ?", stored.ID).
+		Select("start_page", "end_page", "duration", "ended_at").
+		Updates(models.ReadingSession{StartPage: session.StartPage, EndPage: session.EndPage, Duration: session.Duration, EndedAt: &endedAt}).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// DeleteReadingSession deletes a reading session of a user on a book of an organization.
+//
+// Parameters:
+// - userID: a string representing the user ID.
+// - orgID: a string representing the organization ID.
+// - id: a string representing the session ID.
+//
+// Returns:
+// - error: an error object if the session was not found or there was an issue deleting it.
+func (r *readingSessionRepositoryImp) DeleteReadingSession(userID, orgID, id string) error {
+	result := r.db.
+		Where("id = ? AND user_id = ? AND book_id IN (SELECT id FROM books WHERE organization_id = ?)", id, userID, orgID).
+		Delete(&models.ReadingSession{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("reading session not found")
+	}
+
+	return nil
+}
+
+// StopForgottenReadingSessions stops the reading sessions in progress for longer than the maximum duration.
+//
+// Parameters:
+// - maxDuration: the longest a reading session may last.
+//
+// Returns:
+// - int64: the number of sessions stopped.
+// - error: an error object if there was an issue stopping the sessions.
+func (r *readingSessionRepositoryImp) StopForgottenReadingSessions(maxDuration time.Duration) (int64, error) {
+	return stopForgottenReadingSessions(r.db, maxDuration)
+}
+
+// stopForgottenReadingSessions stops the reading sessions matched by the query that are in progress for longer
+// than the maximum duration. They end at their start time plus the maximum duration, without an end page.
+func stopForgottenReadingSessions(query *gorm.DB, maxDuration time.Duration) (int64, error) {
+	seconds := int64(maxDuration.Seconds())
+
+	result := query.Model(&models.ReadingSession{}).
+		Where("ended_at IS NULL AND started_at < ?", time.Now().Add(-maxDuration)).
+		Updates(map[string]interface{}{
+			"ended_at":     gorm.Expr("started_at + make_interval(secs => ?)", seconds),
+			"duration":     seconds,
+			"auto_stopped": true,
+		})
+
+	return result.RowsAffected, result.Error
+}
+
+// organizationReadingSessions restricts a query to the reading sessions on the books of an organization,
+// with the title of their book.
+func organizationReadingSessions(orgID string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.
+			Select("reading_sessions.*, books.title AS book_title").
+			Joins("JOIN books ON books.id = reading_sessions.book_id").
+			Where("books.organization_id = ?", orgID)
+	}
+}
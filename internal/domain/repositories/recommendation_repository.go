@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"mybooks/internal/domain/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RecommendationRepository interface {
+	GetRecommendationBooks(userID, orgID string) (*[]models.RecommendationBook, error)
+}
+
+type recommendationRepositoryImp struct {
+	db *gorm.DB
+}
+
+// NewRecommendationRepository creates a new instance of the RecommendationRepository interface.
+//
+// It takes a *gorm.DB parameter, which represents the database connection.
+// It returns a RecommendationRepository pointer, which is an implementation of the RecommendationRepository interface.
+func NewRecommendationRepository(db *gorm.DB) RecommendationRepository {
+	return &recommendationRepositoryImp{
+		db: db,
+	}
+}
+
+// GetRecommendationBooks retrieves every book of an organization with the rating the user gave it, its
+// credited authors in their order and the series it belongs to, oldest book first.
+//
+// Parameters:
+// - userID: a string representing the user ID.
+// - orgID: a string representing the organization ID.
+//
+// Returns:
+// - *[]models.RecommendationBook: a pointer to a slice of models.RecommendationBook.
+// - error: an error object if there was an issue retrieving the books.
+func (r *recommendationRepositoryImp) GetRecommendationBooks(userID, orgID string) (*[]models.RecommendationBook, error) {
+	books := make([]models.RecommendationBook, 0)
+
+	err := r.db.Model(&models.Book{}).
+		Select("books.id, books.title, books.author, books.cover, books.genre, books.pages, books.read, reviews.rating, books.created_at").
+		Joins("LEFT JOIN reviews ON reviews.book_id = books.id AND reviews.user_id = ?", userID).
+		Where("books.organization_id = ?", orgID).
+		Order("books.created_at ASC, books.id ASC").
+		Scan(&books).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var credits []struct {
+		BookID uuid.UUID
+		Name   string
+	}
+	err = r.db.Table("book_authors").
+		Select("book_authors.book_id, authors.name").
+		Joins("JOIN authors ON authors.id = book_authors.author_id").
+		Joins("JOIN books ON books.id = book_authors.book_id").
+		Where("books.organization_id = ? AND book_authors.role = ?", orgID, "author").
+		Order("book_authors.book_id, book_authors.position").
+		Scan(&credits).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var series []models.RecommendationSeries
+	err = r.db.Table("book_series").
+		Select("book_series.book_id, series.id AS series_id, series.name, book_series.position").
+		Joins("JOIN series ON series.id = book_series.series_id").
+		Joins("JOIN books ON books.id = book_series.book_id").
+		Where("books.organization_id = ?", orgID).
+		Order("series.name, series.id, book_series.position").
+		Scan(&series).Error
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uuid.UUID]*models.RecommendationBook, len(books))
+	for i := range books {
+		byID[books[i].ID] = &books[i]
+	}
+
+	for _, credit := range credits {
+		if book, ok := byID[credit.BookID]; ok {
+			book.Authors = append(book.Authors, credit.Name)
+		}
+	}
+
+	for _, entry := range series {
+		if book, ok := byID[entry.BookID]; ok {
+			book.Series = append(book.Series, entry)
+		}
+	}
+
+	return &books, nil
+}
@@ -0,0 +1,158 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"mybooks/internal/domain/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReviewRepository interface {
+	SetReview(orgID string, review *models.Review) error
+	GetReview(userID, orgID, bookID string) (*models.Review, error)
+	GetReviews(userID, orgID string) (*[]models.Review, error)
+	DeleteReview(userID, orgID, bookID string) error
+}
+
+type reviewRepositoryImp struct {
+	db *gorm.DB
+}
+
+// NewReviewRepository creates a new instance of the ReviewRepository interface.
+//
+// It takes a *gorm.DB parameter, which represents the database connection.
+// It returns a ReviewRepository pointer, which is an implementation of the ReviewRepository interface.
+func NewReviewRepository(db *gorm.DB) ReviewRepository {
+	return &reviewRepositoryImp{
+		db: db,
+	}
+}
+
+// SetReview rates and reviews a book of an organization, replacing the previous review of the user if any.
+// The review is reloaded afterwards, so it holds the ID and creation date of the stored review.
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+// - review: a pointer to the review.
+//
+// Returns:
+// - error: an error object if the book was not found or there was an issue saving the review.
+func (r *reviewRepositoryImp) SetReview(orgID string, review *models.Review) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	if err := tx.First(&models.Book{}, "id = ? AND organization_id = ?", review.BookID, orgID).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("book not found")
+		}
+		return err
+	}
+
+	err := tx.Omit("User").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "book_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rating", "body", "updated_at"}),
+	}).Create(review).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var stored models.Review
+	if err := tx.First(&stored, "book_id = ? AND user_id = ?", review.BookID, review.UserID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	*review = stored
+
+	return tx.Commit().Error
+}
+
+// GetReview retrieves the review of a book of an organization by a user.
+//
+// Parameters:
+// - userID: a string representing the user ID.
+// - orgID: a string representing the organization ID.
+// - bookID: a string representing the book ID.
+//
+// Returns:
+// - *models.Review: a pointer to the review.
+// - error: an error object if the review was not found or there was an issue retrieving it.
+func (r *reviewRepositoryImp) GetReview(userID, orgID, bookID string) (*models.Review, error) {
+	var review models.Review
+
+	err := r.db.Scopes(organizationReviews(orgID)).
+		First(&review, "reviews.book_id = ? AND reviews.user_id = ?", bookID, userID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("review not found")
+		}
+		return nil, err
+	}
+
+	return &review, nil
+}
+
+// GetReviews retrieves the reviews of the books of an organization by a user, most recently updated first.
+//
+// Parameters:
+// - userID: a string representing the user ID.
+// - orgID: a string representing the organization ID.
+//
+// Returns:
+// - *[]models.Review: a pointer to a slice of models.Review representing the reviews.
+// - error: an error object if there was an issue retrieving the reviews.
+func (r *reviewRepositoryImp) GetReviews(userID, orgID string) (*[]models.Review, error) {
+	var reviews []models.Review
+
+	err := r.db.Scopes(organizationReviews(orgID)).
+		Where("reviews.user_id = ?", userID).
+		Order("reviews.updated_at DESC").
+		Find(&reviews).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &reviews, nil
+}
+
+// DeleteReview deletes the review of a book of an organization by a user.
+//
+// Parameters:
+// - userID: a string representing the user ID.
+// - orgID: a string representing the organization ID.
+// - bookID: a string representing the book ID.
+//
+// Returns:
+// - error: an error object if the review was not found or there was an issue deleting it.
+func (r *reviewRepositoryImp) DeleteReview(userID, orgID, bookID string) error {
+	result := r.db.
+		Where("book_id = ? AND user_id = ? AND book_id IN (SELECT id FROM books WHERE organization_id = ?)", bookID, userID, orgID).
+		Delete(&models.Review{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("review not found")
+	}
+
+	return nil
+}
+
+// organizationReviews restricts the query to the reviews of the books of an organization, with the title of their book.
+func organizationReviews(orgID string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.
+			Select("reviews.*, books.title AS book_title").
+			Joins("JOIN books ON books.id = reviews.book_id").
+			Where("books.organization_id = ?", orgID)
+	}
+}
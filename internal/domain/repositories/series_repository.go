@@ -0,0 +1,264 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"mybooks/internal/domain/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SeriesRepository interface {
+	CreateSeries(series *models.Series) error
+	GetAllSeries(orgID string) (*[]models.Series, error)
+	GetSeriesByID(orgID, id string) (*models.Series, error)
+	GetSeriesWithUnreadBooks(orgID string) (*[]models.Series, error)
+	UpdateSeries(orgID string, series *models.Series) error
+	DeleteSeries(orgID, id string) error
+	AddBookToSeries(orgID, seriesID, bookID string, position float64) error
+	RemoveBookFromSeries(orgID, seriesID, bookID string) error
+}
+
+type seriesRepositoryImp struct {
+	db *gorm.DB
+}
+
+// NewSeriesRepository creates a new instance of the SeriesRepository interface.
+//
+// It takes a *gorm.DB parameter, which represents the database connection.
+// It returns a SeriesRepository pointer, which is an implementation of the SeriesRepository interface.
+func NewSeriesRepository(db *gorm.DB) SeriesRepository {
+	return &seriesRepositoryImp{
+		db: db,
+	}
+}
+
+// CreateSeries creates a new series in the database.
+//
+// It takes a pointer to a Series struct as a parameter and returns an error.
+func (r *seriesRepositoryImp) CreateSeries(series *models.Series) error {
+	return r.db.Omit("Books").Create(series).Error
+}
+
+// GetAllSeries retrieves all series of an organization ordered by name.
+//
+// Each series is returned with the number of volumes the organization owns and how many of them were read.
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+//
+// Returns:
+// - *[]models.Series: a pointer to a slice of models.Series objects representing the retrieved series.
+// - error: an error object if there was an issue retrieving the series.
+func (r *seriesRepositoryImp) GetAllSeries(orgID string) (*[]models.Series, error) {
+	var series []models.Series
+
+	err := r.db.Model(&models.Series{}).
+		Select(`series.*,
+			(SELECT COUNT(*) FROM book_series WHERE book_series.series_id = series.id) AS book_count,
+			(SELECT COUNT(*) FROM book_series JOIN books ON books.id = book_series.book_id
+				WHERE book_series.series_id = series.id AND books.read = true) AS read_count`).
+		Where("organization_id = ?", orgID).
+		Order("name ASC").
+		Find(&series).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &series, nil
+}
+
+// GetSeriesByID retrieves a series by its ID with its volumes ordered by position.
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+// - id: a string representing the ID of the series to retrieve.
+//
+// Returns:
+// - *models.Series: a pointer to the retrieved series.
+// - error: an error object if there was an issue retrieving the series.
+func (r *seriesRepositoryImp) GetSeriesByID(orgID, id string) (*models.Series, error) {
+	var series models.Series
+
+	err := r.db.Scopes(preloadSeriesBooks).First(&series, "id = ? AND organization_id = ?", id, orgID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("series not found")
+		}
+		return nil, err
+	}
+
+	return &series, nil
+}
+
+// GetSeriesWithUnreadBooks retrieves the series of an organization that still have unread volumes.
+//
+// The volumes of each series are preloaded in position order.
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+//
+// Returns:
+// - *[]models.Series: a pointer to a slice of models.Series objects representing the retrieved series.
+// - error: an error object if there was an issue retrieving the series.
+func (r *seriesRepositoryImp) GetSeriesWithUnreadBooks(orgID string) (*[]models.Series, error) {
+	var series []models.Series
+
+	err := r.db.Scopes(preloadSeriesBooks).
+		Where("organization_id = ?", orgID).
+		Where(`EXISTS (SELECT 1 FROM book_series JOIN books ON books.id = book_series.book_id
+			WHERE book_series.series_id = series.id AND books.read = false)`).
+		Order("name ASC").
+		Find(&series).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &series, nil
+}
+
+// UpdateSeries updates a series in the repository.
+//
+// It takes an orgID and a series as parameters and returns an error.
+// The function returns an error if the series is not found.
+func (r *seriesRepositoryImp) UpdateSeries(orgID string, series *models.Series) error {
+	result := r.db.Model(&models.Series{}).Omit("ID", "CreatedAt", "Books").Where("id = ? AND organization_id = ?", series.ID, orgID).Updates(series)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("series not found")
+	}
+
+	return nil
+}
+
+// DeleteSeries deletes a series and its volume positions. The books themselves are kept.
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+// - id: a string representing the ID of the series to delete.
+//
+// Returns:
+// - error: an error object if there was an issue deleting the series.
+func (r *seriesRepositoryImp) DeleteSeries(orgID, id string) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	// Delete the relation in the book_series table
+	if err := tx.Exec("DELETE FROM book_series WHERE series_id IN (SELECT id FROM series WHERE id = ? AND organization_id = ?)", id, orgID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Delete the series
+	result := tx.Where("id = ? AND organization_id = ?", id, orgID).Delete(&models.Series{})
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+
+	// Check if no rows were affected (series not found)
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return errors.New("series not found")
+	}
+
+	return tx.Commit().Error
+}
+
+// AddBookToSeries places a book in a series at the given position.
+//
+// Positions may be fractional (e.g. 2.5 for a novella between volumes 2 and 3).
+// If the book is already in the series its position is updated.
+//
+// Parameters:
+// - orgID: a string representing the ID of the organization.
+// - seriesID: a string representing the ID of the series.
+// - bookID: a string representing the ID of the book.
+// - position: the position of the book in the series.
+//
+// Returns:
+// - error: an error object if there was an issue adding the book to the series.
+func (r *seriesRepositoryImp) AddBookToSeries(orgID, seriesID, bookID string, position float64) error {
+	seriesUUID, err := uuid.Parse(seriesID)
+	if err != nil {
+		return err
+	}
+
+	bookUUID, err := uuid.Parse(bookID)
+	if err != nil {
+		return err
+	}
+
+	if err := r.db.First(&models.Series{}, "id = ? AND organization_id = ?", seriesUUID, orgID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("series not found")
+		}
+		return err
+	}
+
+	if err := r.db.First(&models.Book{}, "id = ? AND organization_id = ?", bookUUID, orgID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("book not found")
+		}
+		return err
+	}
+
+	link := models.BookSeries{
+		BookID:   bookUUID,
+		SeriesID: seriesUUID,
+		Position: position,
+	}
+
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "book_id"}, {Name: "series_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"position"}),
+	}).Create(&link).Error
+}
+
+// RemoveBookFromSeries removes a book from a series.
+//
+// Parameters:
+// - orgID: a string representing the ID of the organization.
+// - seriesID: a string representing the ID of the series.
+// - bookID: a string representing the ID of the book.
+//
+// Returns:
+// - error: an error object if there was an issue removing the book from the series.
+func (r *seriesRepositoryImp) RemoveBookFromSeries(orgID, seriesID, bookID string) error {
+	if err := r.db.First(&models.Series{}, "id = ? AND organization_id = ?", seriesID, orgID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("series not found")
+		}
+		return err
+	}
+
+	result := r.db.Where("series_id = ? AND book_id = ?", seriesID, bookID).Delete(&models.BookSeries{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("book not found")
+	}
+
+	return nil
+}
+
+// preloadSeriesBooks preloads the volumes of the series in position order.
+func preloadSeriesBooks(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Books", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC")
+		}).
+		Preload("Books.Book")
+}
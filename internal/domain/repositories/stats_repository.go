@@ -0,0 +1,162 @@
+package repositories
+
+import (
+	"mybooks/internal/domain/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// statsGroupSQL counts the books of a group, their pages and how many of them were read.
+const statsGroupSQL = "COUNT(books.id) AS books, COALESCE(SUM(books.pages), 0) AS pages, COUNT(books.id) FILTER (WHERE books.read) AS read"
+
+// statsDecadeSQL is the decade a book was published in, such as "1990s", read from the year its published
+// date starts with. It is empty when the published date does not start with a year.
+const statsDecadeSQL = "CASE WHEN books.published_date ~ '^\\d{4}' THEN SUBSTRING(books.published_date FROM 1 FOR 3) || '0s' ELSE '' END"
+
+// statsAuthorsLimit is the number of authors with the most books in the statistics.
+const statsAuthorsLimit = 20
+
+// statsBooksLimit is the number of longest and shortest books in the statistics.
+const statsBooksLimit = 5
+
+type StatsRepository interface {
+	GetCollectionStats(orgID, timezone string, since time.Time) (*models.CollectionStats, error)
+}
+
+type statsRepositoryImp struct {
+	db *gorm.DB
+}
+
+// NewStatsRepository creates a new instance of the StatsRepository interface.
+//
+// It takes a *gorm.DB parameter, which represents the database connection.
+// It returns a StatsRepository pointer, which is an implementation of the StatsRepository interface.
+func NewStatsRepository(db *gorm.DB) StatsRepository {
+	return &statsRepositoryImp{
+		db: db,
+	}
+}
+
+// GetCollectionStats computes the statistics of the books of an organization in the database.
+//
+// The books are counted by genre, language, decade and library, and for the authors with the most books.
+// The books added per month are counted from the given time on, in the given timezone, and only the months
+// with books are returned.
+// The longest and shortest books leave out the books without a number of pages.
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+// - timezone: the name of the timezone the months are in, such as "Europe/Paris".
+// - since: the start of the period the books added per month are counted for.
+//
+// Returns:
+// - *models.CollectionStats: a pointer to the statistics.
+// - error: an error object if there was an issue computing the statistics.
+func (r *statsRepositoryImp) GetCollectionStats(orgID, timezone string, since time.Time) (*models.CollectionStats, error) {
+	stats := models.CollectionStats{}
+
+	books := func() *gorm.DB {
+		return r.db.Model(&models.Book{}).Where("books.organization_id = ?", orgID)
+	}
+
+	err := books().
+		Select(`COUNT(*) AS books, COALESCE(SUM(books.pages), 0) AS pages,
+			COUNT(*) FILTER (WHERE books.read) AS read, COUNT(*) FILTER (WHERE NOT books.read) AS unread`).
+		Scan(&stats.Totals).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.db.Model(&models.Copy{}).Where("organization_id = ?", orgID).Count(&stats.Totals.Copies).Error; err != nil {
+		return nil, err
+	}
+
+	groups := []struct {
+		target *[]models.StatsGroup
+		key    string
+	}{
+		{&stats.Genres, "books.genre"},
+		{&stats.Languages, "books.language"},
+		{&stats.Decades, statsDecadeSQL},
+	}
+
+	for _, group := range groups {
+		*group.target = make([]models.StatsGroup, 0)
+		err := books().
+			Select(group.key + " AS key, " + statsGroupSQL).
+			Group("key").
+			Order("books DESC, key ASC").
+			Scan(group.target).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	stats.Authors = make([]models.StatsGroup, 0)
+	err = books().
+		Select("authors.name AS key, authors.id AS id, "+statsGroupSQL).
+		Joins("JOIN book_authors ON book_authors.book_id = books.id AND book_authors.role = ?", "author").
+		Joins("JOIN authors ON authors.id = book_authors.author_id").
+		Group("authors.id, authors.name").
+		Order("books DESC, key ASC").
+		Limit(statsAuthorsLimit).
+		Scan(&stats.Authors).Error
+	if err != nil {
+		return nil, err
+	}
+
+	stats.Libraries = make([]models.StatsGroup, 0)
+	err = r.db.Model(&models.Library{}).
+		Select("libraries.name AS key, libraries.id AS id, "+statsGroupSQL).
+		Joins("LEFT JOIN book_library ON book_library.library_id = libraries.id").
+		Joins("LEFT JOIN books ON books.id = book_library.book_id").
+		Where("libraries.organization_id = ?", orgID).
+		Group("libraries.id, libraries.name").
+		Order("books DESC, key ASC").
+		Scan(&stats.Libraries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.Model(&models.Loan{}).
+		Select(`COUNT(*) AS out, COUNT(*) FILTER (WHERE NULLIF(due_date, '')::date < CURRENT_DATE) AS overdue`).
+		Where("organization_id = ? AND status IN ?", orgID, []string{models.LoanStatusActive, models.LoanStatusReturnPending}).
+		Scan(&stats.Loans).Error
+	if err != nil {
+		return nil, err
+	}
+
+	stats.AddedPerMonth = make([]models.StatsGroup, 0)
+	err = books().
+		Select("TO_CHAR(books.created_at AT TIME ZONE ?, 'YYYY-MM') AS key, "+statsGroupSQL, timezone).
+		Where("books.created_at >= ?", since).
+		Group("key").
+		Order("key ASC").
+		Scan(&stats.AddedPerMonth).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = books().
+		Select("id", "title", "author", "pages", "read").
+		Where("books.pages > 0").
+		Order("books.pages DESC, books.title ASC").
+		Limit(statsBooksLimit).
+		Find(&stats.Longest).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = books().
+		Select("id", "title", "author", "pages", "read").
+		Where("books.pages > 0").
+		Order("books.pages ASC, books.title ASC").
+		Limit(statsBooksLimit).
+		Find(&stats.Shortest).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
@@ -0,0 +1,332 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"mybooks/internal/domain/models"
+	"mybooks/pkg"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TagRepository interface {
+	CreateTag(tag *models.Tag) error
+	GetAllTags(orgID string, filters map[string]interface{}) (*[]models.Tag, error)
+	UpdateTag(orgID string, tag *models.Tag) error
+	DeleteTag(orgID, id string) error
+	MergeTags(orgID, targetID string, sourceIDs []string) error
+	AddTagToBook(orgID, bookID string, tag *models.Tag) error
+	RemoveTagFromBook(orgID, bookID, tagID string) error
+}
+
+type tagRepositoryImp struct {
+	db *gorm.DB
+}
+
+// NewTagRepository creates a new instance of the TagRepository interface.
+//
+// It takes a *gorm.DB parameter, which represents the database connection.
+// It returns a TagRepository pointer, which is an implementation of the TagRepository interface.
+func NewTagRepository(db *gorm.DB) TagRepository {
+	return &tagRepositoryImp{
+		db: db,
+	}
+}
+
+// CreateTag creates a new tag in the database.
+//
+// Tag names are unique per organization regardless of case.
+// It returns an error if the organization already has a tag with the same name.
+func (r *tagRepositoryImp) CreateTag(tag *models.Tag) error {
+	tag.NormalizedName = pkg.NormalizeTagName(tag.Name)
+	orgID := tag.OrganizationID.String()
+
+	if err := r.ensureTagNameIsFree(orgID, tag); err != nil {
+		return err
+	}
+
+	return r.db.Omit("Books").Create(tag).Error
+}
+
+// GetAllTags retrieves the tags of an organization with the number of books using each one.
+//
+// The "prefix" filter matches the beginning of the tag name (case-insensitive) and is used for
+// autocomplete, in which case the most used tags come first. Otherwise tags are ordered by name.
+// The "limit" filter restricts the number of returned tags.
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+// - filters: a map of key-value pairs representing the filters to be applied.
+//
+// Returns:
+// - *[]models.Tag: a pointer to a slice of models.Tag objects representing the retrieved tags.
+// - error: an error object if there was an issue retrieving the tags.
+func (r *tagRepositoryImp) GetAllTags(orgID string, filters map[string]interface{}) (*[]models.Tag, error) {
+	var tags []models.Tag
+	query := r.db.Model(&models.Tag{}).
+		Select("tags.*, (SELECT COUNT(*) FROM book_tag WHERE book_tag.tag_id = tags.id) AS book_count").
+		Where("organization_id = ?", orgID)
+
+	if prefix, ok := filters["prefix"]; ok {
+		query = query.Where("normalized_name LIKE ?", fmt.Sprintf("%s%%", prefix)).Order("book_count DESC")
+	}
+
+	if limit, ok := filters["limit"]; ok {
+		query = query.Limit(limit.(int))
+	}
+
+	if err := query.Order("normalized_name ASC").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	return &tags, nil
+}
+
+// UpdateTag renames a tag or changes its color.
+//
+// It returns an error if the tag is not found or another tag of the organization already has the new name.
+func (r *tagRepositoryImp) UpdateTag(orgID string, tag *models.Tag) error {
+	tag.NormalizedName = pkg.NormalizeTagName(tag.Name)
+
+	if err := r.ensureTagNameIsFree(orgID, tag); err != nil {
+		return err
+	}
+
+	result := r.db.Model(&models.Tag{}).Where("id = ? AND organization_id = ?", tag.ID, orgID).Updates(map[string]interface{}{
+		"name":            tag.Name,
+		"normalized_name": tag.NormalizedName,
+		"color":           tag.Color,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("tag not found")
+	}
+
+	return nil
+}
+
+// DeleteTag deletes a tag and removes it from every book and note.
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+// - id: a string representing the ID of the tag to delete.
+//
+// Returns:
+// - error: an error object if there was an issue deleting the tag.
+func (r *tagRepositoryImp) DeleteTag(orgID, id string) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	// Delete the relation in the book_tag table
+	if err := tx.Exec("DELETE FROM book_tag WHERE tag_id IN (SELECT id FROM tags WHERE id = ? AND organization_id = ?)", id, orgID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Delete the relation in the note_tag table
+	if err := tx.Exec("DELETE FROM note_tag WHERE tag_id IN (SELECT id FROM tags WHERE id = ? AND organization_id = ?)", id, orgID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Delete the tag
+	result := tx.Where("id = ? AND organization_id = ?", id, orgID).Delete(&models.Tag{})
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+
+	// Check if no rows were affected (tag not found)
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return errors.New("tag not found")
+	}
+
+	return tx.Commit().Error
+}
+
+// MergeTags merges the source tags into the target tag.
+//
+// Every book and note tagged with one of the source tags is tagged with the target tag instead
+// and the source tags are deleted.
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+// - targetID: a string representing the ID of the tag that is kept.
+// - sourceIDs: a slice of strings representing the IDs of the tags merged into the target.
+//
+// Returns:
+// - error: an error object if there was an issue merging the tags.
+func (r *tagRepositoryImp) MergeTags(orgID, targetID string, sourceIDs []string) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	var target models.Tag
+	if err := tx.First(&target, "id = ? AND organization_id = ?", targetID, orgID).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("tag not found")
+		}
+		return err
+	}
+
+	var ids []uuid.UUID
+	if err := tx.Model(&models.Tag{}).Where("id IN ? AND organization_id = ? AND id <> ?", sourceIDs, orgID, target.ID).Pluck("id", &ids).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if len(ids) == 0 {
+		tx.Rollback()
+		return fmt.Errorf("tag not found")
+	}
+
+	if err := tx.Exec(`INSERT INTO book_tag (book_id, tag_id)
+		SELECT DISTINCT book_id, ? FROM book_tag WHERE tag_id IN ?
+		ON CONFLICT DO NOTHING`, target.ID, ids).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Exec("DELETE FROM book_tag WHERE tag_id IN ?", ids).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Exec(`INSERT INTO note_tag (note_id, tag_id)
+		SELECT DISTINCT note_id, ? FROM note_tag WHERE tag_id IN ?
+		ON CONFLICT DO NOTHING`, target.ID, ids).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Exec("DELETE FROM note_tag WHERE tag_id IN ?", ids).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Where("id IN ?", ids).Delete(&models.Tag{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// AddTagToBook tags a book, creating the tag when the organization has no tag with the same name.
+//
+// The tag is matched by name regardless of case. The ID, color and creator are only used for new tags.
+// The tag pointer is filled with the stored tag.
+//
+// Parameters:
+// - orgID: a string representing the ID of the organization.
+// - bookID: a string representing the ID of the book.
+// - tag: a pointer to a models.Tag object with the name and color of the tag.
+//
+// Returns:
+// - error: an error object if there was an issue tagging the book.
+func (r *tagRepositoryImp) AddTagToBook(orgID, bookID string, tag *models.Tag) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	var book models.Book
+	if err := tx.First(&book, "id = ? AND organization_id = ?", bookID, orgID).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("book not found")
+		}
+		return err
+	}
+
+	tag.OrganizationID = book.OrganizationID
+
+	if err := findOrCreateTag(tx, tag); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Exec("INSERT INTO book_tag (book_id, tag_id) VALUES (?, ?) ON CONFLICT DO NOTHING", book.ID, tag.ID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// RemoveTagFromBook removes a tag from a book. The tag itself is kept.
+//
+// Parameters:
+// - orgID: a string representing the ID of the organization.
+// - bookID: a string representing the ID of the book.
+// - tagID: a string representing the ID of the tag.
+//
+// Returns:
+// - error: an error object if there was an issue removing the tag from the book.
+func (r *tagRepositoryImp) RemoveTagFromBook(orgID, bookID, tagID string) error {
+	result := r.db.Exec(`DELETE FROM book_tag WHERE book_id = ? AND tag_id = ?
+		AND tag_id IN (SELECT id FROM tags WHERE organization_id = ?)`, bookID, tagID, orgID)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("tag not found")
+	}
+
+	return nil
+}
+
+// findOrCreateTag fills the tag pointer with the tag of its organization having the same name regardless
+// of case, creating the tag when there is none.
+func findOrCreateTag(tx *gorm.DB, tag *models.Tag) error {
+	tag.NormalizedName = pkg.NormalizeTagName(tag.Name)
+
+	var existing models.Tag
+	err := tx.Where("organization_id = ? AND normalized_name = ?", tag.OrganizationID, tag.NormalizedName).First(&existing).Error
+	if err == nil {
+		*tag = existing
+		return nil
+	}
+
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	return tx.Omit("Books").Create(tag).Error
+}
+
+// ensureTagNameIsFree returns an error if another tag of the same organization already uses the name of the tag.
+func (r *tagRepositoryImp) ensureTagNameIsFree(orgID string, tag *models.Tag) error {
+	var count int64
+	err := r.db.Model(&models.Tag{}).
+		Where("organization_id = ? AND normalized_name = ? AND id <> ?", orgID, tag.NormalizedName, tag.ID).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return fmt.Errorf("tag with name %s already exists", tag.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package repositories
+
+import (
+	"errors"
+	"mybooks/internal/domain/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ValuationRepository interface {
+	GetValuationGroups(orgID string) ([]models.ValuationGroup, error)
+	GetCopyValuations(orgID string) ([]models.CopyValuation, error)
+	GetExchangeRates(orgID string) (*[]models.ExchangeRate, error)
+	SetExchangeRate(rate *models.ExchangeRate) error
+	DeleteExchangeRate(orgID, id string) error
+}
+
+type valuationRepositoryImp struct {
+	db *gorm.DB
+}
+
+// NewValuationRepository creates a new instance of the ValuationRepository interface.
+//
+// It takes a *gorm.DB parameter, which represents the database connection.
+// It returns a ValuationRepository pointer, which is an implementation of the ValuationRepository interface.
+func NewValuationRepository(db *gorm.DB) ValuationRepository {
+	return &valuationRepositoryImp{
+		db: db,
+	}
+}
+
+// GetValuationGroups sums the prices and values of the copies of an organization by library,
+// acquisition year and currencies. Copies without a known acquisition date have an empty year.
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+//
+// Returns:
+// - []models.ValuationGroup: the sums of every group.
+// - error: an error object if there was an issue computing the sums.
+func (r *valuationRepositoryImp) GetValuationGroups(orgID string) ([]models.ValuationGroup, error) {
+	var groups []models.ValuationGroup
+
+	err := r.db.Model(&models.Copy{}).
+		Select(`copies.library_id, COALESCE(libraries.name, '') AS library_name, LEFT(copies.acquired_on, 4) AS year,
+			copies.currency, copies.value_currency, COUNT(*) AS copies, SUM(copies.price) AS paid, SUM(copies.value) AS value`).
+		Joins("LEFT JOIN libraries ON libraries.id = copies.library_id").
+		Where("copies.organization_id = ?", orgID).
+		Group("copies.library_id, libraries.name, LEFT(copies.acquired_on, 4), copies.currency, copies.value_currency").
+		Scan(&groups).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+// GetCopyValuations retrieves every copy of an organization with the details of its book and library,
+// ordered by title.
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+//
+// Returns:
+// - []models.CopyValuation: the copies of the organization.
+// - error: an error object if there was an issue retrieving the copies.
+func (r *valuationRepositoryImp) GetCopyValuations(orgID string) ([]models.CopyValuation, error) {
+	var copies []models.CopyValuation
+
+	err := r.db.Model(&models.Copy{}).
+		Select(`copies.id AS copy_id, books.title, books.author, books.isbn, COALESCE(libraries.name, '') AS library_name,
+			copies.condition, copies.location, copies.acquired_on, copies.acquired_from, copies.gift_from,
+			copies.price, copies.currency, copies.value, copies.value_currency, copies.valued_on`).
+		Joins("JOIN books ON books.id = copies.book_id").
+		Joins("LEFT JOIN libraries ON libraries.id = copies.library_id").
+		Where("copies.organization_id = ?", orgID).
+		Order("books.title ASC, copies.created_at ASC").
+		Scan(&copies).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return copies, nil
+}
+
+// GetExchangeRates retrieves the exchange rates of an organization.
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+//
+// Returns:
+// - *[]models.ExchangeRate: a pointer to a slice of models.ExchangeRate representing the rates.
+// - error: an error object if there was an issue retrieving the rates.
+func (r *valuationRepositoryImp) GetExchangeRates(orgID string) (*[]models.ExchangeRate, error) {
+	var rates []models.ExchangeRate
+
+	if err := r.db.Where("organization_id = ?", orgID).Order("from_currency ASC, to_currency ASC").Find(&rates).Error; err != nil {
+		return nil, err
+	}
+
+	return &rates, nil
+}
+
+// SetExchangeRate creates the exchange rate of a pair of currencies, or replaces the rate of the pair
+// when the organization already has it.
+//
+// Parameters:
+// - rate: a pointer to the exchange rate.
+//
+// Returns:
+// - error: an error object if there was an issue saving the rate.
+func (r *valuationRepositoryImp) SetExchangeRate(rate *models.ExchangeRate) error {
+	return r.db.Omit("User").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "organization_id"}, {Name: "from_currency"}, {Name: "to_currency"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "user_id", "updated_at"}),
+	}).Create(rate).Error
+}
+
+// DeleteExchangeRate deletes an exchange rate of an organization.
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+// - id: a string representing the exchange rate ID.
+//
+// Returns:
+// - error: an error object if the rate was not found or there was an issue deleting it.
+func (r *valuationRepositoryImp) DeleteExchangeRate(orgID, id string) error {
+	result := r.db.Where("id = ? AND organization_id = ?", id, orgID).Delete(&models.ExchangeRate{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("exchange rate not found")
+	}
+
+	return nil
+}
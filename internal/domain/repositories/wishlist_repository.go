@@ -0,0 +1,310 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"mybooks/internal/domain/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WishlistRepository interface {
+	CreateWishlistItem(item *models.WishlistItem) error
+	GetWishlist(orgID string, bought bool) (*[]models.WishlistItem, error)
+	GetWishlistItem(orgID, id string) (*models.WishlistItem, error)
+	UpdateWishlistItem(orgID string, item *models.WishlistItem) error
+	DeleteWishlistItem(orgID, id string) error
+	BuyWishlistItem(orgID, id string, book *models.Book, bookCopy *models.Copy) error
+	ShareWishlist(share *models.WishlistShare) error
+	UnshareWishlist(orgID string) error
+	GetSharedWishlist(token string) (*models.Organization, *[]models.WishlistItem, error)
+}
+
+type wishlistRepositoryImp struct {
+	db *gorm.DB
+}
+
+// NewWishlistRepository creates a new instance of the WishlistRepository interface.
+//
+// It takes a *gorm.DB parameter, which represents the database connection.
+// It returns a WishlistRepository pointer, which is an implementation of the WishlistRepository interface.
+func NewWishlistRepository(db *gorm.DB) WishlistRepository {
+	return &wishlistRepositoryImp{
+		db: db,
+	}
+}
+
+// CreateWishlistItem creates a wishlist item with its links.
+//
+// Parameters:
+// - item: a pointer to the wishlist item.
+//
+// Returns:
+// - error: an error object if there was an issue creating the item.
+func (r *wishlistRepositoryImp) CreateWishlistItem(item *models.WishlistItem) error {
+	return r.db.Omit("User").Create(item).Error
+}
+
+// GetWishlist retrieves the wishlist items of an organization with their links, most wanted first.
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+// - bought: whether to retrieve the items already bought instead of the wanted ones.
+//
+// Returns:
+// - *[]models.WishlistItem: a pointer to a slice of models.WishlistItem representing the items.
+// - error: an error object if there was an issue retrieving the items.
+func (r *wishlistRepositoryImp) GetWishlist(orgID string, bought bool) (*[]models.WishlistItem, error) {
+	var items []models.WishlistItem
+
+	query := r.db.Where("organization_id = ?", orgID).Scopes(preloadWishlistLinks)
+	if bought {
+		query = query.Where("bought_at IS NOT NULL").Order("bought_at DESC")
+	} else {
+		query = query.Where("bought_at IS NULL").Order("priority DESC, created_at ASC")
+	}
+
+	if err := query.Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	return &items, nil
+}
+
+// GetWishlistItem retrieves a wishlist item of an organization with its links.
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+// - id: a string representing the wishlist item ID.
+//
+// Returns:
+// - *models.WishlistItem: a pointer to the item.
+// - error: an error object if the item was not found or there was an issue retrieving it.
+func (r *wishlistRepositoryImp) GetWishlistItem(orgID, id string) (*models.WishlistItem, error) {
+	var item models.WishlistItem
+
+	if err := r.db.Scopes(preloadWishlistLinks).First(&item, "id = ? AND organization_id = ?", id, orgID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("wishlist item not found")
+		}
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+// UpdateWishlistItem updates the details of a wishlist item. When the item has links they replace
+// the previous links, and an empty list removes them all.
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+// - item: a pointer to the wishlist item with the new details.
+//
+// Returns:
+// - error: an error object if the item was not found or there was an issue updating it.
+func (r *wishlistRepositoryImp) UpdateWishlistItem(orgID string, item *models.WishlistItem) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	result := tx.Model(&models.WishlistItem{}).
+		Where("id = ? AND organization_id = ?", item.ID, orgID).
+		Select("title", "author", "isbn", "cover", "priority", "notes", "target_price", "currency").
+		Updates(models.WishlistItem{
+			Title:       item.Title,
+			Author:      item.Author,
+			ISBN:        item.ISBN,
+			Cover:       item.Cover,
+			Priority:    item.Priority,
+			Notes:       item.Notes,
+			TargetPrice: item.TargetPrice,
+			Currency:    item.Currency,
+		})
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return fmt.Errorf("wishlist item not found")
+	}
+
+	if item.Links != nil {
+		if err := tx.Where("wishlist_item_id = ?", item.ID).Delete(&models.WishlistLink{}).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if len(item.Links) > 0 {
+			if err := tx.Create(&item.Links).Error; err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+// DeleteWishlistItem deletes a wishlist item with its links. Deleting a bought item keeps its book.
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+// - id: a string representing the wishlist item ID.
+//
+// Returns:
+// - error: an error object if the item was not found or there was an issue deleting it.
+func (r *wishlistRepositoryImp) DeleteWishlistItem(orgID, id string) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	if err := tx.Exec("DELETE FROM wishlist_links WHERE wishlist_item_id = ? AND wishlist_item_id IN (SELECT id FROM wishlist_items WHERE organization_id = ?)", id, orgID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	result := tx.Where("id = ? AND organization_id = ?", id, orgID).Delete(&models.WishlistItem{})
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return fmt.Errorf("wishlist item not found")
+	}
+
+	return tx.Commit().Error
+}
+
+// BuyWishlistItem turns a wishlist item into an owned book with its first copy, and marks the item
+// as bought by linking it to the book.
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+// - id: a string representing the wishlist item ID.
+// - book: a pointer to the book to create.
+// - bookCopy: a pointer to the copy of the book that was bought.
+//
+// Returns:
+// - error: an error object if the item was not found or already bought, or there was an issue creating the book.
+func (r *wishlistRepositoryImp) BuyWishlistItem(orgID, id string, book *models.Book, bookCopy *models.Copy) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	var item models.WishlistItem
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&item, "id = ? AND organization_id = ?", id, orgID).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("wishlist item not found")
+		}
+		return err
+	}
+
+	if item.BoughtAt != nil {
+		tx.Rollback()
+		return errors.New("wishlist item already bought")
+	}
+
+	if err := createBook(tx, book, bookCopy); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Model(&item).Updates(map[string]interface{}{"book_id": book.ID, "bought_at": time.Now()}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// ShareWishlist creates the public link of the wishlist of an organization. Sharing it again replaces
+// the token, so the previous link stops working.
+//
+// Parameters:
+// - share: a pointer to the wishlist share.
+//
+// Returns:
+// - error: an error object if there was an issue saving the share.
+func (r *wishlistRepositoryImp) ShareWishlist(share *models.WishlistShare) error {
+	return r.db.Omit("User").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "organization_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"token", "user_id", "updated_at"}),
+	}).Create(share).Error
+}
+
+// UnshareWishlist removes the public link of the wishlist of an organization.
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+//
+// Returns:
+// - error: an error object if the wishlist was not shared or there was an issue removing the link.
+func (r *wishlistRepositoryImp) UnshareWishlist(orgID string) error {
+	result := r.db.Where("organization_id = ?", orgID).Delete(&models.WishlistShare{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("wishlist share not found")
+	}
+
+	return nil
+}
+
+// GetSharedWishlist retrieves the organization and the wanted wishlist items behind a public wishlist token.
+//
+// Parameters:
+// - token: a string representing the token of the public link.
+//
+// Returns:
+// - *models.Organization: a pointer to the organization owning the wishlist.
+// - *[]models.WishlistItem: a pointer to a slice of models.WishlistItem representing the wanted items.
+// - error: an error object if the token was not found or there was an issue retrieving the wishlist.
+func (r *wishlistRepositoryImp) GetSharedWishlist(token string) (*models.Organization, *[]models.WishlistItem, error) {
+	var share models.WishlistShare
+	if err := r.db.First(&share, "token = ?", token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, fmt.Errorf("wishlist not found")
+		}
+		return nil, nil, err
+	}
+
+	var organization models.Organization
+	if err := r.db.First(&organization, "id = ?", share.OrganizationID).Error; err != nil {
+		return nil, nil, err
+	}
+
+	items, err := r.GetWishlist(organization.ID.String(), false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &organization, items, nil
+}
+
+// preloadWishlistLinks preloads the links of the wishlist items in the order they were added.
+func preloadWishlistLinks(db *gorm.DB) *gorm.DB {
+	return db.Preload("Links", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC")
+	})
+}
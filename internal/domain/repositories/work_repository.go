@@ -0,0 +1,206 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"mybooks/internal/domain/models"
+	"mybooks/pkg"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WorkRepository interface {
+	GetAllWorks(orgID string, filters map[string]interface{}) (*[]models.Work, error)
+	GetWorkByID(orgID, id string) (*models.Work, error)
+	UpdateWork(orgID string, work *models.Work) error
+	MergeWorks(orgID, targetID string, sourceIDs []string) error
+}
+
+type workRepositoryImp struct {
+	db *gorm.DB
+}
+
+// NewWorkRepository creates a new instance of the WorkRepository interface.
+//
+// It takes a *gorm.DB parameter, which represents the database connection.
+// It returns a WorkRepository pointer, which is an implementation of the WorkRepository interface.
+func NewWorkRepository(db *gorm.DB) WorkRepository {
+	return &workRepositoryImp{
+		db: db,
+	}
+}
+
+// GetAllWorks retrieves the works of an organization with their editions, ordered by title.
+//
+// The "title" filter matches any part of the work title (case-insensitive).
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+// - filters: a map of key-value pairs representing the filters to be applied.
+//
+// Returns:
+// - *[]models.Work: a pointer to a slice of models.Work objects representing the retrieved works.
+// - error: an error object if there was an issue retrieving the works.
+func (r *workRepositoryImp) GetAllWorks(orgID string, filters map[string]interface{}) (*[]models.Work, error) {
+	var works []models.Work
+	query := r.db.Preload("Editions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("published_date ASC")
+	}).Where("organization_id = ?", orgID)
+
+	if title, ok := filters["title"]; ok {
+		query = query.Where("LOWER(title) LIKE ?", fmt.Sprintf("%%%s%%", title))
+	}
+
+	if err := query.Order("title ASC").Find(&works).Error; err != nil {
+		return nil, err
+	}
+
+	return &works, nil
+}
+
+// GetWorkByID retrieves a work by its ID with its editions and their copies.
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+// - id: a string representing the work ID.
+//
+// Returns:
+// - *models.Work: a pointer to the retrieved work.
+// - error: an error object if the work was not found or there was an issue retrieving it.
+func (r *workRepositoryImp) GetWorkByID(orgID, id string) (*models.Work, error) {
+	var work models.Work
+
+	err := r.db.
+		Preload("Editions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("published_date ASC")
+		}).
+		Preload("Editions.Copies", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		First(&work, "id = ? AND organization_id = ?", id, orgID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("work not found")
+		}
+		return nil, err
+	}
+
+	return &work, nil
+}
+
+// UpdateWork renames a work or changes its author.
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+// - work: a pointer to the work with the new title and author.
+//
+// Returns:
+// - error: an error object if the work was not found or there was an issue updating it.
+func (r *workRepositoryImp) UpdateWork(orgID string, work *models.Work) error {
+	result := r.db.Model(&models.Work{}).
+		Where("id = ? AND organization_id = ?", work.ID, orgID).
+		Select("title", "author").
+		Updates(models.Work{Title: work.Title, Author: work.Author})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("work not found")
+	}
+
+	return nil
+}
+
+// MergeWorks moves the editions of the source works to the target work and deletes the sources.
+//
+// Parameters:
+// - orgID: a string representing the organization ID.
+// - targetID: the ID of the work that is kept.
+// - sourceIDs: the IDs of the works merged into the target.
+//
+// Returns:
+// - error: an error object if the target or every source was not found or there was an issue merging them.
+func (r *workRepositoryImp) MergeWorks(orgID, targetID string, sourceIDs []string) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r) // Re-throw panic after Rollback
+		}
+	}()
+
+	var target models.Work
+	if err := tx.First(&target, "id = ? AND organization_id = ?", targetID, orgID).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("work not found")
+		}
+		return err
+	}
+
+	var sources []models.Work
+	if err := tx.Where("id IN ? AND organization_id = ? AND id <> ?", sourceIDs, orgID, target.ID).Find(&sources).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if len(sources) == 0 {
+		tx.Rollback()
+		return fmt.Errorf("work not found")
+	}
+
+	for _, source := range sources {
+		if err := tx.Model(&models.Book{}).Where("work_id = ?", source.ID).Update("work_id", target.ID).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if err := tx.Delete(&source).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+// createWork creates a new work for a book, with the title and author of the book.
+func createWork(tx *gorm.DB, book *models.Book) (*models.Work, error) {
+	id, err := pkg.GenerateRandomID()
+	if err != nil {
+		return nil, err
+	}
+
+	work := models.Work{
+		ID:             id,
+		Title:          book.Title,
+		Author:         book.Author,
+		OrganizationID: book.OrganizationID,
+		UserID:         book.UserID,
+	}
+
+	if err := tx.Omit("User", "Editions").Create(&work).Error; err != nil {
+		return nil, err
+	}
+
+	return &work, nil
+}
+
+// ensureWorkExists returns "work not found" when the organization does not have the work.
+func ensureWorkExists(tx *gorm.DB, orgID string, workID uuid.UUID) error {
+	if err := tx.First(&models.Work{}, "id = ? AND organization_id = ?", workID, orgID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("work not found")
+		}
+		return err
+	}
+
+	return nil
+}
+
+// deleteEmptyWorks deletes the works of an organization that have no edition left.
+func deleteEmptyWorks(tx *gorm.DB, orgID string) error {
+	return tx.Where("organization_id = ? AND NOT EXISTS (SELECT 1 FROM books WHERE books.work_id = works.id)", orgID).Delete(&models.Work{}).Error
+}
@@ -0,0 +1,203 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"mybooks/internal/domain/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// yearReviewTopLimit is the number of genres and authors with the most books finished in a year review.
+const yearReviewTopLimit = 5
+
+type YearReviewRepository interface {
+	GetYearReview(userID, orgID, timezone string, from, to time.Time) (*models.YearReview, error)
+	ShareYearReview(share *models.YearReviewShare) error
+	UnshareYearReview(userID, orgID string, year int) error
+	GetYearReviewShare(token string) (*models.YearReviewShare, *models.Organization, error)
+}
+
+type yearReviewRepositoryImp struct {
+	db *gorm.DB
+}
+
+// NewYearReviewRepository creates a new instance of the YearReviewRepository interface.
+//
+// It takes a *gorm.DB parameter, which represents the database connection.
+// It returns a YearReviewRepository pointer, which is an implementation of the YearReviewRepository interface.
+func NewYearReviewRepository(db *gorm.DB) YearReviewRepository {
+	return &yearReviewRepositoryImp{
+		db: db,
+	}
+}
+
+// GetYearReview computes the review of the reading of a user from and before the given times, from the books of
+// an organization marked as read during the period.
+//
+// The genres and authors are counted in SQL and only those with the most books are returned. The months are
+// those of the given timezone, and only the months with books are returned. The books are in the order they
+// were read, with the rating the user gave them.
+//
+// Parameters:
+// - userID: a string representing the user ID.
+// - orgID: a string representing the organization ID.
+// - timezone: the name of the timezone of the user, such as "Europe/Paris".
+// - from: the start of the period.
+// - to: the end of the period, excluded.
+//
+// Returns:
+// - *models.YearReview: a pointer to the review, without its year, name and timezone.
+// - error: an error object if there was an issue computing the review.
+func (r *yearReviewRepositoryImp) GetYearReview(userID, orgID, timezone string, from, to time.Time) (*models.YearReview, error) {
+	review := models.YearReview{
+		Genres:  make([]models.StatsGroup, 0),
+		Authors: make([]models.StatsGroup, 0),
+		Months:  make([]models.YearReviewMonth, 0),
+		Books:   make([]models.YearReviewBook, 0),
+	}
+
+	books := func() *gorm.DB {
+		return r.db.Model(&models.Book{}).
+			Where("books.organization_id = ? AND books.read = true AND books.read_at >= ? AND books.read_at < ?", orgID, from, to)
+	}
+
+	err := books().
+		Select("books.id, books.title, books.author, books.cover, books.genre, books.pages, reviews.rating, books.read_at").
+		Joins("LEFT JOIN reviews ON reviews.book_id = books.id AND reviews.user_id = ?", userID).
+		Order("books.read_at ASC").
+		Scan(&review.Books).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = books().
+		Select("books.genre AS key, " + statsGroupSQL).
+		Where("books.genre <> ''").
+		Group("key").
+		Order("books DESC, key ASC").
+		Limit(yearReviewTopLimit).
+		Scan(&review.Genres).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = books().
+		Select("authors.name AS key, authors.id AS id, "+statsGroupSQL).
+		Joins("JOIN book_authors ON book_authors.book_id = books.id AND book_authors.role = ?", "author").
+		Joins("JOIN authors ON authors.id = book_authors.author_id").
+		Group("authors.id, authors.name").
+		Order("books DESC, key ASC").
+		Limit(yearReviewTopLimit).
+		Scan(&review.Authors).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = books().
+		Select("TO_CHAR(books.read_at AT TIME ZONE ?, 'YYYY-MM') AS month, COUNT(books.id) AS books, COALESCE(SUM(books.pages), 0) AS pages", timezone).
+		Group("month").
+		Order("month ASC").
+		Scan(&review.Months).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.Model(&models.ReadingSession{}).
+		Select("COALESCE(SUM(reading_sessions.duration), 0)").
+		Joins("JOIN books ON books.id = reading_sessions.book_id").
+		Where("reading_sessions.user_id = ? AND books.organization_id = ?", userID, orgID).
+		Where("reading_sessions.ended_at IS NOT NULL AND reading_sessions.started_at >= ? AND reading_sessions.started_at < ?", from, to).
+		Scan(&review.ReadingTime).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i, book := range review.Books {
+		review.BooksRead++
+		review.PagesRead += int64(book.Pages)
+
+		if book.Pages > 0 && (review.Longest == nil || book.Pages > review.Longest.Pages) {
+			review.Longest = &review.Books[i]
+		}
+		if book.Rating != nil && (review.HighestRated == nil || *book.Rating > *review.HighestRated.Rating) {
+			review.HighestRated = &review.Books[i]
+		}
+	}
+
+	return &review, nil
+}
+
+// ShareYearReview creates the public link of the year review of a user in an organization, or replaces the
+// token of the existing link, which disables the previous one.
+//
+// Parameters:
+// - share: a pointer to the share, with the new token.
+//
+// Returns:
+// - error: an error object if there was an issue saving the link.
+func (r *yearReviewRepositoryImp) ShareYearReview(share *models.YearReviewShare) error {
+	return r.db.Omit("User").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "year"}, {Name: "organization_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"token", "updated_at"}),
+	}).Create(share).Error
+}
+
+// UnshareYearReview removes the public link of the year review of a user in an organization.
+//
+// Parameters:
+// - userID: a string representing the user ID.
+// - orgID: a string representing the organization ID.
+// - year: the year of the review.
+//
+// Returns:
+// - error: an error object if the review was not shared or there was an issue removing the link.
+func (r *yearReviewRepositoryImp) UnshareYearReview(userID, orgID string, year int) error {
+	result := r.db.Where("user_id = ? AND organization_id = ? AND year = ?", userID, orgID, year).Delete(&models.YearReviewShare{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("year review share not found")
+	}
+
+	return nil
+}
+
+// GetYearReviewShare retrieves the public link behind a year review token, with its user, and the organization
+// the review is about. The link stops working once its user is no longer a member of the organization.
+//
+// Parameters:
+// - token: a string representing the token of the public link.
+//
+// Returns:
+// - *models.YearReviewShare: a pointer to the share, with its user.
+// - *models.Organization: a pointer to the organization of the review.
+// - error: an error object if the token was not found, its user left the organization or there was an issue
+// retrieving the share.
+func (r *yearReviewRepositoryImp) GetYearReviewShare(token string) (*models.YearReviewShare, *models.Organization, error) {
+	var share models.YearReviewShare
+	if err := r.db.Preload("User").First(&share, "token = ?", token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, fmt.Errorf("year review not found")
+		}
+		return nil, nil, err
+	}
+
+	// A user who left the organization no longer shares its year review
+	var organization models.Organization
+	err := r.db.
+		Where("EXISTS (SELECT 1 FROM organization_members WHERE organization_members.organization_id = organizations.id AND organization_members.user_id = ?)", share.UserID).
+		First(&organization, "id = ?", share.OrganizationID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, fmt.Errorf("year review not found")
+		}
+		return nil, nil, err
+	}
+
+	return &share, &organization, nil
+}
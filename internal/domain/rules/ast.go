@@ -0,0 +1,85 @@
+package rules
+
+// Logical operators joining two expressions.
+const (
+	And = "AND"
+	Or  = "OR"
+)
+
+// Comparison operators.
+const (
+	Equal          = "="
+	NotEqual       = "!="
+	Less           = "<"
+	LessOrEqual    = "<="
+	Greater        = ">"
+	GreaterOrEqual = ">="
+	Contains       = "CONTAINS"
+)
+
+type FieldType int
+
+const (
+	StringField FieldType = iota
+	NumberField
+	BooleanField
+)
+
+// Fields lists the book fields that can be used in a rule and their type.
+//
+// The "tag" and "series" fields match the name of any tag or series of the book.
+var Fields = map[string]FieldType{
+	"title":          StringField,
+	"author":         StringField,
+	"description":    StringField,
+	"genre":          StringField,
+	"isbn":           StringField,
+	"language":       StringField,
+	"published_date": StringField,
+	"tag":            StringField,
+	"series":         StringField,
+	"pages":          NumberField,
+	"read":           BooleanField,
+}
+
+// allows reports whether the operator can be used with a field of this type.
+func (t FieldType) allows(operator string) bool {
+	switch t {
+	case StringField:
+		return operator == Equal || operator == NotEqual || operator == Contains
+	case NumberField:
+		return operator != Contains
+	default:
+		return operator == Equal || operator == NotEqual
+	}
+}
+
+// Expr is a node of a parsed rule expression: a *Binary, a *Not or a *Comparison.
+type Expr interface {
+	isExpr()
+}
+
+// Binary joins two expressions with AND or OR.
+type Binary struct {
+	Operator string
+	Left     Expr
+	Right    Expr
+}
+
+// Not negates an expression.
+type Not struct {
+	Expr Expr
+}
+
+// Comparison compares a book field with a value.
+//
+// The value is a string, a float64 or a bool depending on the type of the field.
+type Comparison struct {
+	Field    string
+	Operator string
+	Value    interface{}
+}
+
+func (*Binary) isExpr()     {}
+func (*Not) isExpr()        {}
+func (*Comparison) isExpr() {}
@@ -0,0 +1,113 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenOperator
+	tokenLeftParen
+	tokenRightParen
+)
+
+type token struct {
+	kind     tokenKind
+	value    string
+	position int
+}
+
+// tokenize splits a rule expression into tokens.
+//
+// Identifiers and keywords are returned as tokenIdent, strings may be delimited by double or
+// single quotes and support backslash escapes, and operators are =, ==, !=, <, <=, > and >=.
+func tokenize(input string) ([]token, error) {
+	tokens := make([]token, 0)
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLeftParen, value: "(", position: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRightParen, value: ")", position: i})
+			i++
+		case r == '"' || r == '\'':
+			value, next, err := readString(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, value: value, position: i})
+			i = next
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, value: string(runes[start:i]), position: start})
+		case strings.ContainsRune("=!<>", r):
+			start := i
+			i++
+			if i < len(runes) && runes[i] == '=' {
+				i++
+			}
+
+			operator := string(runes[start:i])
+			if operator == "!" {
+				return nil, fmt.Errorf("unexpected \"!\" at position %d, use NOT or !=", start)
+			}
+			if operator == "==" {
+				operator = "="
+			}
+			tokens = append(tokens, token{kind: tokenOperator, value: operator, position: start})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, value: string(runes[start:i]), position: start})
+		default:
+			return nil, fmt.Errorf("unexpected character %q at position %d", r, i)
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokenEOF, position: len(runes)})
+
+	return tokens, nil
+}
+
+// readString reads a quoted string starting at the given position and returns its value
+// and the position right after the closing quote.
+func readString(runes []rune, start int) (string, int, error) {
+	quote := runes[start]
+	var builder strings.Builder
+
+	for i := start + 1; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			if i+1 < len(runes) {
+				i++
+				builder.WriteRune(runes[i])
+			}
+		case quote:
+			return builder.String(), i + 1, nil
+		default:
+			builder.WriteRune(runes[i])
+		}
+	}
+
+	return "", 0, fmt.Errorf("unterminated string starting at position %d", start)
+}
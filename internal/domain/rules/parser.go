@@ -0,0 +1,228 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxRuleLength is the maximum length of a rule expression.
+const MaxRuleLength = 1024
+
+// maxDepth is the maximum nesting of parentheses and NOT operators in a rule expression.
+const maxDepth = 32
+
+type parser struct {
+	tokens   []token
+	position int
+	depth    int
+}
+
+// Parse parses and validates a rule expression.
+//
+// The grammar is:
+//
+//	expr       := term (OR term)*
+//	term       := factor (AND factor)*
+//	factor     := NOT factor | "(" expr ")" | comparison | boolean field
+//	comparison := field operator value
+//
+// Keywords are case-insensitive. Fields and the operators allowed for each of them are listed in
+// Fields. For example: genre = "Sci-Fi" AND pages > 400 AND NOT read.
+//
+// Parameters:
+// - input: the rule expression.
+//
+// Returns:
+// - Expr: the root of the parsed expression.
+// - error: an error describing the first problem found in the expression.
+func Parse(input string) (Expr, error) {
+	if strings.TrimSpace(input) == "" {
+		return nil, fmt.Errorf("rule is empty")
+	}
+
+	if utf8.RuneCountInString(input) > MaxRuleLength {
+		return nil, fmt.Errorf("rule must be less than or equal to %d characters", MaxRuleLength)
+	}
+
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rule: %w", err)
+	}
+
+	p := &parser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("invalid rule: %w", err)
+	}
+
+	if next := p.peek(); next.kind != tokenEOF {
+		return nil, fmt.Errorf("invalid rule: unexpected %q at position %d", next.value, next.position)
+	}
+
+	return expr, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.position]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.position]
+	if t.kind != tokenEOF {
+		p.position++
+	}
+	return t
+}
+
+func (p *parser) isKeyword(keyword string) bool {
+	t := p.peek()
+	return t.kind == tokenIdent && strings.EqualFold(t.value, keyword)
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isKeyword("OR") {
+		p.next()
+
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = &Binary{Operator: Or, Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isKeyword("AND") {
+		p.next()
+
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+
+		left = &Binary{Operator: And, Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseFactor() (Expr, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+
+	if p.depth > maxDepth {
+		return nil, fmt.Errorf("expression is nested too deeply")
+	}
+
+	if p.isKeyword("NOT") {
+		p.next()
+
+		expr, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+
+		return &Not{Expr: expr}, nil
+	}
+
+	if p.peek().kind == tokenLeftParen {
+		p.next()
+
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if t := p.next(); t.kind != tokenRightParen {
+			return nil, fmt.Errorf("expected \")\" at position %d", t.position)
+		}
+
+		return expr, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (Expr, error) {
+	t := p.next()
+	if t.kind != tokenIdent {
+		return nil, fmt.Errorf("expected a field at position %d", t.position)
+	}
+
+	name := strings.ToLower(t.value)
+	fieldType, ok := Fields[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown field %q at position %d", t.value, t.position)
+	}
+
+	operatorToken := p.peek()
+	operator := ""
+	if operatorToken.kind == tokenOperator {
+		operator = operatorToken.value
+	} else if p.isKeyword("CONTAINS") {
+		operator = Contains
+	}
+
+	// A boolean field on its own means "field = true"
+	if operator == "" {
+		if fieldType != BooleanField {
+			return nil, fmt.Errorf("expected an operator after %q at position %d", t.value, operatorToken.position)
+		}
+
+		return &Comparison{Field: name, Operator: Equal, Value: true}, nil
+	}
+	p.next()
+
+	if !fieldType.allows(operator) {
+		return nil, fmt.Errorf("operator %s is not allowed for field %q at position %d", operator, t.value, operatorToken.position)
+	}
+
+	value, err := p.parseValue(fieldType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Comparison{Field: name, Operator: operator, Value: value}, nil
+}
+
+func (p *parser) parseValue(fieldType FieldType) (interface{}, error) {
+	t := p.next()
+
+	switch fieldType {
+	case StringField:
+		if t.kind != tokenString {
+			return nil, fmt.Errorf("expected a quoted string at position %d", t.position)
+		}
+		return t.value, nil
+	case NumberField:
+		if t.kind != tokenNumber {
+			return nil, fmt.Errorf("expected a number at position %d", t.position)
+		}
+
+		number, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", t.value, t.position)
+		}
+		return number, nil
+	default:
+		if t.kind == tokenIdent && (strings.EqualFold(t.value, "true") || strings.EqualFold(t.value, "false")) {
+			return strings.EqualFold(t.value, "true"), nil
+		}
+		return nil, fmt.Errorf("expected true or false at position %d", t.position)
+	}
+}
@@ -0,0 +1,251 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// format writes a parsed expression in prefix form with explicit parentheses, e.g.
+// (AND (= genre "Sci-Fi") (NOT (= read true))), so that tests can compare the shape of the tree.
+func format(expr Expr) string {
+	switch e := expr.(type) {
+	case *Binary:
+		return fmt.Sprintf("(%s %s %s)", e.Operator, format(e.Left), format(e.Right))
+	case *Not:
+		return fmt.Sprintf("(NOT %s)", format(e.Expr))
+	case *Comparison:
+		return fmt.Sprintf("(%s %s %#v)", e.Operator, e.Field, e.Value)
+	default:
+		return fmt.Sprintf("%T", expr)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "comparison",
+			input: `genre = "Sci-Fi"`,
+			want:  `(= genre "Sci-Fi")`,
+		},
+		{
+			name:  "AND binds tighter than OR",
+			input: `genre = "a" OR genre = "b" AND pages > 100`,
+			want:  `(OR (= genre "a") (AND (= genre "b") (> pages 100)))`,
+		},
+		{
+			name:  "AND binds tighter than OR on the left",
+			input: `genre = "a" AND genre = "b" OR pages > 100`,
+			want:  `(OR (AND (= genre "a") (= genre "b")) (> pages 100))`,
+		},
+		{
+			name:  "operators of the same precedence are left-associative",
+			input: `pages > 1 OR pages > 2 OR pages > 3`,
+			want:  `(OR (OR (> pages 1) (> pages 2)) (> pages 3))`,
+		},
+		{
+			name:  "parentheses override precedence",
+			input: `(genre = "a" OR genre = "b") AND pages > 100`,
+			want:  `(AND (OR (= genre "a") (= genre "b")) (> pages 100))`,
+		},
+		{
+			name:  "NOT binds tighter than AND",
+			input: `NOT read AND pages > 400`,
+			want:  `(AND (NOT (= read true)) (> pages 400))`,
+		},
+		{
+			name:  "NOT applies to a parenthesized expression",
+			input: `NOT (read OR pages < 100)`,
+			want:  `(NOT (OR (= read true) (< pages 100)))`,
+		},
+		{
+			name:  "double NOT",
+			input: `NOT NOT read`,
+			want:  `(NOT (NOT (= read true)))`,
+		},
+		{
+			name:  "keywords and fields are case-insensitive",
+			input: `not Read and TITLE contains "dune"`,
+			want:  `(AND (NOT (= read true)) (CONTAINS title "dune"))`,
+		},
+		{
+			name:  "boolean field compared with false",
+			input: `read = FALSE`,
+			want:  `(= read false)`,
+		},
+		{
+			name:  "double equals is equals",
+			input: `pages == 300`,
+			want:  `(= pages 300)`,
+		},
+		{
+			name:  "number operators",
+			input: `pages >= 10 AND pages <= 20.5 AND pages != -1`,
+			want:  `(AND (AND (>= pages 10) (<= pages 20.5)) (!= pages -1))`,
+		},
+		{
+			name:  "single quotes",
+			input: `author = 'Ursula K. Le Guin'`,
+			want:  `(= author "Ursula K. Le Guin")`,
+		},
+		{
+			name:  "other quotes inside a string",
+			input: `title CONTAINS "Ender's Game" OR title CONTAINS 'The "Hobbit"'`,
+			want:  `(OR (CONTAINS title "Ender's Game") (CONTAINS title "The \"Hobbit\""))`,
+		},
+		{
+			name:  "escaped quotes and backslashes",
+			input: `title = "say \"hi\"" OR title = 'c:\\dir'`,
+			want:  `(OR (= title "say \"hi\"") (= title "c:\\dir"))`,
+		},
+		{
+			name:  "keywords inside a string",
+			input: `title = "War AND Peace OR NOT"`,
+			want:  `(= title "War AND Peace OR NOT")`,
+		},
+		{
+			name:  "the length is counted in characters, not bytes",
+			input: `title = "` + strings.Repeat("é", MaxRuleLength-10) + `"`,
+			want:  `(= title "` + strings.Repeat("é", MaxRuleLength-10) + `")`,
+		},
+		{
+			name:  "no spaces",
+			input: `(pages>1)AND(tag="x")`,
+			want:  `(AND (> pages 1) (= tag "x"))`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expr, err := Parse(test.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", test.input, err)
+			}
+
+			if got := format(expr); got != test.want {
+				t.Errorf("Parse(%q) = %s, want %s", test.input, got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "empty",
+			input: "   ",
+			want:  "rule is empty",
+		},
+		{
+			name:  "too long",
+			input: `title = "` + strings.Repeat("a", MaxRuleLength) + `"`,
+			want:  "rule must be less than or equal to 1024 characters",
+		},
+		{
+			name:  "unknown field",
+			input: `pages > 1 AND colour = "red"`,
+			want:  `invalid rule: unknown field "colour" at position 14`,
+		},
+		{
+			name:  "missing operator",
+			input: `title "Dune"`,
+			want:  `invalid rule: expected an operator after "title" at position 6`,
+		},
+		{
+			name:  "operator not allowed for a number",
+			input: `pages CONTAINS 3`,
+			want:  `invalid rule: operator CONTAINS is not allowed for field "pages" at position 6`,
+		},
+		{
+			name:  "operator not allowed for a string",
+			input: `title > "a"`,
+			want:  `invalid rule: operator > is not allowed for field "title" at position 6`,
+		},
+		{
+			name:  "unquoted string",
+			input: `genre = fantasy`,
+			want:  "invalid rule: expected a quoted string at position 8",
+		},
+		{
+			name:  "string for a number",
+			input: `pages = "300"`,
+			want:  "invalid rule: expected a number at position 8",
+		},
+		{
+			name:  "invalid number",
+			input: `pages = 1.2.3`,
+			want:  `invalid rule: invalid number "1.2.3" at position 8`,
+		},
+		{
+			name:  "invalid boolean",
+			input: `read = yes`,
+			want:  "invalid rule: expected true or false at position 7",
+		},
+		{
+			name:  "unterminated string",
+			input: `title = "Dune`,
+			want:  "invalid rule: unterminated string starting at position 8",
+		},
+		{
+			name:  "bang without equals",
+			input: `!read`,
+			want:  `invalid rule: unexpected "!" at position 0, use NOT or !=`,
+		},
+		{
+			name:  "unexpected character",
+			input: `pages > 1 & read`,
+			want:  `invalid rule: unexpected character '&' at position 10`,
+		},
+		{
+			name:  "missing closing parenthesis",
+			input: `(read OR pages > 1`,
+			want:  `invalid rule: expected ")" at position 18`,
+		},
+		{
+			name:  "extra closing parenthesis",
+			input: `read)`,
+			want:  `invalid rule: unexpected ")" at position 4`,
+		},
+		{
+			name:  "missing operand after AND",
+			input: `read AND`,
+			want:  "invalid rule: expected a field at position 8",
+		},
+		{
+			name:  "two comparisons without a keyword",
+			input: `read pages > 1`,
+			want:  `invalid rule: unexpected "pages" at position 5`,
+		},
+		{
+			name:  "positions count characters, not bytes",
+			input: `title = "été" AND colour = "red"`,
+			want:  `invalid rule: unknown field "colour" at position 18`,
+		},
+		{
+			name:  "nested too deeply",
+			input: strings.Repeat("NOT ", maxDepth) + "read",
+			want:  "invalid rule: expression is nested too deeply",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expr, err := Parse(test.input)
+			if err == nil {
+				t.Fatalf("Parse(%q) = %s, want error %q", test.input, format(expr), test.want)
+			}
+
+			if err.Error() != test.want {
+				t.Errorf("Parse(%q) returned error %q, want %q", test.input, err.Error(), test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,336 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"mybooks/internal/domain/events"
+	"mybooks/internal/domain/models"
+	"mybooks/internal/domain/repositories"
+	"mybooks/internal/infrastructure/helpers"
+	"mybooks/pkg"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// The number of activities of a page of a feed, by default and at most.
+const (
+	activityPageDefaultLimit = 20
+	activityPageMaxLimit     = 100
+)
+
+// activityKinds maps the kinds of events to the kinds of activities they are recorded as.
+var activityKinds = map[string]string{
+	events.ReadingStarted:     models.ActivityStartedReading,
+	events.BookFinished:       models.ActivityFinished,
+	events.BookRated:          models.ActivityRated,
+	events.BookAddedToLibrary: models.ActivityAddedToLibrary,
+}
+
+type ActivityService struct {
+	repo repositories.ActivityRepository
+}
+
+// ActivityVisibilityRequest is the body of a request changing the visibility of an activity or of a kind of activities.
+type ActivityVisibilityRequest struct {
+	Visibility string `json:"visibility" validate:"required,oneof=public followers private"`
+}
+
+// NewActivityService creates a new instance of the ActivityService struct.
+//
+// It takes an ActivityRepository as a parameter and returns a pointer to an ActivityService.
+func NewActivityService(repo repositories.ActivityRepository) *ActivityService {
+	return &ActivityService{
+		repo: repo,
+	}
+}
+
+// RecordEvent records the activity matching a domain event, such as a book marked as read, for the feeds.
+// It is subscribed to the events when the server starts, and only logs its errors, since the request that
+// published the event has already succeeded.
+//
+// Parameters:
+// - event: the event.
+//
+// Returns:
+// - None.
+func (s *ActivityService) RecordEvent(event events.Event) {
+	kind, ok := activityKinds[event.Kind]
+	if !ok {
+		return
+	}
+
+	id, err := pkg.GenerateRandomID()
+	if err != nil {
+		log.Println("Error recording activity:", err)
+		return
+	}
+
+	activity := &models.Activity{
+		ID:        id,
+		Kind:      kind,
+		BookID:    event.BookID,
+		LibraryID: event.LibraryID,
+		Rating:    event.Rating,
+		UserID:    event.UserID,
+		CreatedAt: event.At,
+	}
+
+	if err := s.repo.RecordActivity(activity); err != nil {
+		log.Println("Error recording activity:", err)
+	}
+}
+
+// GetFeed retrieves a page of the feed of the user, newest first: their own activities and the activities the
+// users they follow share with their followers or publicly.
+//
+// The "limit" query parameter is the number of activities of the page, 20 by default and 100 at most, and the
+// "before" query parameter is the next cursor of the previous page.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ActivityService) GetFeed(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	before, limit, err := activityPageParams(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	activities, err := s.repo.GetFeed(user.ID.String(), before, limit+1)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, newActivityPage(*activities, limit))
+}
+
+// GetPublicActivities retrieves a page of the public activities of a public profile, newest first. It needs
+// no authentication and takes the same "limit" and "before" query parameters as the feed.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ActivityService) GetPublicActivities(c *gin.Context) {
+	before, limit, err := activityPageParams(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	activities, err := s.repo.GetPublicActivities(strings.ToLower(c.Param("handle")), before, limit+1)
+	if err != nil {
+		s.handleActivityError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newActivityPage(*activities, limit))
+}
+
+// UpdateActivity changes the visibility of an activity of the user.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ActivityService) UpdateActivity(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	activityID, err := uuid.Parse(c.Param("activityId"))
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	var request ActivityVisibilityRequest
+	if err := c.BindJSON(&request); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := pkg.ValidateModelStruct(request); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.UpdateActivityVisibility(user.ID.String(), activityID.String(), request.Visibility); err != nil {
+		s.handleActivityError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Activity updated successfully"})
+}
+
+// DeleteActivity deletes an activity of the user from the feeds.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ActivityService) DeleteActivity(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	activityID, err := uuid.Parse(c.Param("activityId"))
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := s.repo.DeleteActivity(user.ID.String(), activityID.String()); err != nil {
+		s.handleActivityError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Activity deleted successfully"})
+}
+
+// GetActivitySettings retrieves the visibility of every kind of activity of the user, the default visibility
+// for the kinds the user did not set.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ActivityService) GetActivitySettings(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	settings, err := s.repo.GetActivitySettings(user.ID.String())
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	visibilities := make(map[string]string)
+	for _, setting := range *settings {
+		visibilities[setting.Kind] = setting.Visibility
+	}
+
+	response := make([]models.ActivitySetting, 0, len(models.ActivityKinds))
+	for _, kind := range models.ActivityKinds {
+		visibility, ok := visibilities[kind]
+		if !ok {
+			visibility = models.ActivityDefaultVisibility
+		}
+		response = append(response, models.ActivitySetting{Kind: kind, Visibility: visibility})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// SetActivitySetting sets the visibility of the future activities of a kind of the user. The activities
+// already recorded keep their visibility.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ActivityService) SetActivitySetting(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	var request ActivityVisibilityRequest
+	if err := c.BindJSON(&request); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	setting := &models.ActivitySetting{
+		UserID:     user.ID,
+		Kind:       strings.ToLower(c.Param("kind")),
+		Visibility: request.Visibility,
+		User:       *user,
+	}
+
+	if err := pkg.ValidateModelStruct(setting); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.SetActivitySetting(setting); err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, setting)
+}
+
+// handleActivityError maps the errors of the activity operations to HTTP status codes.
+func (s *ActivityService) handleActivityError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		helpers.HandleError(c, err, http.StatusNotFound)
+	default:
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+	}
+}
+
+// activityPageParams reads the cursor and the number of activities of a page of a feed from the query.
+func activityPageParams(c *gin.Context) (*uuid.UUID, int, error) {
+	var before *uuid.UUID
+	if value := c.Query("before"); value != "" {
+		cursor, err := uuid.Parse(value)
+		if err != nil {
+			return nil, 0, errors.New("before must be the next cursor of the previous page")
+		}
+		before = &cursor
+	}
+
+	limit := activityPageDefaultLimit
+	if value := c.Query("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil {
+			return nil, 0, err
+		}
+		if limit < 1 || limit > activityPageMaxLimit {
+			return nil, 0, errors.New("limit must be between 1 and 100")
+		}
+	}
+
+	return before, limit, nil
+}
+
+// newActivityPage builds a page of a feed from the activities read with one more than the limit, the extra one
+// only telling there is a next page.
+func newActivityPage(activities []models.Activity, limit int) models.ActivityPage {
+	page := models.ActivityPage{Activities: activities}
+
+	if len(activities) > limit {
+		page.Activities = activities[:limit]
+		page.NextCursor = page.Activities[limit-1].ID.String()
+	}
+
+	return page
+}
@@ -64,6 +64,12 @@ func (s *AuthService) CreateUserWithCredentials(c *gin.Context) {
 
 	user.ID = id
 
+	// The public profile is only set from the profile endpoints, which check the handle
+	user.Handle = nil
+	user.DisplayName = ""
+	user.Bio = ""
+	user.Public = false
+
 	if err := pkg.ValidateModelStruct(user); err != nil {
 		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
 		return
@@ -326,10 +332,14 @@ func (s *AuthService) ValidateAuthToken(c *gin.Context) {
 
 	response := map[string]interface{}{
 		"user": map[string]interface{}{
-			"id":         user.ID,
-			"email":      user.Email,
-			"created_at": user.CreatedAt,
-			"updated_at": user.UpdatedAt,
+			"id":           user.ID,
+			"email":        user.Email,
+			"timezone":     user.Timezone,
+			"handle":       user.Handle,
+			"display_name": user.DisplayName,
+			"public":       user.Public,
+			"created_at":   user.CreatedAt,
+			"updated_at":   user.UpdatedAt,
 		},
 	}
 
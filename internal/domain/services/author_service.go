@@ -0,0 +1,242 @@
+package services
+
+import (
+	"errors"
+	"mybooks/internal/domain/models"
+	"mybooks/internal/domain/repositories"
+	"mybooks/internal/infrastructure/helpers"
+	"mybooks/pkg"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AuthorService struct {
+	repo repositories.AuthorRepository
+}
+
+// NewAuthorService creates a new instance of the AuthorService struct.
+//
+// It takes an AuthorRepository as a parameter and returns a pointer to an AuthorService.
+func NewAuthorService(repo repositories.AuthorRepository) *AuthorService {
+	return &AuthorService{
+		repo: repo,
+	}
+}
+
+// GetAllAuthors retrieves all authors of the active organization, optionally filtered by name.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *AuthorService) GetAllAuthors(c *gin.Context) {
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	filters := make(map[string]interface{})
+
+	if name := strings.TrimSpace(c.Query("name")); name != "" {
+		filters["name"] = strings.ToLower(name)
+	}
+
+	authors, err := s.repo.GetAllAuthors(orgID.String(), filters)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, authors)
+}
+
+// GetAuthorByID retrieves an author by its ID together with the books it is credited on.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *AuthorService) GetAuthorByID(c *gin.Context) {
+	id := c.Param("authorId")
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	author, err := s.repo.GetAuthorByID(orgID.String(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "author not found") {
+			helpers.HandleError(c, err, http.StatusNotFound)
+			return
+		}
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, author)
+}
+
+// UpdateAuthor renames an author or changes its sort name.
+//
+// It takes a gin.Context as a parameter and returns nothing.
+// The function binds the JSON from the request to a models.Author struct, validates it,
+// updates the author in the repository and returns a success message.
+// If another author already has the new name it returns a conflict so the client can merge them instead.
+func (s *AuthorService) UpdateAuthor(c *gin.Context) {
+	id := c.Param("authorId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	var author models.Author
+	if err := c.BindJSON(&author); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	authorID, err := uuid.Parse(id)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	author.ID = authorID
+	author.Name = strings.TrimSpace(author.Name)
+	author.SortName = strings.TrimSpace(author.SortName)
+	author.User = *user
+
+	if err := pkg.ValidateModelStruct(author); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.UpdateAuthor(orgID.String(), &author); err != nil {
+		if strings.Contains(err.Error(), "author not found") {
+			helpers.HandleError(c, err, http.StatusNotFound)
+			return
+		}
+		if strings.Contains(err.Error(), "already exists") {
+			helpers.HandleError(c, err, http.StatusConflict)
+			return
+		}
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Author updated successfully"})
+}
+
+// MergeAuthors merges the authors listed in the request body into the author in the URL.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *AuthorService) MergeAuthors(c *gin.Context) {
+	id := c.Param("authorId")
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	var body struct {
+		AuthorIDs []string `json:"author_ids" validate:"required,min=1,dive,uuid4"`
+	}
+
+	if err := c.BindJSON(&body); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := pkg.ValidateModelStruct(body); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.MergeAuthors(orgID.String(), id, body.AuthorIDs); err != nil {
+		if strings.Contains(err.Error(), "author not found") {
+			helpers.HandleError(c, err, http.StatusNotFound)
+			return
+		}
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Authors merged successfully"})
+}
+
+// SetBookAuthors replaces the authors, translators, editors and illustrators credited on a book.
+//
+// It takes a gin.Context as a parameter and returns nothing.
+// The function binds the JSON array from the request to a slice of models.AuthorCredit,
+// validates every credit, replaces the credits of the book in the repository and returns a success message.
+// At least one credit with the "author" role is required.
+func (s *AuthorService) SetBookAuthors(c *gin.Context) {
+	bookID := c.Param("bookId")
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	var credits []models.AuthorCredit
+	if err := c.BindJSON(&credits); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	hasAuthor := false
+	for i := range credits {
+		credits[i].Name = strings.TrimSpace(credits[i].Name)
+		if err := pkg.ValidateModelStruct(credits[i]); err != nil {
+			helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+			return
+		}
+
+		if credits[i].Role == "author" {
+			hasAuthor = true
+		}
+	}
+
+	if !hasAuthor {
+		helpers.HandleError(c, errors.New("at least one credit with the author role is required"), http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.SetBookAuthors(orgID.String(), bookID, credits); err != nil {
+		if strings.Contains(err.Error(), "book not found") {
+			helpers.HandleError(c, err, http.StatusNotFound)
+			return
+		}
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Book authors updated successfully"})
+}
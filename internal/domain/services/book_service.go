@@ -1,6 +1,8 @@
 package services
 
 import (
+	"errors"
+	"mybooks/internal/domain/events"
 	"mybooks/internal/domain/models"
 	"mybooks/internal/domain/repositories"
 	"mybooks/internal/infrastructure/helpers"
@@ -41,6 +43,12 @@ func (s *BookService) CreateBook(c *gin.Context) {
 		return
 	}
 
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
 	id, err := pkg.GenerateRandomID()
 	if err != nil {
 		helpers.HandleError(c, err, http.StatusInternalServerError)
@@ -53,6 +61,7 @@ func (s *BookService) CreateBook(c *gin.Context) {
 	}
 
 	book.ID = id
+	book.OrganizationID = organization.ID
 	book.UserID = user.ID
 	book.User = *user
 
@@ -79,12 +88,12 @@ func (s *BookService) CreateBook(c *gin.Context) {
 // Returns:
 // - None.
 func (s *BookService) GetAllBooks(c *gin.Context) {
-	user, err := helpers.GetUserFromContext(c)
+	organization, err := helpers.GetOrganizationFromContext(c)
 	if err != nil {
 		helpers.HandleError(c, err, http.StatusUnauthorized)
 		return
 	}
-	userID := user.ID
+	orgID := organization.ID
 
 	filters := make(map[string]interface{})
 
@@ -94,6 +103,13 @@ func (s *BookService) GetAllBooks(c *gin.Context) {
 	if author := strings.TrimSpace(c.Query("author")); author != "" {
 		filters["author"] = strings.ToLower(author)
 	}
+	if authorID := strings.TrimSpace(c.Query("author_id")); authorID != "" {
+		if _, err := uuid.Parse(authorID); err != nil {
+			helpers.HandleError(c, err, http.StatusBadRequest)
+			return
+		}
+		filters["author_id"] = authorID
+	}
 	if genre := strings.TrimSpace(c.Query("genre")); genre != "" {
 		filters["genre"] = strings.ToLower(genre)
 	}
@@ -103,6 +119,27 @@ func (s *BookService) GetAllBooks(c *gin.Context) {
 	if language := strings.TrimSpace(c.Query("language")); language != "" {
 		filters["language"] = strings.ToLower(language)
 	}
+	if tags := strings.TrimSpace(c.Query("tags")); tags != "" {
+		names := make([]string, 0)
+		seen := make(map[string]bool)
+		for _, name := range strings.Split(tags, ",") {
+			if name = pkg.NormalizeTagName(name); name != "" && !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+
+		mode := strings.ToLower(strings.TrimSpace(c.DefaultQuery("tags_mode", "and")))
+		if mode != "and" && mode != "or" {
+			helpers.HandleError(c, errors.New("tags_mode must be one of: and or"), http.StatusBadRequest)
+			return
+		}
+
+		if len(names) > 0 {
+			filters["tags"] = names
+			filters["tags_mode"] = mode
+		}
+	}
 	if read := strings.TrimSpace(c.Query("read")); read != "" {
 		readBool, err := strconv.ParseBool(read)
 		if err != nil {
@@ -111,8 +148,23 @@ func (s *BookService) GetAllBooks(c *gin.Context) {
 		}
 		filters["read"] = readBool
 	}
+	if minRating := strings.TrimSpace(c.Query("min_rating")); minRating != "" {
+		minRatingFloat, err := strconv.ParseFloat(minRating, 64)
+		if err != nil {
+			helpers.HandleError(c, err, http.StatusBadRequest)
+			return
+		}
+		filters["min_rating"] = minRatingFloat
+	}
+	if sort := strings.ToLower(strings.TrimSpace(c.Query("sort"))); sort != "" {
+		if sort != "rating" && sort != "created_at" {
+			helpers.HandleError(c, errors.New("sort must be one of: rating created_at"), http.StatusBadRequest)
+			return
+		}
+		filters["sort"] = sort
+	}
 
-	books, err := s.repo.GetAllBooks(userID.String(), filters)
+	books, err := s.repo.GetAllBooks(orgID.String(), filters)
 	if err != nil {
 		helpers.HandleError(c, err, http.StatusInternalServerError)
 		return
@@ -131,14 +183,14 @@ func (s *BookService) GetAllBooks(c *gin.Context) {
 func (s *BookService) GetBookById(c *gin.Context) {
 	id := c.Param("bookId")
 
-	user, err := helpers.GetUserFromContext(c)
+	organization, err := helpers.GetOrganizationFromContext(c)
 	if err != nil {
 		helpers.HandleError(c, err, http.StatusUnauthorized)
 		return
 	}
-	userID := user.ID
+	orgID := organization.ID
 
-	book, err := s.repo.GetBookById(userID.String(), id)
+	book, err := s.repo.GetBookById(orgID.String(), id)
 	if err != nil {
 		if strings.Contains(err.Error(), "book not found") {
 			helpers.HandleError(c, err, http.StatusNotFound)
@@ -161,14 +213,14 @@ func (s *BookService) GetBookById(c *gin.Context) {
 func (s *BookService) DeleteBook(c *gin.Context) {
 	id := c.Param("bookId")
 
-	user, err := helpers.GetUserFromContext(c)
+	organization, err := helpers.GetOrganizationFromContext(c)
 	if err != nil {
 		helpers.HandleError(c, err, http.StatusUnauthorized)
 		return
 	}
-	userID := user.ID
+	orgID := organization.ID
 
-	if err := s.repo.DeleteBook(userID.String(), id); err != nil {
+	if err := s.repo.DeleteBook(orgID.String(), id); err != nil {
 		if strings.Contains(err.Error(), "book not found") {
 			helpers.HandleError(c, err, http.StatusNotFound)
 			return
@@ -190,13 +242,13 @@ func (s *BookService) DeleteBook(c *gin.Context) {
 func (s *BookService) UpdateBook(c *gin.Context) {
 	id := c.Param("bookId")
 
-	user, err := helpers.GetUserFromContext(c)
+	organization, err := helpers.GetOrganizationFromContext(c)
 	if err != nil {
 		helpers.HandleError(c, err, http.StatusUnauthorized)
 		return
 	}
 
-	userID := user.ID
+	orgID := organization.ID
 
 	var book models.Book
 	if err := c.BindJSON(&book); err != nil {
@@ -212,7 +264,22 @@ func (s *BookService) UpdateBook(c *gin.Context) {
 
 	book.ID = bookID
 
-	if err := s.repo.UpdateBook(userID.String(), &book); err != nil {
+	// Tell whether the update finishes the book, to publish it once the book is saved
+	finished := false
+	if book.Read {
+		previous, err := s.repo.GetBookById(orgID.String(), bookID.String())
+		if err != nil {
+			if strings.Contains(err.Error(), "book not found") {
+				helpers.HandleError(c, err, http.StatusNotFound)
+				return
+			}
+			helpers.HandleError(c, err, http.StatusInternalServerError)
+			return
+		}
+		finished = !previous.Read
+	}
+
+	if err := s.repo.UpdateBook(orgID.String(), &book); err != nil {
 		if strings.Contains(err.Error(), "book not found") {
 			helpers.HandleError(c, err, http.StatusNotFound)
 			return
@@ -221,5 +288,15 @@ func (s *BookService) UpdateBook(c *gin.Context) {
 		return
 	}
 
+	if finished {
+		if user, err := helpers.GetUserFromContext(c); err == nil {
+			event := events.Event{Kind: events.BookFinished, UserID: user.ID, BookID: bookID}
+			if book.ReadAt != nil {
+				event.At = *book.ReadAt
+			}
+			events.Publish(event)
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "Book updated successfully"})
 }
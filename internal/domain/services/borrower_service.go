@@ -0,0 +1,302 @@
+package services
+
+import (
+	"mybooks/internal/domain/models"
+	"mybooks/internal/domain/repositories"
+	"mybooks/internal/infrastructure/helpers"
+	"mybooks/pkg"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type BorrowerService struct {
+	repo repositories.BorrowerRepository
+}
+
+type BorrowerResponse struct {
+	*models.Borrower
+	Stats *models.BorrowerStats `json:"stats"`
+}
+
+// NewBorrowerService creates a new instance of the BorrowerService struct.
+//
+// It takes a BorrowerRepository as a parameter and returns a pointer to a BorrowerService.
+func NewBorrowerService(repo repositories.BorrowerRepository) *BorrowerService {
+	return &BorrowerService{
+		repo: repo,
+	}
+}
+
+// CreateBorrower adds a borrower to the contact book of the active organization.
+//
+// It takes a gin.Context as a parameter and returns nothing.
+// The function generates a random ID, binds the JSON from the request to a models.Borrower struct,
+// validates the struct, creates the borrower in the repository, and returns the ID of the created borrower.
+// If the organization already has a borrower with the same name, regardless of case, it returns a conflict.
+func (s *BorrowerService) CreateBorrower(c *gin.Context) {
+	borrower := new(models.Borrower)
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	id, err := pkg.GenerateRandomID()
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	if err := c.BindJSON(borrower); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	borrower.ID = id
+	borrower.Name = strings.TrimSpace(borrower.Name)
+	borrower.OrganizationID = organization.ID
+	borrower.UserID = user.ID
+	borrower.User = *user
+
+	if err := pkg.ValidateModelStruct(borrower); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.CreateBorrower(borrower); err != nil {
+		s.handleBorrowerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, map[string]interface{}{
+		"id": borrower.ID,
+	})
+}
+
+// GetAllBorrowers retrieves the borrowers of the active organization, optionally filtered by name.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *BorrowerService) GetAllBorrowers(c *gin.Context) {
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	filters := make(map[string]interface{})
+
+	if name := pkg.NormalizeBorrowerName(c.Query("name")); name != "" {
+		filters["name"] = name
+	}
+
+	borrowers, err := s.repo.GetAllBorrowers(orgID.String(), filters)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, borrowers)
+}
+
+// GetBorrowerByID retrieves a borrower by its ID with the statistics of their loans: how many books
+// they currently hold, how many loans were overdue and how many days they take on average to return a book.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *BorrowerService) GetBorrowerByID(c *gin.Context) {
+	id := c.Param("borrowerId")
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	borrower, err := s.repo.GetBorrowerByID(orgID.String(), id)
+	if err != nil {
+		s.handleBorrowerError(c, err)
+		return
+	}
+
+	stats, err := s.repo.GetBorrowerStats(orgID.String(), id)
+	if err != nil {
+		s.handleBorrowerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, BorrowerResponse{
+		Borrower: borrower,
+		Stats:    stats,
+	})
+}
+
+// GetBorrowerLoans retrieves the loan history of a borrower, most recent first.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *BorrowerService) GetBorrowerLoans(c *gin.Context) {
+	id := c.Param("borrowerId")
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	loans, err := s.repo.GetBorrowerLoans(orgID.String(), id)
+	if err != nil {
+		s.handleBorrowerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, loans)
+}
+
+// UpdateBorrower updates the name and contact details of a borrower.
+//
+// It takes a gin.Context as a parameter and returns nothing.
+// If another borrower already has the new name it returns a conflict so the client can merge them instead.
+func (s *BorrowerService) UpdateBorrower(c *gin.Context) {
+	id := c.Param("borrowerId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	var borrower models.Borrower
+	if err := c.BindJSON(&borrower); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	borrowerID, err := uuid.Parse(id)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	borrower.ID = borrowerID
+	borrower.Name = strings.TrimSpace(borrower.Name)
+	borrower.User = *user
+
+	if err := pkg.ValidateModelStruct(borrower); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.UpdateBorrower(orgID.String(), &borrower); err != nil {
+		s.handleBorrowerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Borrower updated successfully"})
+}
+
+// DeleteBorrower deletes a borrower without loans.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *BorrowerService) DeleteBorrower(c *gin.Context) {
+	id := c.Param("borrowerId")
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	if err := s.repo.DeleteBorrower(orgID.String(), id); err != nil {
+		s.handleBorrowerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Borrower deleted successfully"})
+}
+
+// MergeBorrowers merges the borrowers listed in the request body into the borrower in the URL.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *BorrowerService) MergeBorrowers(c *gin.Context) {
+	id := c.Param("borrowerId")
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	var body struct {
+		BorrowerIDs []string `json:"borrower_ids" validate:"required,min=1,dive,uuid4"`
+	}
+
+	if err := c.BindJSON(&body); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := pkg.ValidateModelStruct(body); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.MergeBorrowers(orgID.String(), id, body.BorrowerIDs); err != nil {
+		s.handleBorrowerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Borrowers merged successfully"})
+}
+
+// handleBorrowerError maps the errors of the borrower operations to HTTP status codes.
+func (s *BorrowerService) handleBorrowerError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "borrower not found"):
+		helpers.HandleError(c, err, http.StatusNotFound)
+	case strings.Contains(err.Error(), "already exists"),
+		strings.Contains(err.Error(), "has loans"):
+		helpers.HandleError(c, err, http.StatusConflict)
+	default:
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+	}
+}
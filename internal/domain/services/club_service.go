@@ -0,0 +1,899 @@
+package services
+
+import (
+	"errors"
+	"mybooks/internal/domain/models"
+	"mybooks/internal/domain/repositories"
+	"mybooks/internal/infrastructure/helpers"
+	"mybooks/pkg"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ClubService struct {
+	repo repositories.ClubRepository
+}
+
+// ClubResponse is a book club with the membership of the user in it.
+type ClubResponse struct {
+	models.Club
+	Page            int  `json:"page"`
+	ProgressVisible bool `json:"progress_visible"`
+}
+
+// ClubProgressRequest is the body of a request recording the progress of a member in the current book of a club.
+type ClubProgressRequest struct {
+	Page            *int  `json:"page" validate:"required,min=0"`
+	ProgressVisible *bool `json:"progress_visible"`
+}
+
+// NewClubService creates a new instance of the ClubService struct.
+//
+// It takes a ClubRepository as a parameter and returns a pointer to a ClubService.
+func NewClubService(repo repositories.ClubRepository) *ClubService {
+	return &ClubService{
+		repo: repo,
+	}
+}
+
+// CreateClub creates a book club with a name and a description. The user creating it is its owner and gets
+// the join code to share with the other members.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ClubService) CreateClub(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	var body models.Club
+	if err := c.BindJSON(&body); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	id, err := pkg.GenerateRandomID()
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	code, err := helpers.GenerateSecureToken()
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	club := &models.Club{
+		ID:          id,
+		Name:        strings.TrimSpace(body.Name),
+		Description: strings.TrimSpace(body.Description),
+		JoinCode:    code,
+		UserID:      user.ID,
+		User:        *user,
+	}
+
+	if err := pkg.ValidateModelStruct(club); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.CreateClub(club); err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	club.Role = "owner"
+	club.Members = 1
+
+	c.JSON(http.StatusCreated, club)
+}
+
+// GetClubs retrieves the book clubs of the user.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ClubService) GetClubs(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	clubs, err := s.repo.GetClubs(user.ID.String())
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, clubs)
+}
+
+// GetClub retrieves a book club of the user with their own progress in its current book.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ClubService) GetClub(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	ids, err := clubPathIDs(c, "clubId")
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	member, err := s.repo.GetClubMembership(user.ID.String(), ids[0])
+	if err != nil {
+		s.handleClubError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ClubResponse{
+		Club:            member.Club,
+		Page:            clubMemberPage(member),
+		ProgressVisible: member.ProgressVisible,
+	})
+}
+
+// UpdateClub updates the name and description of a book club. Only an owner can update it.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ClubService) UpdateClub(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	ids, err := clubPathIDs(c, "clubId")
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Name        string `json:"name" validate:"required,min=1,max=100"`
+		Description string `json:"description" validate:"max=500"`
+	}
+
+	if err := c.BindJSON(&body); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	body.Name = strings.TrimSpace(body.Name)
+	body.Description = strings.TrimSpace(body.Description)
+
+	if err := pkg.ValidateModelStruct(body); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	club := models.Club{ID: uuid.MustParse(ids[0]), Name: body.Name, Description: body.Description}
+
+	if err := s.repo.UpdateClub(user.ID.String(), &club); err != nil {
+		s.handleClubError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Club updated successfully"})
+}
+
+// DeleteClub deletes a book club with its members, its schedule and its discussions. Only an owner can delete it.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ClubService) DeleteClub(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	ids, err := clubPathIDs(c, "clubId")
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := s.repo.DeleteClub(user.ID.String(), ids[0]); err != nil {
+		s.handleClubError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Club deleted successfully"})
+}
+
+// SetClubBook makes a book of the active organization of the user the current book of a book club. Only an
+// owner can set it, and the club then starts over: the schedule and discussions of the previous book are
+// deleted and the progress of every member goes back to the first page.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ClubService) SetClubBook(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	ids, err := clubPathIDs(c, "clubId")
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		BookID string `json:"book_id" validate:"required,uuid"`
+	}
+
+	if err := c.BindJSON(&body); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := pkg.ValidateModelStruct(body); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.SetClubBook(user.ID.String(), orgID.String(), ids[0], body.BookID); err != nil {
+		s.handleClubError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Club book updated successfully"})
+}
+
+// ResetJoinCode replaces the join code of a book club, so the previous one no longer lets anyone join.
+// Only an owner can reset it.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ClubService) ResetJoinCode(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	ids, err := clubPathIDs(c, "clubId")
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	code, err := helpers.GenerateSecureToken()
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	if err := s.repo.ResetJoinCode(user.ID.String(), ids[0], code); err != nil {
+		s.handleClubError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"join_code": code})
+}
+
+// JoinClub makes the user a member of the book club with the join code given in the body.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ClubService) JoinClub(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	var body struct {
+		Code string `json:"code" validate:"required,max=100"`
+	}
+
+	if err := c.BindJSON(&body); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := pkg.ValidateModelStruct(body); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	club, err := s.repo.JoinClub(user, strings.TrimSpace(body.Code))
+	if err != nil {
+		s.handleClubError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, club)
+}
+
+// GetClubMembers retrieves the members of a book club. The progress of the members who hide it is only shown
+// to themselves.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ClubService) GetClubMembers(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	ids, err := clubPathIDs(c, "clubId")
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	members, err := s.repo.GetClubMembers(user.ID.String(), ids[0])
+	if err != nil {
+		s.handleClubError(c, err)
+		return
+	}
+
+	for i := range *members {
+		member := &(*members)[i]
+		if !member.ProgressVisible && member.UserID != user.ID {
+			member.Page = nil
+			member.ProgressUpdatedAt = nil
+		}
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// UpdateClubMember changes the role of a member of a book club to "owner" or "member".
+//
+// Only an owner can change roles, and demoting the last owner is rejected with a conflict.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ClubService) UpdateClubMember(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	ids, err := clubPathIDs(c, "clubId", "userId")
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Role string `json:"role" validate:"required,oneof=owner member"`
+	}
+
+	if err := c.BindJSON(&body); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := pkg.ValidateModelStruct(body); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.UpdateClubMember(user.ID.String(), ids[0], ids[1], body.Role); err != nil {
+		s.handleClubError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member updated successfully"})
+}
+
+// RemoveClubMember removes a member from a book club with their posts.
+//
+// An owner can remove any member, and any member can remove themselves to leave the club.
+// Removing the last owner is rejected with a conflict.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ClubService) RemoveClubMember(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	ids, err := clubPathIDs(c, "clubId", "userId")
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := s.repo.RemoveClubMember(user.ID.String(), ids[0], ids[1]); err != nil {
+		s.handleClubError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+}
+
+// UpdateClubProgress records the last page of the current book of a book club the user read, and optionally
+// whether the other members see it. The progress unlocks the sections of the schedule and the posts of their
+// discussions.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ClubService) UpdateClubProgress(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	ids, err := clubPathIDs(c, "clubId")
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	var request ClubProgressRequest
+	if err := c.BindJSON(&request); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := pkg.ValidateModelStruct(request); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.UpdateClubProgress(user.ID.String(), ids[0], *request.Page, request.ProgressVisible); err != nil {
+		s.handleClubError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Progress updated successfully"})
+}
+
+// GetClubSections retrieves the reading schedule of a book club, telling for each section whether the user
+// reached it and finished it.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ClubService) GetClubSections(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	ids, err := clubPathIDs(c, "clubId")
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	member, err := s.repo.GetClubMembership(user.ID.String(), ids[0])
+	if err != nil {
+		s.handleClubError(c, err)
+		return
+	}
+
+	sections, err := s.repo.GetClubSections(ids[0])
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	page := clubMemberPage(member)
+	for i := range *sections {
+		section := &(*sections)[i]
+		section.Unlocked = page >= section.StartPage
+		section.Read = page >= section.EndPage
+	}
+
+	c.JSON(http.StatusOK, sections)
+}
+
+// CreateClubSection adds a section to the reading schedule of a book club: a title, the pages of the current
+// book it covers and the dates it is read between. Only an owner can add it.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ClubService) CreateClubSection(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	ids, err := clubPathIDs(c, "clubId")
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	section := new(models.ClubSection)
+	if err := c.BindJSON(section); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	id, err := pkg.GenerateRandomID()
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	section.ID = id
+	section.ClubID = uuid.MustParse(ids[0])
+	section.Title = strings.TrimSpace(section.Title)
+
+	if err := validateClubSection(section); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.CreateClubSection(user.ID.String(), section); err != nil {
+		s.handleClubError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, section)
+}
+
+// UpdateClubSection updates the title, pages and dates of a section of the reading schedule of a book club.
+// Only an owner can update it.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ClubService) UpdateClubSection(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	ids, err := clubPathIDs(c, "clubId", "sectionId")
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	section := new(models.ClubSection)
+	if err := c.BindJSON(section); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	section.ClubID = uuid.MustParse(ids[0])
+	section.ID = uuid.MustParse(ids[1])
+	section.Title = strings.TrimSpace(section.Title)
+
+	if err := validateClubSection(section); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.UpdateClubSection(user.ID.String(), section); err != nil {
+		s.handleClubError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Section updated successfully"})
+}
+
+// DeleteClubSection deletes a section of the reading schedule of a book club with its discussion. Only an
+// owner can delete it.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ClubService) DeleteClubSection(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	ids, err := clubPathIDs(c, "clubId", "sectionId")
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := s.repo.DeleteClubSection(user.ID.String(), ids[0], ids[1]); err != nil {
+		s.handleClubError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Section deleted successfully"})
+}
+
+// GetClubPosts retrieves the discussion of a section of the reading schedule of a book club, oldest first.
+//
+// A post written by a member further in the book than the user is a spoiler, returned without its body, until
+// the user reads as far as its author did when writing it or finishes the section. The user always sees their
+// own posts.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ClubService) GetClubPosts(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	ids, err := clubPathIDs(c, "clubId", "sectionId")
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	member, err := s.repo.GetClubMembership(user.ID.String(), ids[0])
+	if err != nil {
+		s.handleClubError(c, err)
+		return
+	}
+
+	section, err := s.repo.GetClubSection(ids[0], ids[1])
+	if err != nil {
+		s.handleClubError(c, err)
+		return
+	}
+
+	posts, err := s.repo.GetClubPosts(section.ID.String())
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	gateClubPosts(*posts, section, member)
+
+	c.JSON(http.StatusOK, posts)
+}
+
+// CreateClubPost adds a post to the discussion of a section of the reading schedule of a book club. The user
+// must have reached the section, and the post remembers how far they read to hide it from the members who
+// did not read as far.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ClubService) CreateClubPost(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	ids, err := clubPathIDs(c, "clubId", "sectionId")
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	post := new(models.ClubPost)
+	if err := c.BindJSON(post); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	member, err := s.repo.GetClubMembership(user.ID.String(), ids[0])
+	if err != nil {
+		s.handleClubError(c, err)
+		return
+	}
+
+	section, err := s.repo.GetClubSection(ids[0], ids[1])
+	if err != nil {
+		s.handleClubError(c, err)
+		return
+	}
+
+	page := clubMemberPage(member)
+	if page < section.StartPage {
+		helpers.HandleError(c, errors.New("you have not reached this section yet"), http.StatusForbidden)
+		return
+	}
+
+	id, err := pkg.GenerateRandomID()
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	*post = models.ClubPost{
+		ID:          id,
+		SectionID:   section.ID,
+		Body:        strings.TrimSpace(post.Body),
+		Page:        page,
+		UserID:      user.ID,
+		User:        *user,
+		Handle:      user.Handle,
+		DisplayName: user.DisplayName,
+	}
+
+	if err := pkg.ValidateModelStruct(post); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.CreateClubPost(post); err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusCreated, post)
+}
+
+// DeleteClubPost deletes a post of the discussion of a section of a book club. Authors delete their own posts
+// and owners delete any post of their club.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ClubService) DeleteClubPost(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	ids, err := clubPathIDs(c, "clubId", "sectionId", "postId")
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := s.repo.DeleteClubPost(user.ID.String(), ids[0], ids[1], ids[2]); err != nil {
+		s.handleClubError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Post deleted successfully"})
+}
+
+// handleClubError maps the errors of the book club operations to HTTP status codes.
+func (s *ClubService) handleClubError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		helpers.HandleError(c, err, http.StatusNotFound)
+	case strings.Contains(err.Error(), "insufficient club permissions"):
+		helpers.HandleError(c, err, http.StatusForbidden)
+	case strings.Contains(err.Error(), "at least one owner"), strings.Contains(err.Error(), "no current book"):
+		helpers.HandleError(c, err, http.StatusConflict)
+	case strings.Contains(err.Error(), "beyond the last page"):
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+	case strings.Contains(err.Error(), "invalid join code"):
+		helpers.HandleError(c, err, http.StatusBadRequest)
+	default:
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+	}
+}
+
+// clubPathIDs reads the IDs of the path of a book club request, in the order of their names.
+func clubPathIDs(c *gin.Context, names ...string) ([]string, error) {
+	ids := make([]string, 0, len(names))
+
+	for _, name := range names {
+		id, err := uuid.Parse(c.Param(name))
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id.String())
+	}
+
+	return ids, nil
+}
+
+// clubMemberPage returns the last page of the current book of a club a member read.
+func clubMemberPage(member *models.ClubMember) int {
+	if member.Page == nil {
+		return 0
+	}
+
+	return *member.Page
+}
+
+// validateClubSection validates a section of the schedule of a club, which cannot end before it starts.
+func validateClubSection(section *models.ClubSection) error {
+	if err := pkg.ValidateModelStruct(section); err != nil {
+		return err
+	}
+
+	// Dates in the YYYY-MM-DD format compare as strings
+	if section.EndsOn < section.StartsOn {
+		return errors.New("ends_on must not be before starts_on")
+	}
+
+	return nil
+}
+
+// gateClubPosts hides the body of the posts of a section a member cannot read yet without spoilers: the posts of
+// the other members written further in the book than the member read, unless the member finished the section.
+func gateClubPosts(posts []models.ClubPost, section *models.ClubSection, member *models.ClubMember) {
+	page := clubMemberPage(member)
+
+	for i := range posts {
+		post := &posts[i]
+		if post.UserID == member.UserID {
+			continue
+		}
+
+		if page < min(post.Page, section.EndPage) {
+			post.Body = ""
+			post.Spoiler = true
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"mybooks/internal/domain/models"
+	"mybooks/internal/domain/repositories"
+	"mybooks/internal/infrastructure/helpers"
+	"mybooks/pkg"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CopyService struct {
+	repo repositories.CopyRepository
+}
+
+// NewCopyService creates a new instance of the CopyService struct.
+//
+// It takes a CopyRepository as a parameter and returns a pointer to a CopyService.
+func NewCopyService(repo repositories.CopyRepository) *CopyService {
+	return &CopyService{
+		repo: repo,
+	}
+}
+
+// CreateCopy adds a physical copy to a book of the active organization.
+//
+// It takes a gin.Context as a parameter and returns nothing.
+// The function generates a random ID, binds the JSON from the request to a models.Copy struct,
+// validates the struct, creates the copy in the repository, and returns the ID of the created copy.
+func (s *CopyService) CreateCopy(c *gin.Context) {
+	bookID := c.Param("bookId")
+	bookCopy := new(models.Copy)
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	id, err := pkg.GenerateRandomID()
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	if err := c.BindJSON(bookCopy); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	bookUUID, err := uuid.Parse(bookID)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	bookCopy.ID = id
+	bookCopy.BookID = bookUUID
+	bookCopy.Currency = strings.ToUpper(bookCopy.Currency)
+	bookCopy.ValueCurrency = strings.ToUpper(bookCopy.ValueCurrency)
+	bookCopy.OrganizationID = organization.ID
+	bookCopy.UserID = user.ID
+	bookCopy.User = *user
+
+	if err := pkg.ValidateModelStruct(bookCopy); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.CreateCopy(bookCopy); err != nil {
+		s.handleCopyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, map[string]interface{}{
+		"id": bookCopy.ID,
+	})
+}
+
+// GetBookCopies retrieves the copies of a book of the active organization.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *CopyService) GetBookCopies(c *gin.Context) {
+	bookID := c.Param("bookId")
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	copies, err := s.repo.GetBookCopies(orgID.String(), bookID)
+	if err != nil {
+		s.handleCopyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, copies)
+}
+
+// UpdateCopy updates the condition, location, library, acquisition and valuation details of a copy.
+//
+// It takes a gin.Context as a parameter and returns nothing.
+// A null library ID takes the copy out of its library.
+func (s *CopyService) UpdateCopy(c *gin.Context) {
+	id := c.Param("copyId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	var bookCopy models.Copy
+	if err := c.BindJSON(&bookCopy); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	copyID, err := uuid.Parse(id)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	bookCopy.ID = copyID
+	bookCopy.Currency = strings.ToUpper(bookCopy.Currency)
+	bookCopy.ValueCurrency = strings.ToUpper(bookCopy.ValueCurrency)
+	bookCopy.User = *user
+
+	if err := pkg.ValidateModelStruct(bookCopy); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.UpdateCopy(userID.String(), orgID.String(), &bookCopy); err != nil {
+		s.handleCopyError(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// DeleteCopy deletes a copy that is not lent.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *CopyService) DeleteCopy(c *gin.Context) {
+	id := c.Param("copyId")
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	if err := s.repo.DeleteCopy(orgID.String(), id); err != nil {
+		s.handleCopyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Copy deleted successfully"})
+}
+
+// handleCopyError maps the errors of the copy operations to HTTP status codes.
+func (s *CopyService) handleCopyError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		helpers.HandleError(c, err, http.StatusNotFound)
+	case strings.Contains(err.Error(), "insufficient library permissions"):
+		helpers.HandleError(c, err, http.StatusForbidden)
+	case strings.Contains(err.Error(), "smart library"),
+		strings.Contains(err.Error(), "on loan"),
+		strings.Contains(err.Error(), "last copy"):
+		helpers.HandleError(c, err, http.StatusConflict)
+	default:
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"errors"
+	"mybooks/internal/domain/models"
+	"mybooks/internal/domain/repositories"
+	"mybooks/internal/infrastructure/helpers"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type FollowService struct {
+	repo repositories.FollowRepository
+}
+
+// NewFollowService creates a new instance of the FollowService struct.
+//
+// It takes a FollowRepository as a parameter and returns a pointer to a FollowService.
+func NewFollowService(repo repositories.FollowRepository) *FollowService {
+	return &FollowService{
+		repo: repo,
+	}
+}
+
+// Follow makes the user follow the user with a handle. Following a public profile is accepted at once, while
+// following a private one stays pending until its user approves it. Following a user already followed
+// returns the status of the existing follow.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *FollowService) Follow(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	followed, err := s.repo.GetUserByHandle(strings.ToLower(c.Param("handle")))
+	if err != nil {
+		s.handleFollowError(c, err)
+		return
+	}
+
+	if followed.ID == user.ID {
+		helpers.HandleError(c, errors.New("you cannot follow yourself"), http.StatusUnprocessableEntity)
+		return
+	}
+
+	follow := &models.Follow{
+		FollowerID: user.ID,
+		FollowedID: followed.ID,
+		Status:     models.FollowStatusPending,
+	}
+	if followed.Public {
+		follow.Status = models.FollowStatusAccepted
+	}
+
+	if err := s.repo.Follow(follow); err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": follow.Status})
+}
+
+// Unfollow stops the user from following the user with a handle, or cancels their pending follow request.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *FollowService) Unfollow(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	followed, err := s.repo.GetUserByHandle(strings.ToLower(c.Param("handle")))
+	if err != nil {
+		s.handleFollowError(c, err)
+		return
+	}
+
+	if err := s.repo.Unfollow(user.ID.String(), followed.ID.String()); err != nil {
+		s.handleFollowError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User unfollowed successfully"})
+}
+
+// GetFollowers retrieves the users following the user, or asking to. The "status" query parameter restricts
+// them to the "accepted" or "pending" ones.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *FollowService) GetFollowers(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	filters, err := followFilters(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	followers, err := s.repo.GetFollowers(user.ID.String(), filters)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, followers)
+}
+
+// GetFollowing retrieves the users the user follows, or asked to. The "status" query parameter restricts
+// them to the "accepted" or "pending" ones.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *FollowService) GetFollowing(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	filters, err := followFilters(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	following, err := s.repo.GetFollowing(user.ID.String(), filters)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, following)
+}
+
+// ApproveFollower accepts the pending follow request of a user, who then sees the activities of the user
+// shared with followers.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *FollowService) ApproveFollower(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	followerID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := s.repo.ApproveFollower(user.ID.String(), followerID.String()); err != nil {
+		s.handleFollowError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Follower approved successfully"})
+}
+
+// RemoveFollower removes a follower of the user, or declines their pending follow request.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *FollowService) RemoveFollower(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	followerID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := s.repo.RemoveFollower(user.ID.String(), followerID.String()); err != nil {
+		s.handleFollowError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Follower removed successfully"})
+}
+
+// handleFollowError maps the errors of the follow operations to HTTP status codes.
+func (s *FollowService) handleFollowError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		helpers.HandleError(c, err, http.StatusNotFound)
+	default:
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+	}
+}
+
+// followFilters reads the status filter of the followers and followed users from the query.
+func followFilters(c *gin.Context) (map[string]interface{}, error) {
+	filters := make(map[string]interface{})
+
+	switch status := c.Query("status"); status {
+	case "":
+	case models.FollowStatusAccepted, models.FollowStatusPending:
+		filters["status"] = status
+	default:
+		return nil, errors.New("status must be one of: accepted pending")
+	}
+
+	return filters, nil
+}
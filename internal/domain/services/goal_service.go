@@ -0,0 +1,369 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"mybooks/internal/domain/models"
+	"mybooks/internal/domain/repositories"
+	"mybooks/internal/infrastructure/helpers"
+	"mybooks/pkg"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GoalService struct {
+	repo repositories.GoalRepository
+}
+
+// NewGoalService creates a new instance of the GoalService struct.
+//
+// It takes a GoalRepository as a parameter and returns a pointer to a GoalService.
+func NewGoalService(repo repositories.GoalRepository) *GoalService {
+	return &GoalService{
+		repo: repo,
+	}
+}
+
+// SetGoal sets the number of books or pages the user wants to read in a year, counting the books of the
+// active organization marked as read.
+//
+// It takes a gin.Context as a parameter and returns nothing.
+// The function binds the JSON from the request to a models.ReadingGoal struct with the "kind" ("books" or
+// "pages") and the "target", validates it, saves it, replacing the previous goal of the same kind for the
+// year, and returns it.
+func (s *GoalService) SetGoal(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	id, err := pkg.GenerateRandomID()
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	goal := new(models.ReadingGoal)
+	if err := c.BindJSON(goal); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	goal.ID = id
+	goal.Year = year
+	goal.Kind = strings.ToLower(strings.TrimSpace(goal.Kind))
+	goal.OrganizationID = orgID
+	goal.UserID = user.ID
+	goal.User = *user
+
+	if err := pkg.ValidateModelStruct(goal); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.SetGoal(goal); err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, goal)
+}
+
+// GetGoals retrieves the reading goals of the user in the active organization, most recent year first.
+// The "year" query parameter restricts the goals to a year.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *GoalService) GetGoals(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	filters := make(map[string]interface{})
+
+	if value := c.Query("year"); value != "" {
+		year, err := strconv.Atoi(value)
+		if err != nil {
+			helpers.HandleError(c, err, http.StatusBadRequest)
+			return
+		}
+		filters["year"] = year
+	}
+
+	goals, err := s.repo.GetGoals(userID.String(), orgID.String(), filters)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, goals)
+}
+
+// DeleteGoal deletes a reading goal of the user for a year.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *GoalService) DeleteGoal(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := s.repo.DeleteGoal(userID.String(), orgID.String(), year, strings.ToLower(c.Param("kind"))); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			helpers.HandleError(c, err, http.StatusNotFound)
+			return
+		}
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Goal deleted successfully"})
+}
+
+// GetYearSummary sums up the reading of the user in a year: the progress of the goals with the projection
+// at the current pace, the books of the active organization finished during the year, the time spent in
+// reading sessions, the days read on and the reading streaks.
+//
+// The year, the days and the streaks follow the timezone of the user.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *GoalService) GetYearSummary(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if year < 1900 || year > 9999 {
+		helpers.HandleError(c, errors.New("year must be between 1900 and 9999"), http.StatusBadRequest)
+		return
+	}
+
+	location := userLocation(user)
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, location)
+	end := start.AddDate(1, 0, 0)
+	now := time.Now().In(location)
+
+	goals, err := s.repo.GetGoals(userID.String(), orgID.String(), map[string]interface{}{"year": year})
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	books, err := s.repo.GetReadBooks(orgID.String(), start, end)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	readingTime, err := s.repo.GetReadingTime(userID.String(), orgID.String(), start, end)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	dates, err := s.repo.GetReadingDates(userID.String(), orgID.String(), location.String(), end)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	summary := models.YearSummary{
+		Year:        year,
+		Timezone:    location.String(),
+		Goals:       make([]models.GoalProgress, 0),
+		BooksRead:   int64(len(*books)),
+		ReadingTime: readingTime,
+		Books:       *books,
+	}
+
+	for _, book := range *books {
+		summary.PagesRead += int64(book.Pages)
+	}
+
+	for _, goal := range *goals {
+		progress := summary.BooksRead
+		if goal.Kind == "pages" {
+			progress = summary.PagesRead
+		}
+		summary.Goals = append(summary.Goals, goalProgress(goal, progress, start, end, now))
+	}
+
+	for _, date := range dates {
+		if date >= start.Format(time.DateOnly) {
+			summary.DaysRead++
+		}
+	}
+
+	today := now
+	if !now.Before(end) {
+		today = end.AddDate(0, 0, -1)
+	}
+	summary.Streak = readingStreak(dates, start, today)
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// goalProgress computes where a goal stands at a time of its year. The expected progress is the share of the
+// target matching the share of the year elapsed, the projection the progress at the end of the year at the
+// pace so far, and the needed weekly pace what is left to read divided by the remaining weeks.
+func goalProgress(goal models.ReadingGoal, progress int64, start, end, now time.Time) models.GoalProgress {
+	elapsed := 0.0
+	switch {
+	case !now.Before(end):
+		elapsed = 1
+	case now.After(start):
+		elapsed = now.Sub(start).Seconds() / end.Sub(start).Seconds()
+	}
+
+	result := models.GoalProgress{
+		Kind:     goal.Kind,
+		Target:   goal.Target,
+		Progress: progress,
+		Percent:  roundAmount(float64(progress) / float64(goal.Target) * 100),
+		Expected: roundAmount(float64(goal.Target) * elapsed),
+	}
+
+	if elapsed > 0 {
+		result.Projected = roundAmount(float64(progress) / elapsed)
+	}
+
+	remaining := float64(goal.Target) - float64(progress)
+	if remaining > 0 && now.Before(end) {
+		from := now
+		if now.Before(start) {
+			from = start
+		}
+		weeks := math.Max(end.Sub(from).Hours()/(24*7), 1)
+		result.NeededPerWeek = roundAmount(remaining / weeks)
+	}
+
+	switch {
+	case progress >= int64(goal.Target):
+		result.Status = models.GoalStatusAchieved
+	case !now.Before(end):
+		result.Status = models.GoalStatusMissed
+	case elapsed == 0:
+		result.Status = models.GoalStatusNotStarted
+	case float64(progress) >= math.Floor(float64(goal.Target)*elapsed):
+		result.Status = models.GoalStatusOnTrack
+	default:
+		result.Status = models.GoalStatusBehind
+	}
+
+	return result
+}
+
+// readingStreak computes the reading streaks from the days read on, as YYYY-MM-DD dates in chronological order.
+// The current streak ends on the given day, or the day before when there was no reading on that day yet, and
+// the longest streak is the longest one ending from the start of the year on.
+func readingStreak(dates []string, start, today time.Time) models.ReadingStreak {
+	streak := models.ReadingStreak{}
+	todayDate := today.Format(time.DateOnly)
+
+	run := 0
+	var previous time.Time
+	for _, value := range dates {
+		if value > todayDate {
+			break
+		}
+
+		date, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			continue
+		}
+
+		if run > 0 && date.Equal(previous.AddDate(0, 0, 1)) {
+			run++
+		} else {
+			run = 1
+		}
+		previous = date
+
+		if value >= start.Format(time.DateOnly) {
+			streak.Longest = max(streak.Longest, min(run, daysSince(start, date)+1))
+		}
+		streak.LastReadOn = value
+	}
+
+	yesterday := today.AddDate(0, 0, -1).Format(time.DateOnly)
+	if streak.LastReadOn == todayDate || streak.LastReadOn == yesterday {
+		streak.Current = run
+	}
+
+	return streak
+}
+
+// daysSince returns the number of days from the first day of a year to a date of that year.
+func daysSince(start, date time.Time) int {
+	first := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	return int(date.Sub(first).Hours() / 24)
+}
@@ -0,0 +1,254 @@
+package services
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"mybooks/internal/domain/models"
+	"mybooks/internal/domain/repositories"
+	"mybooks/internal/infrastructure/helpers"
+	"mybooks/pkg"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type HoldService struct {
+	repo repositories.HoldRepository
+}
+
+type BookHoldResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	Email     string     `json:"email"`
+	Status    string     `json:"status"`
+	Position  int        `json:"position"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// NewHoldService creates a new instance of HoldService.
+//
+// Parameters:
+// - repo: The HoldRepository implementation used by the service.
+//
+// Returns:
+// - *HoldService: A pointer to the newly created HoldService instance.
+func NewHoldService(repo repositories.HoldRepository) *HoldService {
+	return &HoldService{
+		repo: repo,
+	}
+}
+
+// CreateHold places the user in the queue of a borrowed book of a library shared with them.
+//
+// It takes a pointer to a gin.Context as a parameter and returns nothing.
+// The request body holds the IDs of the book and of the shared library it is in. When the book is
+// returned and the user is first in the queue, they are notified by email and the book is kept for them.
+func (s *HoldService) CreateHold(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	id, err := pkg.GenerateRandomID()
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	var body struct {
+		BookID    string `json:"book_id" validate:"required,uuid4"`
+		LibraryID string `json:"library_id" validate:"required,uuid4"`
+	}
+
+	if err := c.BindJSON(&body); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := pkg.ValidateModelStruct(body); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	hold := &models.Hold{
+		ID:             id,
+		BookID:         body.BookID,
+		Status:         models.HoldStatusWaiting,
+		OrganizationID: organization.ID,
+		UserID:         user.ID,
+	}
+
+	if err := s.repo.CreateHold(body.LibraryID, hold); err != nil {
+		s.handleHoldError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, map[string]interface{}{
+		"id": hold.ID,
+	})
+}
+
+// GetHolds retrieves the holds placed by the user with their position in the queue.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None
+func (s *HoldService) GetHolds(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	holds, err := s.repo.GetHolds(userID.String())
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, holds)
+}
+
+// GetBookHolds retrieves the queue of a book of the active organization.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None
+func (s *HoldService) GetBookHolds(c *gin.Context) {
+	bookID := c.Param("bookId")
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	holds, err := s.repo.GetBookHolds(orgID.String(), bookID)
+	if err != nil {
+		s.handleHoldError(c, err)
+		return
+	}
+
+	response := make([]BookHoldResponse, 0)
+	for _, hold := range *holds {
+		response = append(response, BookHoldResponse{
+			ID:        hold.ID,
+			UserID:    hold.UserID,
+			Email:     hold.User.Email,
+			Status:    hold.Status,
+			Position:  hold.Position,
+			ExpiresAt: hold.ExpiresAt,
+			CreatedAt: hold.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CancelHold removes the user from the queue of a book.
+//
+// When the cancelled hold was ready, the next user in the queue is notified.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None
+func (s *HoldService) CancelHold(c *gin.Context) {
+	holdID := c.Param("holdId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	next, err := s.repo.CancelHold(userID.String(), holdID)
+	if err != nil {
+		s.handleHoldError(c, err)
+		return
+	}
+
+	notifyHoldReady(next)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Hold cancelled successfully"})
+}
+
+// ExpireHolds expires the holds that were not picked up in time every interval and notifies the
+// next users in the queues. It blocks, so it is meant to run in its own goroutine.
+//
+// Parameters:
+// - interval: the time between two runs.
+//
+// Returns:
+// - None
+func (s *HoldService) ExpireHolds(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		holds, err := s.repo.ExpireHolds()
+		if err != nil {
+			log.Println("Error expiring holds:", err)
+		}
+
+		for i := range holds {
+			notifyHoldReady(&holds[i])
+		}
+	}
+}
+
+// handleHoldError maps the errors of the hold operations to HTTP status codes.
+func (s *HoldService) handleHoldError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		helpers.HandleError(c, err, http.StatusNotFound)
+	case strings.Contains(err.Error(), "insufficient library permissions"):
+		helpers.HandleError(c, err, http.StatusForbidden)
+	case strings.Contains(err.Error(), "already borrowed"),
+		strings.Contains(err.Error(), "no copies"),
+		strings.Contains(err.Error(), "already on hold"),
+		strings.Contains(err.Error(), "book is available"),
+		strings.Contains(err.Error(), "your own organization"),
+		strings.Contains(err.Error(), "hold is not"):
+		helpers.HandleError(c, err, http.StatusConflict)
+	default:
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+	}
+}
+
+// notifyHoldReady emails the user of a hold that became ready that the book is kept for them.
+// The change of the hold is already saved, so a failure to send the email is only logged.
+func notifyHoldReady(hold *models.Hold) {
+	if hold == nil {
+		return
+	}
+
+	holdsURL := fmt.Sprintf("%s/holds", os.Getenv("APP_URL"))
+	body := fmt.Sprintf("%s is available for you until %s. Request it before then to keep your place: <a href='%s' target='_blank'>View Holds</a>",
+		html.EscapeString(hold.BookTitle), hold.ExpiresAt.Format("January 2, 2006"), holdsURL)
+
+	if err := pkg.SendEmail([]string{hold.User.Email}, "Your hold is ready", body); err != nil {
+		log.Println("Error notifying hold", hold.ID, err)
+	}
+}
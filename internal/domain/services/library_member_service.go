@@ -0,0 +1,408 @@
+package services
+
+import (
+	"fmt"
+	"html"
+	"mybooks/internal/domain/models"
+	"mybooks/internal/domain/repositories"
+	"mybooks/internal/infrastructure/helpers"
+	"mybooks/pkg"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// libraryInvitationTTL is how long an invitation to a library can be accepted.
+const libraryInvitationTTL = 7 * 24 * time.Hour
+
+type LibraryMemberService struct {
+	repo repositories.LibraryMemberRepository
+}
+
+type LibraryMemberResponse struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type LibraryInvitationResponse struct {
+	ID          uuid.UUID `json:"id"`
+	LibraryID   uuid.UUID `json:"library_id"`
+	LibraryName string    `json:"library_name,omitempty"`
+	Email       string    `json:"email"`
+	Role        string    `json:"role"`
+	Status      string    `json:"status"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// NewLibraryMemberService creates a new instance of LibraryMemberService.
+//
+// Parameters:
+// - repo: The LibraryMemberRepository implementation used by the service.
+//
+// Returns:
+// - *LibraryMemberService: A pointer to the newly created LibraryMemberService instance.
+func NewLibraryMemberService(repo repositories.LibraryMemberRepository) *LibraryMemberService {
+	return &LibraryMemberService{
+		repo: repo,
+	}
+}
+
+// GetLibraryMembers retrieves the members of a library with their roles.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None
+func (s *LibraryMemberService) GetLibraryMembers(c *gin.Context) {
+	libraryID := c.Param("libraryId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	members, err := s.repo.GetLibraryMembers(userID.String(), libraryID)
+	if err != nil {
+		s.handleMemberError(c, err)
+		return
+	}
+
+	response := make([]LibraryMemberResponse, 0)
+	for _, member := range *members {
+		response = append(response, LibraryMemberResponse{
+			UserID:    member.UserID,
+			Email:     member.User.Email,
+			Role:      member.Role,
+			CreatedAt: member.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateLibraryMember changes the role of a member of a library to the role given in the request body.
+//
+// It takes a pointer to a gin.Context as a parameter and returns nothing.
+// Only an owner can change roles, and demoting the last owner is rejected with a conflict.
+func (s *LibraryMemberService) UpdateLibraryMember(c *gin.Context) {
+	libraryID := c.Param("libraryId")
+	memberID := c.Param("userId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	var body struct {
+		Role string `json:"role" validate:"required,oneof=owner editor viewer"`
+	}
+
+	if err := c.BindJSON(&body); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := pkg.ValidateModelStruct(body); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.UpdateLibraryMember(userID.String(), libraryID, memberID, body.Role); err != nil {
+		s.handleMemberError(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// RemoveLibraryMember removes a member from a library.
+//
+// An owner can remove any member, and any member can remove themselves to leave the library.
+// Removing the last owner is rejected with a conflict.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None
+func (s *LibraryMemberService) RemoveLibraryMember(c *gin.Context) {
+	libraryID := c.Param("libraryId")
+	memberID := c.Param("userId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	if err := s.repo.RemoveLibraryMember(userID.String(), libraryID, memberID); err != nil {
+		s.handleMemberError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+}
+
+// CreateInvitation invites an email address to a library with the role given in the request body.
+//
+// It takes a pointer to a gin.Context as a parameter and returns nothing.
+// The function generates a secure token, stores it as a validation token together with the invitation,
+// and sends an email with a link to accept the invitation. The invitation expires after seven days.
+// Inviting an email that already belongs to a member is rejected with a conflict.
+func (s *LibraryMemberService) CreateInvitation(c *gin.Context) {
+	id := c.Param("libraryId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	var body struct {
+		Email string `json:"email" validate:"required,email,max=100"`
+		Role  string `json:"role" validate:"required,oneof=owner editor viewer"`
+	}
+
+	if err := c.BindJSON(&body); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	body.Email = strings.TrimSpace(body.Email)
+
+	if err := pkg.ValidateModelStruct(body); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	libraryID, err := uuid.Parse(id)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	invitationID, err := pkg.GenerateRandomID()
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	tokenString, err := helpers.GenerateSecureToken()
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	expiresAt := time.Now().Add(libraryInvitationTTL)
+
+	token := models.ValidationToken{
+		Token:     tokenString,
+		Type:      "library_invitation",
+		Valid:     true,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+	}
+
+	invitation := models.LibraryInvitation{
+		ID:          invitationID,
+		LibraryID:   libraryID,
+		Email:       body.Email,
+		Role:        body.Role,
+		Status:      "pending",
+		Token:       tokenString,
+		InvitedByID: userID,
+		ExpiresAt:   expiresAt,
+	}
+
+	if err := s.repo.CreateInvitation(userID.String(), &invitation, &token); err != nil {
+		s.handleMemberError(c, err)
+		return
+	}
+
+	invitationURL := fmt.Sprintf("%s/invitations/%s", os.Getenv("APP_URL"), tokenString)
+	err = pkg.SendEmail([]string{invitation.Email}, "Library Invitation", fmt.Sprintf("%s invited you to a library on MyBooks as %s: <a href='%s' target='_blank'>View Invitation</a>", html.EscapeString(user.Email), invitation.Role, invitationURL))
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	data := map[string]interface{}{
+		"id": invitation.ID,
+	}
+
+	c.JSON(http.StatusCreated, data)
+}
+
+// GetLibraryInvitations retrieves the pending invitations of a library.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None
+func (s *LibraryMemberService) GetLibraryInvitations(c *gin.Context) {
+	libraryID := c.Param("libraryId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	invitations, err := s.repo.GetLibraryInvitations(userID.String(), libraryID)
+	if err != nil {
+		s.handleMemberError(c, err)
+		return
+	}
+
+	response := make([]LibraryInvitationResponse, 0)
+	for _, invitation := range *invitations {
+		response = append(response, buildLibraryInvitationResponse(invitation))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RevokeInvitation revokes a pending invitation of a library.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None
+func (s *LibraryMemberService) RevokeInvitation(c *gin.Context) {
+	libraryID := c.Param("libraryId")
+	invitationID := c.Param("invitationId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	if err := s.repo.RevokeInvitation(userID.String(), libraryID, invitationID); err != nil {
+		s.handleMemberError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation revoked successfully"})
+}
+
+// GetPendingInvitations retrieves the pending invitations sent to the email of the user.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None
+func (s *LibraryMemberService) GetPendingInvitations(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	invitations, err := s.repo.GetPendingInvitations(user.Email)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]LibraryInvitationResponse, 0)
+	for _, invitation := range *invitations {
+		response = append(response, buildLibraryInvitationResponse(invitation))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// AcceptInvitation accepts the invitation identified by the token in the URL.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None
+func (s *LibraryMemberService) AcceptInvitation(c *gin.Context) {
+	s.respondToInvitation(c, true)
+}
+
+// DeclineInvitation declines the invitation identified by the token in the URL.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None
+func (s *LibraryMemberService) DeclineInvitation(c *gin.Context) {
+	s.respondToInvitation(c, false)
+}
+
+// respondToInvitation accepts or declines the invitation identified by the token in the URL.
+func (s *LibraryMemberService) respondToInvitation(c *gin.Context, accept bool) {
+	tokenString := c.Param("token")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	if err := s.repo.RespondToInvitation(user, tokenString, accept); err != nil {
+		s.handleMemberError(c, err)
+		return
+	}
+
+	message := "Invitation declined successfully"
+	if accept {
+		message = "Invitation accepted successfully"
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": message})
+}
+
+// handleMemberError maps the errors of the library member operations to HTTP status codes.
+func (s *LibraryMemberService) handleMemberError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		helpers.HandleError(c, err, http.StatusNotFound)
+	case strings.Contains(err.Error(), "insufficient library permissions"):
+		helpers.HandleError(c, err, http.StatusForbidden)
+	case strings.Contains(err.Error(), "already a member"), strings.Contains(err.Error(), "at least one owner"):
+		helpers.HandleError(c, err, http.StatusConflict)
+	case strings.Contains(err.Error(), "invalid or expired"):
+		helpers.HandleError(c, err, http.StatusBadRequest)
+	default:
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+	}
+}
+
+// buildLibraryInvitationResponse maps an invitation to the response returned to the client.
+func buildLibraryInvitationResponse(invitation models.LibraryInvitation) LibraryInvitationResponse {
+	return LibraryInvitationResponse{
+		ID:          invitation.ID,
+		LibraryID:   invitation.LibraryID,
+		LibraryName: invitation.Library.Name,
+		Email:       invitation.Email,
+		Role:        invitation.Role,
+		Status:      invitation.Status,
+		ExpiresAt:   invitation.ExpiresAt,
+		CreatedAt:   invitation.CreatedAt,
+	}
+}
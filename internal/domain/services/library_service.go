@@ -1,11 +1,15 @@
 package services
 
 import (
+	"errors"
+	"mybooks/internal/domain/events"
 	"mybooks/internal/domain/models"
 	"mybooks/internal/domain/repositories"
+	"mybooks/internal/domain/rules"
 	"mybooks/internal/infrastructure/helpers"
 	"mybooks/pkg"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -18,11 +22,54 @@ type LibraryService struct {
 }
 
 type LibraryResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Rule        string     `json:"rule"`
+	Smart       bool       `json:"smart"`
+	ParentID    *uuid.UUID `json:"parent_id"`
+	Public      bool       `json:"public"`
+	Role        string     `json:"role"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+type LibraryTreeResponse struct {
+	LibraryResponse
+	Children []*LibraryTreeResponse `json:"children"`
+}
+
+// LibraryVisibilityRequest is the body of a request making a library public or private.
+type LibraryVisibilityRequest struct {
+	Public *bool `json:"public" validate:"required"`
+}
+
+// PublicLibrarySummary is a public library as listed on a public profile.
+type PublicLibrarySummary struct {
 	ID          uuid.UUID `json:"id"`
 	Name        string    `json:"name"`
 	Description string    `json:"description"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	Smart       bool      `json:"smart"`
+}
+
+// PublicBook holds the only fields of a book shown to the visitors of a public library.
+type PublicBook struct {
+	ID            uuid.UUID `json:"id"`
+	Title         string    `json:"title"`
+	Author        string    `json:"author"`
+	Description   string    `json:"description"`
+	Cover         string    `json:"cover"`
+	Genre         string    `json:"genre"`
+	ISBN          string    `json:"isbn"`
+	PublishedDate string    `json:"published_date"`
+	Language      string    `json:"language"`
+	Pages         int       `json:"pages"`
+}
+
+type PublicLibraryResponse struct {
+	PublicLibrarySummary
+	Children []PublicLibrarySummary `json:"children"`
+	Books    []PublicBook           `json:"books"`
 }
 
 // NewLibraryService creates a new instance of LibraryService.
@@ -52,6 +99,12 @@ func (s *LibraryService) CreateLibrary(c *gin.Context) {
 		return
 	}
 
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
 	id, err := pkg.GenerateRandomID()
 	if err != nil {
 		helpers.HandleError(c, err, http.StatusInternalServerError)
@@ -64,15 +117,34 @@ func (s *LibraryService) CreateLibrary(c *gin.Context) {
 	}
 
 	library.ID = id
+	library.OrganizationID = organization.ID
 	library.UserID = user.ID
 	library.User = *user
+	library.Rule = strings.TrimSpace(library.Rule)
 
 	if err := pkg.ValidateModelStruct(library); err != nil {
 		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
 		return
 	}
 
+	if library.Rule != "" {
+		if _, err := rules.Parse(library.Rule); err != nil {
+			helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+			return
+		}
+	}
+
 	if err := s.repo.CreateLibrary(library); err != nil {
+		if strings.Contains(err.Error(), "library not found") {
+			helpers.HandleError(c, err, http.StatusNotFound)
+			return
+		}
+
+		if strings.Contains(err.Error(), "insufficient library permissions") {
+			helpers.HandleError(c, err, http.StatusForbidden)
+			return
+		}
+
 		helpers.HandleError(c, err, http.StatusInternalServerError)
 		return
 	}
@@ -84,7 +156,8 @@ func (s *LibraryService) CreateLibrary(c *gin.Context) {
 	c.JSON(http.StatusCreated, data)
 }
 
-// GetAllLibraries retrieves all libraries from the library service and returns them as a JSON response.
+// GetAllLibraries retrieves the libraries of the active organization and the libraries shared with the user,
+// and returns them as a JSON response.
 //
 // Parameters:
 // - c: a pointer to a gin.Context object representing the HTTP request and response.
@@ -99,7 +172,14 @@ func (s *LibraryService) GetAllLibraries(c *gin.Context) {
 	}
 	userID := user.ID
 
-	libraries, err := s.repo.GetAllLibraries(userID.String())
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	libraries, err := s.repo.GetAllLibraries(userID.String(), orgID.String())
 	if err != nil {
 		helpers.HandleError(c, err, http.StatusInternalServerError)
 		return
@@ -107,20 +187,72 @@ func (s *LibraryService) GetAllLibraries(c *gin.Context) {
 
 	response := make([]LibraryResponse, 0)
 	for _, library := range *libraries {
-		response = append(response, LibraryResponse{
-			ID:          library.ID,
-			Name:        library.Name,
-			Description: library.Description,
-			CreatedAt:   library.CreatedAt,
-			UpdatedAt:   library.UpdatedAt,
-		})
+		response = append(response, buildLibraryResponse(library))
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+// GetLibraryTree retrieves the libraries of the active organization and the libraries shared with the user,
+// nested under their parents.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None
+func (s *LibraryService) GetLibraryTree(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	libraries, err := s.repo.GetAllLibraries(userID.String(), orgID.String())
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	nodes := make(map[uuid.UUID]*LibraryTreeResponse)
+	for _, library := range *libraries {
+		nodes[library.ID] = &LibraryTreeResponse{
+			LibraryResponse: buildLibraryResponse(library),
+			Children:        make([]*LibraryTreeResponse, 0),
+		}
+	}
+
+	roots := make([]*LibraryTreeResponse, 0)
+	for _, library := range *libraries {
+		node := nodes[library.ID]
+
+		if library.ParentID != nil {
+			if parent, ok := nodes[*library.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+
+		roots = append(roots, node)
+	}
+
+	c.JSON(http.StatusOK, roots)
+}
+
 // GetLibraryByID retrieves a library by its ID.
 //
+// The books of a smart library are computed live from its rule instead of the manual list.
+// With the "include_descendants=true" query parameter the books of every descendant library
+// are included as well.
+//
 // Parameters:
 // - c: a pointer to a gin.Context object representing the HTTP request and response.
 //
@@ -143,15 +275,90 @@ func (s *LibraryService) GetLibraryByID(c *gin.Context) {
 			return
 		}
 
+		if strings.Contains(err.Error(), "insufficient library permissions") {
+			helpers.HandleError(c, err, http.StatusForbidden)
+			return
+		}
+
 		helpers.HandleError(c, err, http.StatusInternalServerError)
 		return
 	}
 
+	if includeDescendants, _ := strconv.ParseBool(c.Query("include_descendants")); includeDescendants {
+		books, err := s.getSubtreeBooks(userID.String(), libraryID)
+		if err != nil {
+			helpers.HandleError(c, err, http.StatusInternalServerError)
+			return
+		}
+
+		library.Books = books
+	} else if library.Rule != "" {
+		// The books of a smart library are evaluated from its rule on every request
+		books, err := s.getSmartLibraryBooks(library)
+		if err != nil {
+			helpers.HandleError(c, err, http.StatusInternalServerError)
+			return
+		}
+
+		library.Books = books
+	}
+
 	c.JSON(http.StatusOK, library)
 }
 
+// MoveLibrary moves a library, with all of its descendants, under the parent given in the request body.
+//
+// It takes a pointer to a gin.Context as a parameter and returns nothing.
+// A null parent_id moves the library to the root. Moving a library under itself or one of its
+// descendants is rejected with a conflict.
+func (s *LibraryService) MoveLibrary(c *gin.Context) {
+	libraryID := c.Param("libraryId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	var body struct {
+		ParentID *uuid.UUID `json:"parent_id"`
+	}
+
+	if err := c.BindJSON(&body); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := s.repo.MoveLibrary(userID.String(), libraryID, body.ParentID); err != nil {
+		if strings.Contains(err.Error(), "library not found") {
+			helpers.HandleError(c, err, http.StatusNotFound)
+			return
+		}
+
+		if strings.Contains(err.Error(), "insufficient library permissions") {
+			helpers.HandleError(c, err, http.StatusForbidden)
+			return
+		}
+
+		if strings.Contains(err.Error(), "cannot move") {
+			helpers.HandleError(c, err, http.StatusConflict)
+			return
+		}
+
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
 // DeleteLibrary deletes a library by its ID.
 //
+// The "children" query parameter decides what happens to the child libraries: "reject" (default)
+// refuses to delete a library that has children, "cascade" deletes the whole subtree and
+// "reparent" moves the children to the parent of the deleted library.
+//
 // Parameters:
 // - c: a pointer to a gin.Context object representing the HTTP request and response.
 //
@@ -167,12 +374,28 @@ func (s *LibraryService) DeleteLibrary(c *gin.Context) {
 	}
 	userID := user.ID
 
-	if err := s.repo.DeleteLibrary(userID.String(), libraryID); err != nil {
+	childStrategy := c.DefaultQuery("children", "reject")
+	if childStrategy != "reject" && childStrategy != "cascade" && childStrategy != "reparent" {
+		helpers.HandleError(c, errors.New("children must be one of: reject cascade reparent"), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.repo.DeleteLibrary(userID.String(), libraryID, childStrategy); err != nil {
 		if strings.Contains(err.Error(), "library not found") {
 			helpers.HandleError(c, err, http.StatusNotFound)
 			return
 		}
 
+		if strings.Contains(err.Error(), "insufficient library permissions") {
+			helpers.HandleError(c, err, http.StatusForbidden)
+			return
+		}
+
+		if strings.Contains(err.Error(), "child libraries") {
+			helpers.HandleError(c, err, http.StatusConflict)
+			return
+		}
+
 		helpers.HandleError(c, err, http.StatusInternalServerError)
 		return
 	}
@@ -212,13 +435,31 @@ func (s *LibraryService) UpdateLibrary(c *gin.Context) {
 
 	library.ID = libraryID
 	library.User = *user
+	library.Rule = strings.TrimSpace(library.Rule)
 
 	if err := pkg.ValidateModelStruct(library); err != nil {
 		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
 		return
 	}
 
+	if library.Rule != "" {
+		if _, err := rules.Parse(library.Rule); err != nil {
+			helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+			return
+		}
+	}
+
 	if err := s.repo.UpdateLibrary(userID.String(), &library); err != nil {
+		if strings.Contains(err.Error(), "library not found") {
+			helpers.HandleError(c, err, http.StatusNotFound)
+			return
+		}
+
+		if strings.Contains(err.Error(), "insufficient library permissions") {
+			helpers.HandleError(c, err, http.StatusForbidden)
+			return
+		}
+
 		helpers.HandleError(c, err, http.StatusInternalServerError)
 		return
 	}
@@ -233,6 +474,7 @@ func (s *LibraryService) UpdateLibrary(c *gin.Context) {
 // retrieves the user ID from the context,
 // adds the book to the library in the repository,
 // and returns an HTTP status code indicating the success of the operation.
+// Smart libraries are rejected with a conflict, since their books come from their rule.
 func (s *LibraryService) AddBookToLibrary(c *gin.Context) {
 	libraryID := c.Param("libraryId")
 	bookID := c.Param("bookId")
@@ -244,11 +486,39 @@ func (s *LibraryService) AddBookToLibrary(c *gin.Context) {
 	}
 	userID := user.ID
 
-	if err := s.repo.AddBookToLibrary(userID.String(), libraryID, bookID); err != nil {
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	if err := s.repo.AddBookToLibrary(userID.String(), orgID.String(), libraryID, bookID); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			helpers.HandleError(c, err, http.StatusNotFound)
+			return
+		}
+
+		if strings.Contains(err.Error(), "insufficient library permissions") {
+			helpers.HandleError(c, err, http.StatusForbidden)
+			return
+		}
+
+		if strings.Contains(err.Error(), "smart library") {
+			helpers.HandleError(c, err, http.StatusConflict)
+			return
+		}
+
 		helpers.HandleError(c, err, http.StatusInternalServerError)
 		return
 	}
 
+	libraryUUID, libraryErr := uuid.Parse(libraryID)
+	bookUUID, bookErr := uuid.Parse(bookID)
+	if libraryErr == nil && bookErr == nil {
+		events.Publish(events.Event{Kind: events.BookAddedToLibrary, UserID: userID, BookID: bookUUID, LibraryID: &libraryUUID})
+	}
+
 	c.Status(http.StatusOK)
 }
 
@@ -259,6 +529,7 @@ func (s *LibraryService) AddBookToLibrary(c *gin.Context) {
 // retrieves the user ID from the context,
 // removes the book from the library in the repository,
 // and returns an HTTP status code indicating the success of the operation.
+// Smart libraries are rejected with a conflict, since their books come from their rule.
 func (s *LibraryService) RemoveBookFromLibrary(c *gin.Context) {
 	libraryID := c.Param("libraryId")
 	bookID := c.Param("bookId")
@@ -271,9 +542,310 @@ func (s *LibraryService) RemoveBookFromLibrary(c *gin.Context) {
 	userID := user.ID
 
 	if err := s.repo.RemoveBookFromLibrary(userID.String(), libraryID, bookID); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			helpers.HandleError(c, err, http.StatusNotFound)
+			return
+		}
+
+		if strings.Contains(err.Error(), "insufficient library permissions") {
+			helpers.HandleError(c, err, http.StatusForbidden)
+			return
+		}
+
+		if strings.Contains(err.Error(), "smart library") {
+			helpers.HandleError(c, err, http.StatusConflict)
+			return
+		}
+
 		helpers.HandleError(c, err, http.StatusInternalServerError)
 		return
 	}
 
 	c.Status(http.StatusOK)
 }
+
+// getSmartLibraryBooks evaluates the rule of a smart library against the books of the organization owning it,
+// so every member of a shared smart library sees the same books.
+func (s *LibraryService) getSmartLibraryBooks(library *models.Library) ([]models.Book, error) {
+	rule, err := rules.Parse(library.Rule)
+	if err != nil {
+		return nil, err
+	}
+
+	books, err := s.repo.GetBooksByRule(library.OrganizationID.String(), rule)
+	if err != nil {
+		return nil, err
+	}
+
+	return *books, nil
+}
+
+// getSubtreeBooks collects the distinct books of a library and all of its descendants,
+// evaluating the rule of every smart library in the subtree.
+func (s *LibraryService) getSubtreeBooks(userID, libraryID string) ([]models.Book, error) {
+	libraries, err := s.repo.GetLibrarySubtree(userID, libraryID)
+	if err != nil {
+		return nil, err
+	}
+
+	manualIDs := make([]uuid.UUID, 0)
+	result := make([]models.Book, 0)
+	seen := make(map[uuid.UUID]bool)
+
+	appendBooks := func(books []models.Book) {
+		for _, book := range books {
+			if !seen[book.ID] {
+				seen[book.ID] = true
+				result = append(result, book)
+			}
+		}
+	}
+
+	for i := range *libraries {
+		library := &(*libraries)[i]
+		if library.Rule == "" {
+			manualIDs = append(manualIDs, library.ID)
+			continue
+		}
+
+		books, err := s.getSmartLibraryBooks(library)
+		if err != nil {
+			return nil, err
+		}
+		appendBooks(books)
+	}
+
+	if len(manualIDs) > 0 {
+		books, err := s.repo.GetBooksInLibraries(manualIDs)
+		if err != nil {
+			return nil, err
+		}
+		appendBooks(*books)
+	}
+
+	return result, nil
+}
+
+// buildLibraryResponse maps a library to the response used when listing libraries.
+func buildLibraryResponse(library models.Library) LibraryResponse {
+	return LibraryResponse{
+		ID:          library.ID,
+		Name:        library.Name,
+		Description: library.Description,
+		Rule:        library.Rule,
+		Smart:       library.Rule != "",
+		ParentID:    library.ParentID,
+		Public:      library.Public,
+		Role:        library.Role,
+		CreatedAt:   library.CreatedAt,
+		UpdatedAt:   library.UpdatedAt,
+	}
+}
+
+// MoveBookInLibrary moves a book to the index given in the request body within the manual order of a library.
+//
+// It takes a pointer to a gin.Context as a parameter and returns nothing.
+// The index is zero-based and an index past the end moves the book to the end.
+// Smart libraries are rejected with a conflict, since their order is computed.
+func (s *LibraryService) MoveBookInLibrary(c *gin.Context) {
+	libraryID := c.Param("libraryId")
+	bookID := c.Param("bookId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	var body struct {
+		Index int `json:"index" validate:"min=0"`
+	}
+
+	if err := c.BindJSON(&body); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := pkg.ValidateModelStruct(body); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.MoveBookInLibrary(userID.String(), libraryID, bookID, body.Index); err != nil {
+		s.handleOrderError(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// ReorderLibraryBooks sets the manual order of every book of a library.
+//
+// It takes a pointer to a gin.Context as a parameter and returns nothing.
+// The request body must list every book of the library exactly once, in the requested order.
+// Smart libraries are rejected with a conflict, since their order is computed.
+func (s *LibraryService) ReorderLibraryBooks(c *gin.Context) {
+	libraryID := c.Param("libraryId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	var body struct {
+		BookIDs []uuid.UUID `json:"book_ids" validate:"required"`
+	}
+
+	if err := c.BindJSON(&body); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := pkg.ValidateModelStruct(body); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.ReorderLibraryBooks(userID.String(), libraryID, body.BookIDs); err != nil {
+		s.handleOrderError(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// SetLibraryVisibility makes a library public or private, from the "public" field of the request body.
+// Only an owner of the library can change its visibility.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *LibraryService) SetLibraryVisibility(c *gin.Context) {
+	libraryID := c.Param("libraryId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	var request LibraryVisibilityRequest
+	if err := c.BindJSON(&request); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := pkg.ValidateModelStruct(request); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.SetLibraryVisibility(userID.String(), libraryID, *request.Public); err != nil {
+		if strings.Contains(err.Error(), "library not found") {
+			helpers.HandleError(c, err, http.StatusNotFound)
+			return
+		}
+
+		if strings.Contains(err.Error(), "insufficient library permissions") {
+			helpers.HandleError(c, err, http.StatusForbidden)
+			return
+		}
+
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Library visibility updated successfully"})
+}
+
+// GetPublicLibrary retrieves a public library of a public profile, with its public child libraries and its
+// books. It needs no authentication and only shows the public fields of the books of the organization
+// owning the library.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *LibraryService) GetPublicLibrary(c *gin.Context) {
+	library, err := s.repo.GetPublicLibrary(strings.ToLower(c.Param("handle")), c.Param("libraryId"))
+	if err != nil {
+		if strings.Contains(err.Error(), "library not found") {
+			helpers.HandleError(c, err, http.StatusNotFound)
+			return
+		}
+
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	if library.Rule != "" {
+		// The books of a smart library are evaluated from its rule on every request
+		books, err := s.getSmartLibraryBooks(library)
+		if err != nil {
+			helpers.HandleError(c, err, http.StatusInternalServerError)
+			return
+		}
+
+		library.Books = books
+	}
+
+	response := PublicLibraryResponse{
+		PublicLibrarySummary: buildPublicLibrarySummary(*library),
+		Children:             make([]PublicLibrarySummary, 0),
+		Books:                make([]PublicBook, 0),
+	}
+
+	for _, child := range library.Children {
+		response.Children = append(response.Children, buildPublicLibrarySummary(child))
+	}
+
+	for _, book := range library.Books {
+		response.Books = append(response.Books, PublicBook{
+			ID:            book.ID,
+			Title:         book.Title,
+			Author:        book.Author,
+			Description:   book.Description,
+			Cover:         book.Cover,
+			Genre:         book.Genre,
+			ISBN:          book.ISBN,
+			PublishedDate: book.PublishedDate,
+			Language:      book.Language,
+			Pages:         book.Pages,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// buildPublicLibrarySummary maps a library to the summary shown to visitors, without its rule.
+func buildPublicLibrarySummary(library models.Library) PublicLibrarySummary {
+	return PublicLibrarySummary{
+		ID:          library.ID,
+		Name:        library.Name,
+		Description: library.Description,
+		Smart:       library.Rule != "",
+	}
+}
+
+// handleOrderError maps the errors of the library ordering operations to HTTP status codes.
+func (s *LibraryService) handleOrderError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		helpers.HandleError(c, err, http.StatusNotFound)
+	case strings.Contains(err.Error(), "insufficient library permissions"):
+		helpers.HandleError(c, err, http.StatusForbidden)
+	case strings.Contains(err.Error(), "smart library"):
+		helpers.HandleError(c, err, http.StatusConflict)
+	case strings.Contains(err.Error(), "book_ids"):
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+	default:
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+	}
+}
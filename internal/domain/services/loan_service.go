@@ -7,6 +7,7 @@ import (
 	"mybooks/pkg"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -39,7 +40,12 @@ func (s *LoanService) CreateLoan(c *gin.Context) {
 		helpers.HandleError(c, err, http.StatusUnauthorized)
 		return
 	}
-	userID := user.ID
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
 
 	id, err := pkg.GenerateRandomID()
 	if err != nil {
@@ -53,7 +59,12 @@ func (s *LoanService) CreateLoan(c *gin.Context) {
 	}
 
 	loan.ID = id
-	loan.UserID = userID
+	loan.BorrowerID = nil
+	loan.BorrowerContact = nil
+	loan.ReturnedAt = nil
+	loan.Status = models.LoanStatusActive
+	loan.OrganizationID = organization.ID
+	loan.UserID = user.ID
 
 	if err := pkg.ValidateModelStruct(loan); err != nil {
 		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
@@ -61,15 +72,69 @@ func (s *LoanService) CreateLoan(c *gin.Context) {
 	}
 
 	if err := s.repo.CreateLoan(loan); err != nil {
-		if strings.Contains(err.Error(), "book not found") {
-			helpers.HandleError(c, err, http.StatusNotFound)
-			return
-		}
+		s.handleLoanError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, map[string]interface{}{
+		"id": loan.ID,
+	})
+}
 
+// RequestLoan creates a request to borrow a book from a library shared with the user.
+//
+// It takes a pointer to a gin.Context as a parameter and returns nothing.
+// The request body holds the IDs of the book and of the shared library it is in. The loan starts
+// once a member of the organization owning the book approves the request.
+func (s *LoanService) RequestLoan(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	id, err := pkg.GenerateRandomID()
+	if err != nil {
 		helpers.HandleError(c, err, http.StatusInternalServerError)
 		return
 	}
 
+	var body struct {
+		BookID    string `json:"book_id" validate:"required,uuid4"`
+		LibraryID string `json:"library_id" validate:"required,uuid4"`
+	}
+
+	if err := c.BindJSON(&body); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := pkg.ValidateModelStruct(body); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	loan := &models.Loan{
+		ID:             id,
+		BookID:         body.BookID,
+		LoanDate:       time.Now().Format(time.DateOnly),
+		BorrowerName:   user.Email,
+		BorrowerID:     &user.ID,
+		Status:         models.LoanStatusRequested,
+		OrganizationID: organization.ID,
+	}
+
+	if err := s.repo.RequestLoan(body.LibraryID, loan); err != nil {
+		s.handleLoanError(c, err)
+		return
+	}
+
 	c.JSON(http.StatusCreated, map[string]interface{}{
 		"id": loan.ID,
 	})
@@ -78,7 +143,8 @@ func (s *LoanService) CreateLoan(c *gin.Context) {
 // GetAllLoans retrieves all loans from the loan service.
 //
 // It takes a pointer to a gin.Context as a parameter and returns nothing.
-// The function retrieves all loans from the loan repository and returns them as JSON in the response body.
+// The function retrieves the loans of the active organization and the books the user borrowed from
+// other users, and returns them as JSON in the response body.
 // If an error occurs during the process, it handles the error and returns an appropriate HTTP status code.
 func (s *LoanService) GetAllLoans(c *gin.Context) {
 	user, err := helpers.GetUserFromContext(c)
@@ -88,7 +154,14 @@ func (s *LoanService) GetAllLoans(c *gin.Context) {
 	}
 	userID := user.ID
 
-	loans, err := s.repo.GetAllLoans(userID.String())
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	loans, err := s.repo.GetAllLoans(userID.String(), orgID.String())
 	if err != nil {
 		helpers.HandleError(c, err, http.StatusInternalServerError)
 		return
@@ -97,8 +170,59 @@ func (s *LoanService) GetAllLoans(c *gin.Context) {
 	c.JSON(http.StatusOK, loans)
 }
 
+// ApproveLoan approves a borrow request for a book of the active organization.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request context.
+//
+// Returns: None.
+func (s *LoanService) ApproveLoan(c *gin.Context) {
+	loanID := c.Param("loanId")
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	if err := s.repo.ApproveLoan(orgID.String(), loanID, time.Now().Format(time.DateOnly)); err != nil {
+		s.handleLoanError(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// DeclineLoan declines a borrow request for a book of the active organization.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request context.
+//
+// Returns: None.
+func (s *LoanService) DeclineLoan(c *gin.Context) {
+	loanID := c.Param("loanId")
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	if err := s.repo.DeclineLoan(orgID.String(), loanID); err != nil {
+		s.handleLoanError(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
 // ReturnLoan handles the return of a loan by its ID.
 //
+// The lender returns the loan right away and the first user waiting for the book is notified,
+// while a borrower marks it as return pending until the lender confirms the return by returning it too.
+//
 // Parameters:
 // - c: a pointer to a gin.Context object representing the HTTP request context.
 //
@@ -113,10 +237,39 @@ func (s *LoanService) ReturnLoan(c *gin.Context) {
 	}
 	userID := user.ID
 
-	if err := s.repo.ReturnLoan(userID.String(), loanID); err != nil {
-		helpers.HandleError(c, err, http.StatusInternalServerError)
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	next, err := s.repo.ReturnLoan(userID.String(), orgID.String(), loanID)
+	if err != nil {
+		s.handleLoanError(c, err)
 		return
 	}
 
+	notifyHoldReady(next)
+
 	c.Status(http.StatusOK)
 }
+
+// handleLoanError maps the errors of the loan operations to HTTP status codes.
+func (s *LoanService) handleLoanError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		helpers.HandleError(c, err, http.StatusNotFound)
+	case strings.Contains(err.Error(), "insufficient library permissions"):
+		helpers.HandleError(c, err, http.StatusForbidden)
+	case strings.Contains(err.Error(), "already borrowed"),
+		strings.Contains(err.Error(), "no copies"),
+		strings.Contains(err.Error(), "already requested"),
+		strings.Contains(err.Error(), "on hold"),
+		strings.Contains(err.Error(), "your own organization"),
+		strings.Contains(err.Error(), "loan is not"):
+		helpers.HandleError(c, err, http.StatusConflict)
+	default:
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+	}
+}
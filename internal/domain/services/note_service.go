@@ -0,0 +1,532 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mybooks/internal/domain/models"
+	"mybooks/internal/domain/repositories"
+	"mybooks/internal/infrastructure/helpers"
+	"mybooks/pkg"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// noteTagsLimit is the maximum number of tags of a note.
+const noteTagsLimit = 20
+
+// noteImportMaxSize is the maximum size in bytes of an uploaded clippings file or e-reader database.
+const noteImportMaxSize = 64 << 20
+
+type NoteService struct {
+	repo repositories.NoteRepository
+}
+
+// NewNoteService creates a new instance of the NoteService struct.
+//
+// It takes a NoteRepository as a parameter and returns a pointer to a NoteService.
+func NewNoteService(repo repositories.NoteRepository) *NoteService {
+	return &NoteService{
+		repo: repo,
+	}
+}
+
+// CreateNote adds a highlight or a note to a book of the active organization.
+//
+// It takes a gin.Context as a parameter and returns nothing.
+// The function generates a random ID, binds the JSON from the request to a models.Note struct,
+// validates the struct, creates the note in the repository, and returns the ID of the created note.
+// The tags are given by name and the ones the organization does not have yet are created.
+func (s *NoteService) CreateNote(c *gin.Context) {
+	bookID := c.Param("bookId")
+	note := new(models.Note)
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	id, err := pkg.GenerateRandomID()
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	if err := c.BindJSON(note); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	bookUUID, err := uuid.Parse(bookID)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	note.ID = id
+	note.BookID = bookUUID
+	note.UserID = user.ID
+	note.User = *user
+
+	if err := prepareNote(note); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := pkg.ValidateModelStruct(note); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.CreateNote(orgID.String(), note); err != nil {
+		s.handleNoteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, map[string]interface{}{
+		"id": note.ID,
+	})
+}
+
+// GetBookNotes retrieves the highlights and notes of the user on a book of the active organization,
+// in reading order.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *NoteService) GetBookNotes(c *gin.Context) {
+	bookID := c.Param("bookId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	_, notes, err := s.repo.GetBookNotes(userID.String(), orgID.String(), bookID)
+	if err != nil {
+		s.handleNoteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, notes)
+}
+
+// ExportBookNotes exports the highlights and notes of the user on a book of the active organization
+// as a Markdown file.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *NoteService) ExportBookNotes(c *gin.Context) {
+	bookID := c.Param("bookId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	book, notes, err := s.repo.GetBookNotes(userID.String(), orgID.String(), bookID)
+	if err != nil {
+		s.handleNoteError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="notes-%s.md"`, book.ID))
+	c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(notesMarkdown(book, *notes)))
+}
+
+// SearchNotes retrieves the highlights and notes of the user on the books of the active organization.
+//
+// The "q" query parameter searches the text and comment of the notes, best matches first, and supports
+// quoted phrases, "or" and "-" to exclude words. The "tag" and "kind" query parameters restrict the notes
+// to a tag and to highlights or notes.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *NoteService) SearchNotes(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	filters := make(map[string]interface{})
+
+	if search := strings.TrimSpace(c.Query("q")); search != "" {
+		filters["q"] = search
+	}
+	if tag := pkg.NormalizeTagName(c.Query("tag")); tag != "" {
+		filters["tag"] = tag
+	}
+	if kind := strings.ToLower(strings.TrimSpace(c.Query("kind"))); kind != "" {
+		if kind != "highlight" && kind != "note" {
+			helpers.HandleError(c, errors.New("kind must be one of: highlight note"), http.StatusBadRequest)
+			return
+		}
+		filters["kind"] = kind
+	}
+
+	notes, err := s.repo.SearchNotes(userID.String(), orgID.String(), filters)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, notes)
+}
+
+// UpdateNote updates a highlight or note of the user.
+//
+// It takes a gin.Context as a parameter and returns nothing.
+// When the request body has tags they replace the tags of the note.
+func (s *NoteService) UpdateNote(c *gin.Context) {
+	id := c.Param("noteId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	var note models.Note
+	if err := c.BindJSON(&note); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	noteID, err := uuid.Parse(id)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	note.ID = noteID
+	note.UserID = userID
+	note.User = *user
+
+	if err := prepareNote(&note); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := pkg.ValidateModelStruct(note); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.UpdateNote(userID.String(), orgID.String(), &note); err != nil {
+		s.handleNoteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Note updated successfully"})
+}
+
+// DeleteNote deletes a highlight or note of the user.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *NoteService) DeleteNote(c *gin.Context) {
+	id := c.Param("noteId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	if err := s.repo.DeleteNote(userID.String(), orgID.String(), id); err != nil {
+		s.handleNoteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Note deleted successfully"})
+}
+
+// ImportKindleClippings imports the highlights and notes of the "My Clippings.txt" file of a Kindle,
+// uploaded as the "file" field of a multipart form.
+//
+// The clippings are matched to the books of the active organization by title and author, and placeholder
+// books are created for the others. Clippings imported before are not imported again.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *NoteService) ImportKindleClippings(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, noteImportMaxSize)
+	header, err := c.FormFile("file")
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	clippings, err := pkg.ParseKindleClippings(file)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	result, err := s.repo.ImportNotes(user.ID, orgID, "kindle", clippings)
+	if err != nil {
+		s.handleNoteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ImportKoboAnnotations imports the highlights and notes of the KoboReader.sqlite database of a Kobo,
+// uploaded as the "file" field of a multipart form.
+//
+// The annotations are matched to the books of the active organization by title and author, and placeholder
+// books are created for the others. Annotations imported before are not imported again.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *NoteService) ImportKoboAnnotations(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, noteImportMaxSize)
+	header, err := c.FormFile("file")
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	// The database is read by SQLite, which needs a file on disk
+	database, err := os.CreateTemp("", "kobo-*.sqlite")
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+	defer os.Remove(database.Name())
+
+	_, err = io.Copy(database, file)
+	if closeErr := database.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	clippings, err := pkg.ReadKoboAnnotations(database.Name())
+	if err != nil {
+		s.handleNoteError(c, err)
+		return
+	}
+
+	result, err := s.repo.ImportNotes(user.ID, orgID, "kobo", clippings)
+	if err != nil {
+		s.handleNoteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// handleNoteError maps the errors of the note operations to HTTP status codes.
+func (s *NoteService) handleNoteError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		helpers.HandleError(c, err, http.StatusNotFound)
+	case strings.Contains(err.Error(), "invalid Kobo database"):
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+	default:
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+	}
+}
+
+// prepareNote trims the text of a note, applies the default kind and keeps only the names of its tags,
+// without duplicates. It returns an error when a tag name is empty or too long, or there are too many tags.
+func prepareNote(note *models.Note) error {
+	note.Kind = strings.ToLower(strings.TrimSpace(note.Kind))
+	if note.Kind == "" {
+		note.Kind = "highlight"
+	}
+	note.Text = strings.TrimSpace(note.Text)
+	note.Comment = strings.TrimSpace(note.Comment)
+	note.Location = strings.TrimSpace(note.Location)
+
+	if note.Tags == nil {
+		return nil
+	}
+
+	tags := make([]models.Tag, 0)
+	seen := make(map[string]bool)
+	for _, tag := range note.Tags {
+		name := strings.TrimSpace(tag.Name)
+		key := pkg.NormalizeTagName(name)
+
+		if key == "" || len(name) > 50 {
+			return errors.New("tag names must have between 1 and 50 characters")
+		}
+		if seen[key] {
+			continue
+		}
+
+		seen[key] = true
+		tags = append(tags, models.Tag{Name: name})
+	}
+
+	if len(tags) > noteTagsLimit {
+		return fmt.Errorf("a note can have at most %d tags", noteTagsLimit)
+	}
+
+	note.Tags = tags
+	return nil
+}
+
+// notesMarkdown writes the notes on a book as a Markdown document: highlights are quoted, followed by
+// their location and the comment on them, and notes are plain paragraphs.
+func notesMarkdown(book *models.Book, notes []models.Note) string {
+	var out strings.Builder
+
+	fmt.Fprintf(&out, "# %s\n\n*%s*\n", book.Title, book.Author)
+
+	for _, note := range notes {
+		out.WriteString("\n")
+
+		if note.Kind == "highlight" {
+			for _, line := range strings.Split(note.Text, "\n") {
+				out.WriteString(strings.TrimRight("> "+line, " ") + "\n")
+			}
+		} else {
+			out.WriteString(note.Text + "\n")
+		}
+
+		where := make([]string, 0)
+		if note.Page > 0 {
+			where = append(where, fmt.Sprintf("page %d", note.Page))
+		}
+		if note.Location != "" {
+			where = append(where, "location "+note.Location)
+		}
+		if len(where) > 0 {
+			out.WriteString("\n— " + strings.Join(where, ", ") + "\n")
+		}
+
+		if note.Comment != "" {
+			out.WriteString("\n" + note.Comment + "\n")
+		}
+
+		if len(note.Tags) > 0 {
+			names := make([]string, 0)
+			for _, tag := range note.Tags {
+				names = append(names, "`"+tag.Name+"`")
+			}
+			out.WriteString("\nTags: " + strings.Join(names, " ") + "\n")
+		}
+
+		out.WriteString("\n---\n")
+	}
+
+	return out.String()
+}
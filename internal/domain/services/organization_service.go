@@ -0,0 +1,339 @@
+package services
+
+import (
+	"mybooks/internal/domain/models"
+	"mybooks/internal/domain/repositories"
+	"mybooks/internal/infrastructure/helpers"
+	"mybooks/pkg"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type OrganizationService struct {
+	repo repositories.OrganizationRepository
+}
+
+type OrganizationMemberResponse struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewOrganizationService creates a new instance of OrganizationService.
+//
+// Parameters:
+// - repo: The OrganizationRepository implementation used by the service.
+//
+// Returns:
+// - *OrganizationService: A pointer to the newly created OrganizationService instance.
+func NewOrganizationService(repo repositories.OrganizationRepository) *OrganizationService {
+	return &OrganizationService{
+		repo: repo,
+	}
+}
+
+// CreateOrganization creates a new organization owned by the user.
+//
+// It takes a pointer to a gin.Context as a parameter and returns nothing.
+// The function generates a random ID, binds the JSON request body to an Organization struct,
+// validates the struct, creates the organization in the repository, and returns the ID of the created organization.
+func (s *OrganizationService) CreateOrganization(c *gin.Context) {
+	organization := new(models.Organization)
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	id, err := pkg.GenerateRandomID()
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	if err := c.BindJSON(organization); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	organization.ID = id
+	organization.Name = strings.TrimSpace(organization.Name)
+	organization.Personal = false
+	organization.UserID = user.ID
+	organization.User = *user
+
+	if err := pkg.ValidateModelStruct(organization); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.CreateOrganization(organization); err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	data := map[string]interface{}{
+		"id": organization.ID,
+	}
+
+	c.JSON(http.StatusCreated, data)
+}
+
+// GetAllOrganizations retrieves the organizations the user is a member of, with the role of the user in each one.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None
+func (s *OrganizationService) GetAllOrganizations(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	organizations, err := s.repo.GetAllOrganizations(userID.String())
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, organizations)
+}
+
+// UpdateOrganization renames an organization.
+//
+// It takes a pointer to a gin.Context as a parameter and returns nothing.
+// Only owners and admins of the organization can rename it.
+func (s *OrganizationService) UpdateOrganization(c *gin.Context) {
+	id := c.Param("organizationId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	var organization models.Organization
+	if err := c.BindJSON(&organization); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	organizationID, err := uuid.Parse(id)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	organization.ID = organizationID
+	organization.Name = strings.TrimSpace(organization.Name)
+	organization.User = *user
+
+	if err := pkg.ValidateModelStruct(organization); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.UpdateOrganization(userID.String(), &organization); err != nil {
+		s.handleOrganizationError(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// DeleteOrganization deletes an organization by its ID.
+//
+// Only owners can delete an organization. Personal organizations and organizations that still
+// have books, libraries or loans are rejected with a conflict.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None
+func (s *OrganizationService) DeleteOrganization(c *gin.Context) {
+	id := c.Param("organizationId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	if err := s.repo.DeleteOrganization(userID.String(), id); err != nil {
+		s.handleOrganizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Organization deleted successfully"})
+}
+
+// GetOrganizationMembers retrieves the members of an organization with their roles.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None
+func (s *OrganizationService) GetOrganizationMembers(c *gin.Context) {
+	id := c.Param("organizationId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	members, err := s.repo.GetOrganizationMembers(userID.String(), id)
+	if err != nil {
+		s.handleOrganizationError(c, err)
+		return
+	}
+
+	response := make([]OrganizationMemberResponse, 0)
+	for _, member := range *members {
+		response = append(response, OrganizationMemberResponse{
+			UserID:    member.UserID,
+			Email:     member.User.Email,
+			Role:      member.Role,
+			CreatedAt: member.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// AddOrganizationMember adds the registered user with the email given in the request body to an organization.
+//
+// It takes a pointer to a gin.Context as a parameter and returns nothing.
+// Only owners and admins can add members, and only owners can add other owners.
+func (s *OrganizationService) AddOrganizationMember(c *gin.Context) {
+	id := c.Param("organizationId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	var body struct {
+		Email string `json:"email" validate:"required,email,max=100"`
+		Role  string `json:"role" validate:"required,oneof=owner admin member"`
+	}
+
+	if err := c.BindJSON(&body); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	body.Email = strings.TrimSpace(body.Email)
+
+	if err := pkg.ValidateModelStruct(body); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.AddOrganizationMember(userID.String(), id, body.Email, body.Role); err != nil {
+		s.handleOrganizationError(c, err)
+		return
+	}
+
+	c.Status(http.StatusCreated)
+}
+
+// UpdateOrganizationMember changes the role of a member of an organization to the role given in the request body.
+//
+// It takes a pointer to a gin.Context as a parameter and returns nothing.
+// Demoting the last owner is rejected with a conflict.
+func (s *OrganizationService) UpdateOrganizationMember(c *gin.Context) {
+	id := c.Param("organizationId")
+	memberID := c.Param("userId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	var body struct {
+		Role string `json:"role" validate:"required,oneof=owner admin member"`
+	}
+
+	if err := c.BindJSON(&body); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := pkg.ValidateModelStruct(body); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.UpdateOrganizationMember(userID.String(), id, memberID, body.Role); err != nil {
+		s.handleOrganizationError(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// RemoveOrganizationMember removes a member from an organization.
+//
+// Owners and admins can remove members, and any member can remove themselves to leave the organization.
+// Removing the last owner is rejected with a conflict.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None
+func (s *OrganizationService) RemoveOrganizationMember(c *gin.Context) {
+	id := c.Param("organizationId")
+	memberID := c.Param("userId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	if err := s.repo.RemoveOrganizationMember(userID.String(), id, memberID); err != nil {
+		s.handleOrganizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+}
+
+// handleOrganizationError maps the errors of the organization operations to HTTP status codes.
+func (s *OrganizationService) handleOrganizationError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		helpers.HandleError(c, err, http.StatusNotFound)
+	case strings.Contains(err.Error(), "insufficient organization permissions"):
+		helpers.HandleError(c, err, http.StatusForbidden)
+	case strings.Contains(err.Error(), "already a member"),
+		strings.Contains(err.Error(), "at least one owner"),
+		strings.Contains(err.Error(), "personal organization"),
+		strings.Contains(err.Error(), "not empty"):
+		helpers.HandleError(c, err, http.StatusConflict)
+	default:
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+	}
+}
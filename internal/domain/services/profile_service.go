@@ -0,0 +1,191 @@
+package services
+
+import (
+	"errors"
+	"mybooks/internal/domain/models"
+	"mybooks/internal/domain/repositories"
+	"mybooks/internal/infrastructure/helpers"
+	"mybooks/pkg"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProfileService struct {
+	repo repositories.ProfileRepository
+}
+
+// UpdateTimezoneRequest is the body of a request changing the timezone of the user.
+type UpdateTimezoneRequest struct {
+	Timezone string `json:"timezone" validate:"required,timezone"`
+}
+
+// UpdatePublicProfileRequest is the body of a request changing the public profile of the user. The handle is
+// the vanity name in the address of the profile, and the profile is only visible when public.
+type UpdatePublicProfileRequest struct {
+	Handle      string `json:"handle" validate:"omitempty,min=3,max=30,alphanum"`
+	DisplayName string `json:"display_name" validate:"max=100"`
+	Bio         string `json:"bio" validate:"max=500"`
+	Public      bool   `json:"public"`
+}
+
+// PublicProfileResponse is a public profile as shown to visitors, with the public libraries of the user.
+type PublicProfileResponse struct {
+	Handle      string                 `json:"handle"`
+	DisplayName string                 `json:"display_name"`
+	Bio         string                 `json:"bio"`
+	Libraries   []PublicLibrarySummary `json:"libraries"`
+}
+
+// NewProfileService creates a new instance of the ProfileService struct.
+//
+// It takes a ProfileRepository as a parameter and returns a pointer to a ProfileService.
+func NewProfileService(repo repositories.ProfileRepository) *ProfileService {
+	return &ProfileService{
+		repo: repo,
+	}
+}
+
+// UpdateTimezone changes the timezone of the user, an IANA name such as "Europe/Paris". Reading days,
+// streaks and the years of the reading goals follow the timezone of the user.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ProfileService) UpdateTimezone(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	var request UpdateTimezoneRequest
+	if err := c.BindJSON(&request); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := pkg.ValidateModelStruct(request); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.UpdateTimezone(user.ID, request.Timezone); err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Timezone updated successfully"})
+}
+
+// UpdatePublicProfile changes the handle, display name and bio of the user and whether their profile is public.
+// The handle is stored in lowercase and must be unique, and a profile needs a handle to be public. An empty
+// handle removes it.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ProfileService) UpdatePublicProfile(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	var request UpdatePublicProfileRequest
+	if err := c.BindJSON(&request); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	request.Handle = strings.ToLower(strings.TrimSpace(request.Handle))
+	request.DisplayName = strings.TrimSpace(request.DisplayName)
+	request.Bio = strings.TrimSpace(request.Bio)
+
+	if err := pkg.ValidateModelStruct(request); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if request.Public && request.Handle == "" {
+		helpers.HandleError(c, errors.New("a handle is required to make the profile public"), http.StatusUnprocessableEntity)
+		return
+	}
+
+	user.Handle = nil
+	if request.Handle != "" {
+		user.Handle = &request.Handle
+	}
+	user.DisplayName = request.DisplayName
+	user.Bio = request.Bio
+	user.Public = request.Public
+
+	if err := s.repo.UpdatePublicProfile(user); err != nil {
+		if strings.Contains(err.Error(), "already taken") {
+			helpers.HandleError(c, err, http.StatusConflict)
+			return
+		}
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"handle":       user.Handle,
+		"display_name": user.DisplayName,
+		"bio":          user.Bio,
+		"public":       user.Public,
+	})
+}
+
+// GetPublicProfile retrieves a public profile by its handle, with the public libraries of the user. It needs no
+// authentication, and a private profile is not found.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ProfileService) GetPublicProfile(c *gin.Context) {
+	user, libraries, err := s.repo.GetPublicProfile(strings.ToLower(c.Param("handle")))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			helpers.HandleError(c, err, http.StatusNotFound)
+			return
+		}
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	response := PublicProfileResponse{
+		Handle:      *user.Handle,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		Libraries:   make([]PublicLibrarySummary, 0),
+	}
+
+	for _, library := range *libraries {
+		response.Libraries = append(response.Libraries, buildPublicLibrarySummary(library))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// userLocation returns the location of the timezone of a user, UTC when the user has none.
+func userLocation(user *models.User) *time.Location {
+	if user.Timezone == "" {
+		return time.UTC
+	}
+
+	location, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return location
+}
@@ -0,0 +1,525 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"log"
+	"math"
+	"mybooks/internal/domain/events"
+	"mybooks/internal/domain/models"
+	"mybooks/internal/domain/repositories"
+	"mybooks/internal/infrastructure/helpers"
+	"mybooks/pkg"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// readingDaysLimit is the longest period, in days, the daily reading time can be requested for.
+const readingDaysLimit = 366
+
+type ReadingSessionService struct {
+	repo repositories.ReadingSessionRepository
+}
+
+// StopReadingSessionRequest is the optional body of a request stopping a reading session.
+type StopReadingSessionRequest struct {
+	EndPage *int `json:"end_page" validate:"omitempty,min=0"`
+}
+
+// BookReadingSessionsResponse is the reading sessions of a user on a book with their statistics.
+type BookReadingSessionsResponse struct {
+	Stats    models.BookReadingStats `json:"stats"`
+	Sessions []models.ReadingSession `json:"sessions"`
+}
+
+// ReadingDaysResponse is the reading time of a user per day over a period, including the days without reading.
+type ReadingDaysResponse struct {
+	From     string              `json:"from"`
+	To       string              `json:"to"`
+	Duration int64               `json:"duration"`
+	Pages    int64               `json:"pages"`
+	Days     []models.ReadingDay `json:"days"`
+}
+
+// NewReadingSessionService creates a new instance of the ReadingSessionService struct.
+//
+// It takes a ReadingSessionRepository as a parameter and returns a pointer to a ReadingSessionService.
+func NewReadingSessionService(repo repositories.ReadingSessionRepository) *ReadingSessionService {
+	return &ReadingSessionService{
+		repo: repo,
+	}
+}
+
+// StartReadingSession starts a reading session of the user on a book of the active organization.
+//
+// It takes a gin.Context as a parameter and returns nothing.
+// The request body is optional and may give the "start_page". Without it the session starts where the
+// previous session on the book ended. The function returns the ID of the started session.
+func (s *ReadingSessionService) StartReadingSession(c *gin.Context) {
+	bookID := c.Param("bookId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	id, err := pkg.GenerateRandomID()
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	session := new(models.ReadingSession)
+	if err := c.ShouldBindJSON(session); err != nil && !errors.Is(err, io.EOF) {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	bookUUID, err := uuid.Parse(bookID)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	*session = models.ReadingSession{
+		ID:        id,
+		BookID:    bookUUID,
+		StartPage: session.StartPage,
+		StartedAt: time.Now(),
+		UserID:    user.ID,
+		User:      *user,
+	}
+
+	if err := pkg.ValidateModelStruct(session); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.StartReadingSession(orgID.String(), readingSessionMaxDuration(), session); err != nil {
+		s.handleReadingSessionError(c, err)
+		return
+	}
+
+	events.Publish(events.Event{Kind: events.ReadingStarted, UserID: user.ID, BookID: bookUUID, At: session.StartedAt})
+
+	c.JSON(http.StatusCreated, map[string]interface{}{
+		"id": session.ID,
+	})
+}
+
+// StopReadingSession stops the reading session of the user in progress on a book of the active organization.
+//
+// It takes a gin.Context as a parameter and returns nothing.
+// The request body is optional and may give the "end_page". A session forgotten in progress for longer than
+// the maximum duration ends at its start time plus the maximum duration. The function returns the session.
+func (s *ReadingSessionService) StopReadingSession(c *gin.Context) {
+	bookID := c.Param("bookId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	var request StopReadingSessionRequest
+	if err := c.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := pkg.ValidateModelStruct(request); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	session, err := s.repo.StopReadingSession(userID.String(), orgID.String(), bookID, request.EndPage, readingSessionMaxDuration())
+	if err != nil {
+		s.handleReadingSessionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// GetBookReadingSessions retrieves the reading sessions of the user on a book of the active organization,
+// most recent first, with the pages read, the reading speed in pages per hour and the estimated time
+// to finish the book.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ReadingSessionService) GetBookReadingSessions(c *gin.Context) {
+	bookID := c.Param("bookId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	book, sessions, err := s.repo.GetBookReadingSessions(userID.String(), orgID.String(), bookID)
+	if err != nil {
+		s.handleReadingSessionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, BookReadingSessionsResponse{
+		Stats:    bookReadingStats(book, *sessions),
+		Sessions: *sessions,
+	})
+}
+
+// GetReadingSessions retrieves the reading sessions of the user on the books of the active organization,
+// most recent first.
+//
+// The "book_id" query parameter restricts the sessions to a book, and the "from" and "to" query parameters,
+// as YYYY-MM-DD dates in the timezone of the user, to the sessions started during these days.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ReadingSessionService) GetReadingSessions(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	filters := make(map[string]interface{})
+
+	if bookID := c.Query("book_id"); bookID != "" {
+		if _, err := uuid.Parse(bookID); err != nil {
+			helpers.HandleError(c, err, http.StatusBadRequest)
+			return
+		}
+		filters["book_id"] = bookID
+	}
+	if from := c.Query("from"); from != "" {
+		date, err := time.ParseInLocation(time.DateOnly, from, userLocation(user))
+		if err != nil {
+			helpers.HandleError(c, err, http.StatusBadRequest)
+			return
+		}
+		filters["from"] = date
+	}
+	if to := c.Query("to"); to != "" {
+		date, err := time.ParseInLocation(time.DateOnly, to, userLocation(user))
+		if err != nil {
+			helpers.HandleError(c, err, http.StatusBadRequest)
+			return
+		}
+		filters["to"] = date.AddDate(0, 0, 1)
+	}
+
+	sessions, err := s.repo.GetReadingSessions(userID.String(), orgID.String(), filters)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// GetReadingDays retrieves the reading time and the pages read by the user on the books of the active
+// organization for every day of a period, including the days without reading.
+//
+// The "from" and "to" query parameters are YYYY-MM-DD dates, in the timezone of the user, and default to
+// the last 30 days.
+// The period may not be longer than a year.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ReadingSessionService) GetReadingDays(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	location := userLocation(user)
+	now := time.Now().In(location)
+
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+	if value := c.Query("to"); value != "" {
+		if to, err = time.ParseInLocation(time.DateOnly, value, location); err != nil {
+			helpers.HandleError(c, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	from := to.AddDate(0, 0, -29)
+	if value := c.Query("from"); value != "" {
+		if from, err = time.ParseInLocation(time.DateOnly, value, location); err != nil {
+			helpers.HandleError(c, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	if from.After(to) || !to.Before(from.AddDate(0, 0, readingDaysLimit)) {
+		helpers.HandleError(c, errors.New("the period must be between 1 and 366 days"), http.StatusBadRequest)
+		return
+	}
+
+	days, err := s.repo.GetReadingDays(userID.String(), orgID.String(), location.String(), from, to.AddDate(0, 0, 1))
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	response := ReadingDaysResponse{
+		From: from.Format(time.DateOnly),
+		To:   to.Format(time.DateOnly),
+		Days: make([]models.ReadingDay, 0),
+	}
+
+	byDate := make(map[string]models.ReadingDay)
+	for _, day := range *days {
+		byDate[day.Date] = day
+		response.Duration += day.Duration
+		response.Pages += day.Pages
+	}
+
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		day, ok := byDate[date.Format(time.DateOnly)]
+		if !ok {
+			day = models.ReadingDay{Date: date.Format(time.DateOnly)}
+		}
+		response.Days = append(response.Days, day)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateReadingSession corrects the start and end pages and the duration, in seconds, of a stopped reading
+// session of the user.
+//
+// It takes a gin.Context as a parameter and returns nothing.
+func (s *ReadingSessionService) UpdateReadingSession(c *gin.Context) {
+	id := c.Param("sessionId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	var session models.ReadingSession
+	if err := c.BindJSON(&session); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	sessionID, err := uuid.Parse(id)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	session.ID = sessionID
+	session.UserID = userID
+	session.User = *user
+
+	if err := pkg.ValidateModelStruct(session); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if session.Duration <= 0 {
+		helpers.HandleError(c, errors.New("duration must be a positive number of seconds"), http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.UpdateReadingSession(userID.String(), orgID.String(), &session); err != nil {
+		s.handleReadingSessionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Reading session updated successfully"})
+}
+
+// DeleteReadingSession deletes a reading session of the user.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ReadingSessionService) DeleteReadingSession(c *gin.Context) {
+	id := c.Param("sessionId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	if err := s.repo.DeleteReadingSession(userID.String(), orgID.String(), id); err != nil {
+		s.handleReadingSessionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Reading session deleted successfully"})
+}
+
+// StopForgottenReadingSessions periodically stops the reading sessions forgotten in progress for longer
+// than the maximum duration. It runs until the program exits.
+//
+// Parameters:
+// - interval: the time between two runs.
+//
+// Returns:
+// - None
+func (s *ReadingSessionService) StopForgottenReadingSessions(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if _, err := s.repo.StopForgottenReadingSessions(readingSessionMaxDuration()); err != nil {
+			log.Println("Error stopping forgotten reading sessions:", err)
+		}
+	}
+}
+
+// handleReadingSessionError maps the errors of the reading session operations to HTTP status codes.
+func (s *ReadingSessionService) handleReadingSessionError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		helpers.HandleError(c, err, http.StatusNotFound)
+	case strings.Contains(err.Error(), "already in progress"),
+		strings.Contains(err.Error(), "no reading session in progress"),
+		strings.Contains(err.Error(), "is in progress"):
+		helpers.HandleError(c, err, http.StatusConflict)
+	case strings.Contains(err.Error(), "end page"):
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+	default:
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+	}
+}
+
+// readingSessionMaxDuration returns the longest a reading session may last, read from the
+// READING_SESSION_MAX_DURATION environment variable as a duration such as "3h" or "90m".
+func readingSessionMaxDuration() time.Duration {
+	if value, err := time.ParseDuration(os.Getenv("READING_SESSION_MAX_DURATION")); err == nil && value > 0 {
+		return value
+	}
+
+	return models.ReadingSessionMaxDuration
+}
+
+// bookReadingStats sums up the reading sessions of a user on a book, given most recent first.
+//
+// The current page is the end page of the latest session having one. The reading speed only counts the
+// stopped sessions with an end page, and the estimated time to finish reads the remaining pages at that speed.
+func bookReadingStats(book *models.Book, sessions []models.ReadingSession) models.BookReadingStats {
+	stats := models.BookReadingStats{BookID: book.ID, Pages: book.Pages}
+
+	var timedPages, timedDuration int64
+	for _, session := range sessions {
+		if session.EndedAt == nil {
+			continue
+		}
+
+		stats.Sessions++
+		stats.Duration += session.Duration
+
+		if session.EndPage == nil {
+			continue
+		}
+
+		if stats.CurrentPage == 0 {
+			stats.CurrentPage = *session.EndPage
+		}
+
+		pages := int64(max(*session.EndPage-session.StartPage, 0))
+		stats.PagesRead += pages
+		if session.Duration > 0 {
+			timedPages += pages
+			timedDuration += session.Duration
+		}
+	}
+
+	if timedPages > 0 {
+		pagesPerHour := math.Round(float64(timedPages)/(float64(timedDuration)/3600)*10) / 10
+		stats.PagesPerHour = &pagesPerHour
+	}
+
+	if book.Pages > 0 {
+		remaining := max(book.Pages-stats.CurrentPage, 0)
+		stats.RemainingPages = &remaining
+
+		if timedPages > 0 {
+			estimated := int64(math.Round(float64(remaining) * float64(timedDuration) / float64(timedPages)))
+			stats.EstimatedSeconds = &estimated
+		}
+	}
+
+	return stats
+}
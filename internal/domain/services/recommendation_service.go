@@ -0,0 +1,386 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"mybooks/internal/domain/models"
+	"mybooks/internal/domain/repositories"
+	"mybooks/internal/infrastructure/helpers"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// The number of recommendations returned, by default and at most.
+const (
+	recommendationDefaultLimit = 10
+	recommendationMaxLimit     = 50
+)
+
+// The weights of the signals of the recommendations: how much a book is moved up by being by a favorite author,
+// in a favorite genre, next in a series the user is reading, of the length the user enjoys, and waiting on the
+// shelf for two years or more.
+const (
+	recommendationAuthorWeight  = 3.0
+	recommendationGenreWeight   = 2.0
+	recommendationSeriesWeight  = 4.0
+	recommendationLengthWeight  = 1.0
+	recommendationWaitingWeight = 1.0
+)
+
+// recommendationHighRating is the lowest rating a user gives the books they enjoyed.
+const recommendationHighRating = 4.0
+
+type RecommendationService struct {
+	repo repositories.RecommendationRepository
+}
+
+// NewRecommendationService creates a new instance of the RecommendationService struct.
+//
+// It takes a RecommendationRepository as a parameter and returns a pointer to a RecommendationService.
+func NewRecommendationService(repo repositories.RecommendationRepository) *RecommendationService {
+	return &RecommendationService{
+		repo: repo,
+	}
+}
+
+// GetRecommendations ranks the unread books of the active organization to tell the user what to read next,
+// from their ratings, favorite authors and genres, the series they are reading, the length of the books they
+// enjoy and how long each book has been waiting. Each recommendation explains why it was suggested.
+//
+// The "limit" query parameter is the number of recommendations, 10 by default and 50 at most.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *RecommendationService) GetRecommendations(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	limit := recommendationDefaultLimit
+	if value := c.Query("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > recommendationMaxLimit {
+			helpers.HandleError(c, errors.New("limit must be between 1 and 50"), http.StatusBadRequest)
+			return
+		}
+	}
+
+	books, err := s.repo.GetRecommendationBooks(user.ID.String(), orgID.String())
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, rankRecommendations(*books, time.Now(), limit))
+}
+
+// recommendationTaste counts the books of an author or a genre the user read and the ratings they gave them.
+type recommendationTaste struct {
+	name   string
+	read   int
+	rated  int
+	high   int
+	rating float64
+}
+
+// affinity is how much the user likes an author or a genre, from -1.25 to 1: the average rating moved around
+// 3 stars and trusted fully from 3 ratings on, or a slight preference for what the user read without rating.
+func (t *recommendationTaste) affinity() float64 {
+	if t.rated > 0 {
+		return (t.rating/float64(t.rated) - 3) / 2 * math.Min(float64(t.rated), 3) / 3
+	}
+
+	return 0.25 * math.Min(float64(t.read), 3) / 3
+}
+
+// recommendationReason is a reason a book is recommended, with its contribution to the score.
+type recommendationReason struct {
+	score float64
+	text  string
+}
+
+// rankRecommendations ranks the unread books of a collection from what the user read and rated, best first.
+// It only depends on its arguments, so the same collection at the same time always gives the same ranking:
+// books with the same score are ranked by how long they have been waiting and then by ID.
+//
+// A book the user rated counts as read, even if it is not marked as read.
+//
+// Parameters:
+// - books: the books of the collection, read and unread.
+// - now: the time the waiting time of the books is measured at.
+// - limit: the largest number of recommendations.
+//
+// Returns:
+// - []models.Recommendation: the recommendations, best first.
+func rankRecommendations(books []models.RecommendationBook, now time.Time, limit int) []models.Recommendation {
+	authors := make(map[string]*recommendationTaste)
+	genres := make(map[string]*recommendationTaste)
+	seriesRead := make(map[uuid.UUID]float64)
+	var enjoyedPages, readPages []int
+
+	unread := make([]models.RecommendationBook, 0, len(books))
+	for _, book := range books {
+		if !book.Read && book.Rating == nil {
+			unread = append(unread, book)
+			continue
+		}
+
+		for _, author := range recommendationAuthors(book) {
+			addRecommendationTaste(authors, author, book.Rating)
+		}
+		if genre := strings.TrimSpace(book.Genre); genre != "" {
+			addRecommendationTaste(genres, genre, book.Rating)
+		}
+
+		for _, series := range book.Series {
+			if position, ok := seriesRead[series.SeriesID]; !ok || series.Position > position {
+				seriesRead[series.SeriesID] = series.Position
+			}
+		}
+
+		if book.Pages > 0 {
+			readPages = append(readPages, book.Pages)
+			if book.Rating != nil && *book.Rating >= recommendationHighRating {
+				enjoyedPages = append(enjoyedPages, book.Pages)
+			}
+		}
+	}
+
+	// The first unread volume of every series, after the last volume read if the user started it
+	seriesNext := make(map[uuid.UUID]float64)
+	for _, book := range unread {
+		for _, series := range book.Series {
+			if position, ok := seriesRead[series.SeriesID]; ok && series.Position <= position {
+				continue
+			}
+			if position, ok := seriesNext[series.SeriesID]; !ok || series.Position < position {
+				seriesNext[series.SeriesID] = series.Position
+			}
+		}
+	}
+
+	// The length of the books the user enjoyed, or of the books they read when they rated none highly
+	lengthPages, lengthBooks := median(enjoyedPages), "the books you enjoyed"
+	if lengthPages == 0 {
+		lengthPages, lengthBooks = median(readPages), "the books you read"
+	}
+
+	type ranked struct {
+		book   models.RecommendationBook
+		score  float64
+		reason []recommendationReason
+	}
+
+	candidates := make([]ranked, 0, len(unread))
+	for _, book := range unread {
+		candidate := ranked{book: book}
+		add := func(score float64, text string) {
+			candidate.score += score
+			if text != "" && score > 0 {
+				candidate.reason = append(candidate.reason, recommendationReason{score: score, text: text})
+			}
+		}
+
+		// Favorite authors: the author the user likes the most among the credited ones
+		var author *recommendationTaste
+		for _, name := range recommendationAuthors(book) {
+			if taste, ok := authors[strings.ToLower(name)]; ok && (author == nil || taste.affinity() > author.affinity()) {
+				author = taste
+			}
+		}
+		if author != nil {
+			add(recommendationAuthorWeight*author.affinity(), tasteReason(author, func(count int) string {
+				return pluralize(count, "book") + " by " + author.name
+			}))
+		}
+
+		// Favorite genres
+		if taste, ok := genres[strings.ToLower(strings.TrimSpace(book.Genre))]; ok {
+			add(recommendationGenreWeight*taste.affinity(), tasteReason(taste, func(count int) string {
+				return pluralize(count, taste.name+" book")
+			}))
+		}
+
+		// Series: the next volume of a series the user is reading goes first, and volumes with an earlier unread
+		// one go down, so series are read in order
+		for _, series := range book.Series {
+			read, started := seriesRead[series.SeriesID]
+			if started && series.Position <= read {
+				// A volume skipped before the last one read is neither next nor read out of order
+				continue
+			}
+			if series.Position > seriesNext[series.SeriesID] {
+				add(-recommendationSeriesWeight/4, "")
+				break
+			}
+			if started {
+				add(recommendationSeriesWeight, fmt.Sprintf("because it is next in the %s series you are reading", series.Name))
+				break
+			}
+		}
+
+		// Length: as long as the books the user enjoys, down to nothing at half or twice their length
+		if lengthPages > 0 && book.Pages > 0 {
+			closeness := math.Max(0, 1-math.Abs(math.Log(float64(book.Pages)/float64(lengthPages)))/math.Ln2)
+			text := ""
+			if closeness >= 0.75 {
+				text = fmt.Sprintf("because its %d pages are close to the length of %s (about %d pages)", book.Pages, lengthBooks, lengthPages)
+			}
+			add(recommendationLengthWeight*closeness, text)
+		}
+
+		// Waiting time: the longer a book has been waiting, up to two years
+		days := now.Sub(book.CreatedAt).Hours() / 24
+		if days > 0 {
+			text := ""
+			if days >= 180 {
+				text = "because it has been waiting on your shelf for " + waitingTime(days)
+			}
+			add(recommendationWaitingWeight*math.Min(days/730, 1), text)
+		}
+
+		candidates = append(candidates, candidate)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		if !candidates[i].book.CreatedAt.Equal(candidates[j].book.CreatedAt) {
+			return candidates[i].book.CreatedAt.Before(candidates[j].book.CreatedAt)
+		}
+		return candidates[i].book.ID.String() < candidates[j].book.ID.String()
+	})
+
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	recommendations := make([]models.Recommendation, 0, len(candidates))
+	for _, candidate := range candidates {
+		sort.SliceStable(candidate.reason, func(i, j int) bool {
+			return candidate.reason[i].score > candidate.reason[j].score
+		})
+
+		reasons := make([]string, 0, len(candidate.reason))
+		for _, reason := range candidate.reason {
+			reasons = append(reasons, reason.text)
+		}
+
+		recommendations = append(recommendations, models.Recommendation{
+			BookID:  candidate.book.ID,
+			Title:   candidate.book.Title,
+			Author:  candidate.book.Author,
+			Cover:   candidate.book.Cover,
+			Genre:   candidate.book.Genre,
+			Pages:   candidate.book.Pages,
+			Score:   math.Round(candidate.score*100) / 100,
+			Reasons: reasons,
+		})
+	}
+
+	return recommendations
+}
+
+// recommendationAuthors returns the credited authors of a book, or its author when it has no credits.
+func recommendationAuthors(book models.RecommendationBook) []string {
+	if len(book.Authors) > 0 {
+		return book.Authors
+	}
+
+	if author := strings.TrimSpace(book.Author); author != "" {
+		return []string{author}
+	}
+
+	return nil
+}
+
+// addRecommendationTaste counts a read book, and its rating if the user rated it, for an author or a genre.
+// Names are compared without case, keeping the spelling of the oldest book.
+func addRecommendationTaste(tastes map[string]*recommendationTaste, name string, rating *float64) {
+	key := strings.ToLower(name)
+
+	taste, ok := tastes[key]
+	if !ok {
+		taste = &recommendationTaste{name: name}
+		tastes[key] = taste
+	}
+
+	taste.read++
+	if rating != nil {
+		taste.rated++
+		taste.rating += *rating
+		if *rating >= recommendationHighRating {
+			taste.high++
+		}
+	}
+}
+
+// tasteReason explains a favorite author or genre, e.g. "because you rated 3 books by X highly", given how
+// to name a number of its books.
+func tasteReason(taste *recommendationTaste, books func(count int) string) string {
+	if taste.high > 0 {
+		return fmt.Sprintf("because you rated %s highly", books(taste.high))
+	}
+
+	if taste.rated == 0 && taste.read > 1 {
+		return fmt.Sprintf("because you read %s", books(taste.read))
+	}
+
+	return ""
+}
+
+// waitingTime formats a number of days in months, or in years from two years on.
+func waitingTime(days float64) string {
+	months := int(days / 30)
+	if months < 24 {
+		return pluralize(months, "month")
+	}
+
+	return pluralize(int(days/365), "year")
+}
+
+// pluralize formats a count of a noun, e.g. "1 book" or "3 books".
+func pluralize(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+
+	return fmt.Sprintf("%d %ss", count, noun)
+}
+
+// median returns the median of values, rounded down, or 0 without values.
+func median(values []int) int {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+
+	return sorted[middle]
+}
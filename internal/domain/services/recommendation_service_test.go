@@ -0,0 +1,208 @@
+package services
+
+import (
+	"fmt"
+	"mybooks/internal/domain/models"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var recommendationNow = time.Date(2026, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+// recommendationID returns a fixed book or series ID, so that the tie-breaks on IDs are predictable.
+func recommendationID(n int) uuid.UUID {
+	return uuid.MustParse(fmt.Sprintf("00000000-0000-0000-0000-%012d", n))
+}
+
+func recommendationRating(rating float64) *float64 {
+	return &rating
+}
+
+func recommendationSeries(n int, name string, position float64) []models.RecommendationSeries {
+	return []models.RecommendationSeries{{SeriesID: recommendationID(1000 + n), Name: name, Position: position}}
+}
+
+// daysAgo returns the time a book was added the given number of days before the ranking.
+func daysAgo(days int) time.Time {
+	return recommendationNow.AddDate(0, 0, -days)
+}
+
+func TestRankRecommendations(t *testing.T) {
+	tests := []struct {
+		name    string
+		books   []models.RecommendationBook
+		limit   int
+		want    []string
+		reasons map[string][]string
+	}{
+		{
+			name: "books with the same score are ranked by waiting time, then by ID",
+			books: []models.RecommendationBook{
+				{ID: recommendationID(1), Title: "Newest", CreatedAt: daysAgo(3 * 365)},
+				{ID: recommendationID(9), Title: "Same date, higher ID", CreatedAt: daysAgo(4 * 365)},
+				{ID: recommendationID(2), Title: "Oldest", CreatedAt: daysAgo(5 * 365)},
+				{ID: recommendationID(3), Title: "Same date, lower ID", CreatedAt: daysAgo(4 * 365)},
+			},
+			limit: 10,
+			want:  []string{"Oldest", "Same date, lower ID", "Same date, higher ID", "Newest"},
+			reasons: map[string][]string{
+				"Oldest": {"because it has been waiting on your shelf for 5 years"},
+				"Newest": {"because it has been waiting on your shelf for 3 years"},
+			},
+		},
+		{
+			name: "the next volume of a started series goes first and later volumes go down",
+			books: []models.RecommendationBook{
+				{ID: recommendationID(1), Title: "Earthsea 1", Read: true, Series: recommendationSeries(1, "Earthsea", 1), CreatedAt: daysAgo(10)},
+				{ID: recommendationID(2), Title: "Earthsea 2", Series: recommendationSeries(1, "Earthsea", 2), CreatedAt: daysAgo(10)},
+				{ID: recommendationID(3), Title: "Earthsea 3", Series: recommendationSeries(1, "Earthsea", 3), CreatedAt: daysAgo(10)},
+				{ID: recommendationID(4), Title: "Discworld 1", Series: recommendationSeries(2, "Discworld", 1), CreatedAt: daysAgo(10)},
+				{ID: recommendationID(5), Title: "Discworld 2", Series: recommendationSeries(2, "Discworld", 2), CreatedAt: daysAgo(10)},
+			},
+			limit: 10,
+			want:  []string{"Earthsea 2", "Discworld 1", "Earthsea 3", "Discworld 2"},
+			reasons: map[string][]string{
+				"Earthsea 2":  {"because it is next in the Earthsea series you are reading"},
+				"Discworld 1": {},
+				"Earthsea 3":  {},
+			},
+		},
+		{
+			name: "a volume skipped before the last one read is neither next nor read out of order",
+			books: []models.RecommendationBook{
+				{ID: recommendationID(1), Title: "Volume 1", Read: true, Series: recommendationSeries(1, "Saga", 1), CreatedAt: daysAgo(10)},
+				{ID: recommendationID(2), Title: "Volume 2", Series: recommendationSeries(1, "Saga", 2), CreatedAt: daysAgo(10)},
+				{ID: recommendationID(3), Title: "Volume 3", Read: true, Series: recommendationSeries(1, "Saga", 3), CreatedAt: daysAgo(10)},
+				{ID: recommendationID(4), Title: "Volume 4", Series: recommendationSeries(1, "Saga", 4), CreatedAt: daysAgo(10)},
+				{ID: recommendationID(5), Title: "Volume 5", Series: recommendationSeries(1, "Saga", 5), CreatedAt: daysAgo(10)},
+				{ID: recommendationID(6), Title: "Standalone", CreatedAt: daysAgo(10)},
+			},
+			limit: 10,
+			want:  []string{"Volume 4", "Volume 2", "Standalone", "Volume 5"},
+			reasons: map[string][]string{
+				"Volume 4": {"because it is next in the Saga series you are reading"},
+				"Volume 2": {},
+			},
+		},
+		{
+			name: "favorite authors and genres, length and waiting time are explained, most important first",
+			books: []models.RecommendationBook{
+				{ID: recommendationID(1), Title: "A Wizard of Earthsea", Authors: []string{"Ursula K. Le Guin"}, Genre: "Fantasy", Pages: 250, Rating: recommendationRating(5), CreatedAt: daysAgo(1000)},
+				{ID: recommendationID(2), Title: "The Tombs of Atuan", Authors: []string{"Ursula K. Le Guin"}, Genre: "Fantasy", Pages: 300, Read: true, Rating: recommendationRating(4), CreatedAt: daysAgo(1000)},
+				{ID: recommendationID(3), Title: "The Left Hand of Darkness", Authors: []string{"Ursula K. Le Guin"}, Genre: "Fantasy", Pages: 260, CreatedAt: daysAgo(365)},
+				{ID: recommendationID(4), Title: "Guards! Guards!", Author: "Terry Pratchett", Read: true, CreatedAt: daysAgo(1000)},
+				{ID: recommendationID(5), Title: "Mort", Author: "Terry Pratchett", Read: true, CreatedAt: daysAgo(1000)},
+				{ID: recommendationID(6), Title: "Small Gods", Author: "Terry Pratchett", CreatedAt: daysAgo(1)},
+				{ID: recommendationID(7), Title: "Dune", Author: "Frank Herbert", Read: true, Rating: recommendationRating(4.5), CreatedAt: daysAgo(1000)},
+				{ID: recommendationID(8), Title: "Children of Dune", Author: "Frank Herbert", CreatedAt: daysAgo(1)},
+				{ID: recommendationID(9), Title: "Something New", Author: "Someone Else", CreatedAt: daysAgo(1)},
+			},
+			limit: 10,
+			want:  []string{"The Left Hand of Darkness", "Children of Dune", "Small Gods", "Something New"},
+			reasons: map[string][]string{
+				"The Left Hand of Darkness": {
+					"because you rated 2 books by Ursula K. Le Guin highly",
+					"because you rated 2 Fantasy books highly",
+					"because its 260 pages are close to the length of the books you enjoyed (about 275 pages)",
+					"because it has been waiting on your shelf for 12 months",
+				},
+				"Small Gods":       {"because you read 2 books by Terry Pratchett"},
+				"Children of Dune": {"because you rated 1 book by Frank Herbert highly"},
+				"Something New":    {},
+			},
+		},
+		{
+			name: "authors and genres rated poorly go down",
+			books: []models.RecommendationBook{
+				{ID: recommendationID(1), Title: "Disliked", Author: "Author A", Genre: "Horror", Read: true, Rating: recommendationRating(1), CreatedAt: daysAgo(100)},
+				{ID: recommendationID(2), Title: "By the same author", Author: "Author A", CreatedAt: daysAgo(100)},
+				{ID: recommendationID(3), Title: "In the same genre", Author: "Author B", Genre: "Horror", CreatedAt: daysAgo(100)},
+				{ID: recommendationID(4), Title: "Unrelated", Author: "Author C", CreatedAt: daysAgo(100)},
+			},
+			limit: 10,
+			want:  []string{"Unrelated", "In the same genre", "By the same author"},
+			reasons: map[string][]string{
+				"By the same author": {},
+				"In the same genre":  {},
+			},
+		},
+		{
+			name: "the number of recommendations is limited",
+			books: []models.RecommendationBook{
+				{ID: recommendationID(1), Title: "First", CreatedAt: daysAgo(300)},
+				{ID: recommendationID(2), Title: "Second", CreatedAt: daysAgo(200)},
+				{ID: recommendationID(3), Title: "Third", CreatedAt: daysAgo(100)},
+			},
+			limit: 2,
+			want:  []string{"First", "Second"},
+		},
+		{
+			name: "read and rated books are not recommended",
+			books: []models.RecommendationBook{
+				{ID: recommendationID(1), Title: "Read", Read: true, CreatedAt: daysAgo(100)},
+				{ID: recommendationID(2), Title: "Rated", Rating: recommendationRating(3), CreatedAt: daysAgo(100)},
+			},
+			limit: 10,
+			want:  []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recommendations := rankRecommendations(test.books, recommendationNow, test.limit)
+
+			titles := make([]string, 0, len(recommendations))
+			reasons := make(map[string][]string)
+			for _, recommendation := range recommendations {
+				titles = append(titles, recommendation.Title)
+				reasons[recommendation.Title] = recommendation.Reasons
+			}
+
+			if !reflect.DeepEqual(titles, test.want) {
+				t.Fatalf("rankRecommendations() ranked %q, want %q", titles, test.want)
+			}
+
+			for title, want := range test.reasons {
+				if got := reasons[title]; !reflect.DeepEqual(got, want) {
+					t.Errorf("rankRecommendations() gave %q the reasons %q, want %q", title, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRankRecommendationsIsDeterministic(t *testing.T) {
+	books := []models.RecommendationBook{
+		{ID: recommendationID(1), Title: "A Wizard of Earthsea", Authors: []string{"Ursula K. Le Guin"}, Genre: "Fantasy", Pages: 250, Read: true, Rating: recommendationRating(5), Series: recommendationSeries(1, "Earthsea", 1), CreatedAt: daysAgo(1000)},
+		{ID: recommendationID(2), Title: "The Tombs of Atuan", Authors: []string{"Ursula K. Le Guin"}, Genre: "Fantasy", Pages: 300, Series: recommendationSeries(1, "Earthsea", 2), CreatedAt: daysAgo(900)},
+		{ID: recommendationID(3), Title: "The Farthest Shore", Authors: []string{"Ursula K. Le Guin"}, Genre: "Fantasy", Pages: 260, Series: recommendationSeries(1, "Earthsea", 3), CreatedAt: daysAgo(900)},
+		{ID: recommendationID(4), Title: "Dune", Author: "Frank Herbert", Genre: "Science Fiction", Pages: 600, Read: true, Rating: recommendationRating(2), CreatedAt: daysAgo(800)},
+		{ID: recommendationID(5), Title: "Hyperion", Author: "Dan Simmons", Genre: "Science Fiction", Pages: 480, CreatedAt: daysAgo(400)},
+		{ID: recommendationID(6), Title: "Neuromancer", Author: "William Gibson", Genre: "Science Fiction", Pages: 270, CreatedAt: daysAgo(400)},
+		{ID: recommendationID(7), Title: "Circe", Author: "Madeline Miller", Genre: "Fantasy", Pages: 390, CreatedAt: daysAgo(400)},
+		{ID: recommendationID(8), Title: "Piranesi", Author: "Susanna Clarke", Genre: "Fantasy", Pages: 270, CreatedAt: daysAgo(400)},
+	}
+
+	want := rankRecommendations(books, recommendationNow, 10)
+	if len(want) != 6 {
+		t.Fatalf("rankRecommendations() returned %d recommendations, want 6", len(want))
+	}
+
+	reversed := make([]models.RecommendationBook, len(books))
+	for i, book := range books {
+		reversed[len(books)-1-i] = book
+	}
+
+	for i := 0; i < 10; i++ {
+		if got := rankRecommendations(books, recommendationNow, 10); !reflect.DeepEqual(got, want) {
+			t.Fatalf("rankRecommendations() returned %v, then %v", want, got)
+		}
+
+		if got := rankRecommendations(reversed, recommendationNow, 10); !reflect.DeepEqual(got, want) {
+			t.Fatalf("rankRecommendations() of the reversed books returned %v, want %v", got, want)
+		}
+	}
+}
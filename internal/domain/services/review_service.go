@@ -0,0 +1,215 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"mybooks/internal/domain/events"
+	"mybooks/internal/domain/models"
+	"mybooks/internal/domain/repositories"
+	"mybooks/internal/infrastructure/helpers"
+	"mybooks/pkg"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ReviewService struct {
+	repo repositories.ReviewRepository
+}
+
+// NewReviewService creates a new instance of the ReviewService struct.
+//
+// It takes a ReviewRepository as a parameter and returns a pointer to a ReviewService.
+func NewReviewService(repo repositories.ReviewRepository) *ReviewService {
+	return &ReviewService{
+		repo: repo,
+	}
+}
+
+// SetReview rates and reviews a book of the active organization, replacing the previous review of the user.
+//
+// It takes a gin.Context as a parameter and returns nothing.
+// The function binds the JSON from the request to a models.Review struct, validates the rating and the
+// Markdown body, saves the review and returns it.
+func (s *ReviewService) SetReview(c *gin.Context) {
+	bookID := c.Param("bookId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	id, err := pkg.GenerateRandomID()
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	review := new(models.Review)
+	if err := c.BindJSON(review); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	bookUUID, err := uuid.Parse(bookID)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	review.ID = id
+	review.BookID = bookUUID
+	review.Body = strings.TrimSpace(review.Body)
+	review.UserID = user.ID
+	review.User = *user
+
+	if err := pkg.ValidateModelStruct(review); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if math.Mod(review.Rating*2, 1) != 0 {
+		helpers.HandleError(c, errors.New("rating must be a multiple of 0.5"), http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.SetReview(orgID.String(), review); err != nil {
+		s.handleReviewError(c, err)
+		return
+	}
+
+	rating := review.Rating
+	events.Publish(events.Event{Kind: events.BookRated, UserID: user.ID, BookID: bookUUID, Rating: &rating})
+
+	c.JSON(http.StatusOK, review)
+}
+
+// GetReview retrieves the review of a book of the active organization by the user.
+//
+// With the "format" query parameter set to "html", the review also holds its body rendered as sanitized HTML.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ReviewService) GetReview(c *gin.Context) {
+	bookID := c.Param("bookId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	review, err := s.repo.GetReview(userID.String(), orgID.String(), bookID)
+	if err != nil {
+		s.handleReviewError(c, err)
+		return
+	}
+
+	if c.Query("format") == "html" {
+		review.HTML = pkg.RenderMarkdown(review.Body)
+	}
+
+	c.JSON(http.StatusOK, review)
+}
+
+// GetReviews retrieves the reviews of the user for the books of the active organization.
+//
+// With the "format" query parameter set to "html", each review also holds its body rendered as sanitized HTML.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ReviewService) GetReviews(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	reviews, err := s.repo.GetReviews(userID.String(), orgID.String())
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	if c.Query("format") == "html" {
+		for i := range *reviews {
+			(*reviews)[i].HTML = pkg.RenderMarkdown((*reviews)[i].Body)
+		}
+	}
+
+	c.JSON(http.StatusOK, reviews)
+}
+
+// DeleteReview deletes the review of a book of the active organization by the user.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ReviewService) DeleteReview(c *gin.Context) {
+	bookID := c.Param("bookId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	if err := s.repo.DeleteReview(userID.String(), orgID.String(), bookID); err != nil {
+		s.handleReviewError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Review deleted successfully"})
+}
+
+// handleReviewError maps the errors of the review operations to HTTP status codes.
+func (s *ReviewService) handleReviewError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		helpers.HandleError(c, err, http.StatusNotFound)
+	default:
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+	}
+}
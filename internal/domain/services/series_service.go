@@ -0,0 +1,407 @@
+package services
+
+import (
+	"mybooks/internal/domain/models"
+	"mybooks/internal/domain/repositories"
+	"mybooks/internal/infrastructure/helpers"
+	"mybooks/pkg"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type SeriesService struct {
+	repo repositories.SeriesRepository
+}
+
+type SeriesVolumeResponse struct {
+	Position float64      `json:"position"`
+	Book     *models.Book `json:"book"`
+}
+
+type SeriesResponse struct {
+	ID               uuid.UUID              `json:"id"`
+	Name             string                 `json:"name"`
+	Description      string                 `json:"description"`
+	TotalVolumes     int                    `json:"total_volumes"`
+	OwnedCount       int                    `json:"owned_count"`
+	ReadCount        int                    `json:"read_count"`
+	MissingPositions []int                  `json:"missing_positions"`
+	Volumes          []SeriesVolumeResponse `json:"volumes"`
+	NextUnread       *SeriesVolumeResponse  `json:"next_unread"`
+	CreatedAt        time.Time              `json:"created_at"`
+	UpdatedAt        time.Time              `json:"updated_at"`
+}
+
+type NextUnreadResponse struct {
+	SeriesID   uuid.UUID    `json:"series_id"`
+	SeriesName string       `json:"series_name"`
+	Position   float64      `json:"position"`
+	Book       *models.Book `json:"book"`
+}
+
+// NewSeriesService creates a new instance of SeriesService.
+//
+// Parameters:
+// - repo: The SeriesRepository implementation used by the service.
+//
+// Returns:
+// - *SeriesService: A pointer to the newly created SeriesService instance.
+func NewSeriesService(repo repositories.SeriesRepository) *SeriesService {
+	return &SeriesService{
+		repo: repo,
+	}
+}
+
+// CreateSeries creates a new series in the database.
+//
+// It takes a pointer to a gin.Context as a parameter and returns nothing.
+// The function generates a random ID, binds the JSON request body to a Series struct,
+// validates the struct, creates the series in the repository, and returns the ID of the created series.
+func (s *SeriesService) CreateSeries(c *gin.Context) {
+	series := new(models.Series)
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	id, err := pkg.GenerateRandomID()
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	if err := c.BindJSON(series); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	series.ID = id
+	series.OrganizationID = organization.ID
+	series.UserID = user.ID
+	series.User = *user
+
+	if err := pkg.ValidateModelStruct(series); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.CreateSeries(series); err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusCreated, map[string]interface{}{
+		"id": series.ID,
+	})
+}
+
+// GetAllSeries retrieves all series of the active organization with how many volumes are owned and read.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None
+func (s *SeriesService) GetAllSeries(c *gin.Context) {
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	series, err := s.repo.GetAllSeries(orgID.String())
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
+// GetSeriesByID retrieves a series by its ID with its volumes in reading order.
+//
+// The response shows which volumes the organization owns and has read, the whole-numbered
+// positions that are missing from the collection and the next unread volume.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None
+func (s *SeriesService) GetSeriesByID(c *gin.Context) {
+	seriesID := c.Param("seriesId")
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	series, err := s.repo.GetSeriesByID(orgID.String(), seriesID)
+	if err != nil {
+		if strings.Contains(err.Error(), "series not found") {
+			helpers.HandleError(c, err, http.StatusNotFound)
+			return
+		}
+
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, buildSeriesResponse(series))
+}
+
+// GetNextUnreadInSeries retrieves the next unread volume of every series in the organization's collection.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None
+func (s *SeriesService) GetNextUnreadInSeries(c *gin.Context) {
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	series, err := s.repo.GetSeriesWithUnreadBooks(orgID.String())
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]NextUnreadResponse, 0)
+	for _, item := range *series {
+		next := buildSeriesResponse(&item).NextUnread
+		if next == nil {
+			continue
+		}
+
+		response = append(response, NextUnreadResponse{
+			SeriesID:   item.ID,
+			SeriesName: item.Name,
+			Position:   next.Position,
+			Book:       next.Book,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateSeries updates a series in the SeriesService.
+//
+// It takes a pointer to a gin.Context as a parameter and returns nothing.
+// The function retrieves the series ID from the request parameter, binds the JSON request body
+// to a Series struct, validates the struct, updates the series in the repository,
+// and returns an HTTP status code indicating the success of the operation.
+func (s *SeriesService) UpdateSeries(c *gin.Context) {
+	id := c.Param("seriesId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	var series models.Series
+	if err := c.BindJSON(&series); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	seriesID, err := uuid.Parse(id)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	series.ID = seriesID
+	series.User = *user
+
+	if err := pkg.ValidateModelStruct(series); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.UpdateSeries(orgID.String(), &series); err != nil {
+		if strings.Contains(err.Error(), "series not found") {
+			helpers.HandleError(c, err, http.StatusNotFound)
+			return
+		}
+
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// DeleteSeries deletes a series by its ID. The books of the series are kept.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None
+func (s *SeriesService) DeleteSeries(c *gin.Context) {
+	seriesID := c.Param("seriesId")
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	if err := s.repo.DeleteSeries(orgID.String(), seriesID); err != nil {
+		if strings.Contains(err.Error(), "series not found") {
+			helpers.HandleError(c, err, http.StatusNotFound)
+			return
+		}
+
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Series deleted successfully"})
+}
+
+// AddBookToSeries places a book in a series at the position given in the request body.
+//
+// It takes a pointer to a gin.Context as a parameter and returns nothing.
+// The position may be fractional (e.g. 2.5). Adding a book that is already in the series moves it.
+func (s *SeriesService) AddBookToSeries(c *gin.Context) {
+	seriesID := c.Param("seriesId")
+	bookID := c.Param("bookId")
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	var body struct {
+		Position float64 `json:"position" validate:"min=0"`
+	}
+
+	if err := c.BindJSON(&body); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := pkg.ValidateModelStruct(body); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.AddBookToSeries(orgID.String(), seriesID, bookID, body.Position); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			helpers.HandleError(c, err, http.StatusNotFound)
+			return
+		}
+
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// RemoveBookFromSeries removes a book from a series.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None
+func (s *SeriesService) RemoveBookFromSeries(c *gin.Context) {
+	seriesID := c.Param("seriesId")
+	bookID := c.Param("bookId")
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	if err := s.repo.RemoveBookFromSeries(orgID.String(), seriesID, bookID); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			helpers.HandleError(c, err, http.StatusNotFound)
+			return
+		}
+
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// buildSeriesResponse summarizes the volumes of a series that were preloaded in position order.
+func buildSeriesResponse(series *models.Series) SeriesResponse {
+	response := SeriesResponse{
+		ID:               series.ID,
+		Name:             series.Name,
+		Description:      series.Description,
+		TotalVolumes:     series.TotalVolumes,
+		MissingPositions: make([]int, 0),
+		Volumes:          make([]SeriesVolumeResponse, 0),
+		CreatedAt:        series.CreatedAt,
+		UpdatedAt:        series.UpdatedAt,
+	}
+
+	owned := make(map[float64]bool)
+	for _, volume := range series.Books {
+		if volume.Book == nil {
+			continue
+		}
+
+		item := SeriesVolumeResponse{
+			Position: volume.Position,
+			Book:     volume.Book,
+		}
+
+		response.Volumes = append(response.Volumes, item)
+		response.OwnedCount++
+		owned[volume.Position] = true
+
+		if volume.Book.Read {
+			response.ReadCount++
+		} else if response.NextUnread == nil {
+			response.NextUnread = &item
+		}
+	}
+
+	for position := 1; position <= series.TotalVolumes; position++ {
+		if !owned[float64(position)] {
+			response.MissingPositions = append(response.MissingPositions, position)
+		}
+	}
+
+	return response
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"errors"
+	"mybooks/internal/domain/models"
+	"mybooks/internal/domain/repositories"
+	"mybooks/internal/infrastructure/helpers"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// statsMonthsLimit is the largest number of months the books added per month can be requested for.
+const statsMonthsLimit = 120
+
+type StatsService struct {
+	repo repositories.StatsRepository
+}
+
+// NewStatsService creates a new instance of the StatsService struct.
+//
+// It takes a StatsRepository as a parameter and returns a pointer to a StatsService.
+func NewStatsService(repo repositories.StatsRepository) *StatsService {
+	return &StatsService{
+		repo: repo,
+	}
+}
+
+// GetStats retrieves the statistics of the books of the active organization: the totals of books, pages and
+// read and unread books, the books by genre, language, author, decade and library, the loans out and overdue,
+// the books added per month and the longest and shortest books.
+//
+// The "months" query parameter is the number of months the books added per month are given for, the current
+// month included, 12 by default, in the timezone of the user. Every month of the period is given, including the months without books.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *StatsService) GetStats(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	months := 12
+	if value := c.Query("months"); value != "" {
+		if months, err = strconv.Atoi(value); err != nil {
+			helpers.HandleError(c, err, http.StatusBadRequest)
+			return
+		}
+		if months < 1 || months > statsMonthsLimit {
+			helpers.HandleError(c, errors.New("months must be between 1 and 120"), http.StatusBadRequest)
+			return
+		}
+	}
+
+	location := userLocation(user)
+	now := time.Now().In(location)
+	since := time.Date(now.Year(), now.Month()-time.Month(months-1), 1, 0, 0, 0, 0, location)
+
+	stats, err := s.repo.GetCollectionStats(orgID.String(), location.String(), since)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	added := make(map[string]models.StatsGroup)
+	for _, month := range stats.AddedPerMonth {
+		added[month.Key] = month
+	}
+
+	stats.AddedPerMonth = make([]models.StatsGroup, 0)
+	for month := since; !month.After(now); month = month.AddDate(0, 1, 0) {
+		group, ok := added[month.Format("2006-01")]
+		if !ok {
+			group = models.StatsGroup{Key: month.Format("2006-01")}
+		}
+		stats.AddedPerMonth = append(stats.AddedPerMonth, group)
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
@@ -0,0 +1,340 @@
+package services
+
+import (
+	"mybooks/internal/domain/models"
+	"mybooks/internal/domain/repositories"
+	"mybooks/internal/infrastructure/helpers"
+	"mybooks/pkg"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// tagAutocompleteLimit is the maximum number of tags returned when autocompleting a tag name.
+const tagAutocompleteLimit = 10
+
+type TagService struct {
+	repo repositories.TagRepository
+}
+
+// NewTagService creates a new instance of the TagService struct.
+//
+// It takes a TagRepository as a parameter and returns a pointer to a TagService.
+func NewTagService(repo repositories.TagRepository) *TagService {
+	return &TagService{
+		repo: repo,
+	}
+}
+
+// CreateTag creates a new tag in the TagService.
+//
+// It takes a gin.Context as a parameter and returns nothing.
+// The function generates a random ID, binds the JSON from the request to a models.Tag struct,
+// validates the struct, creates the tag in the repository, and returns the ID of the created tag.
+// If the organization already has a tag with the same name, regardless of case, it returns a conflict.
+func (s *TagService) CreateTag(c *gin.Context) {
+	tag := new(models.Tag)
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	id, err := pkg.GenerateRandomID()
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	if err := c.BindJSON(tag); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	tag.ID = id
+	tag.Name = strings.TrimSpace(tag.Name)
+	tag.OrganizationID = organization.ID
+	tag.UserID = user.ID
+	tag.User = *user
+
+	if err := pkg.ValidateModelStruct(tag); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.CreateTag(tag); err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			helpers.HandleError(c, err, http.StatusConflict)
+			return
+		}
+
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusCreated, map[string]interface{}{
+		"id": tag.ID,
+	})
+}
+
+// GetAllTags retrieves the tags of the active organization.
+//
+// When the "q" query parameter is given, only the tags starting with it are returned,
+// most used first, which is meant for autocomplete.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *TagService) GetAllTags(c *gin.Context) {
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	filters := make(map[string]interface{})
+
+	if prefix := pkg.NormalizeTagName(c.Query("q")); prefix != "" {
+		filters["prefix"] = prefix
+		filters["limit"] = tagAutocompleteLimit
+	}
+
+	tags, err := s.repo.GetAllTags(orgID.String(), filters)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+// UpdateTag renames a tag or changes its color.
+//
+// It takes a gin.Context as a parameter and returns nothing.
+// If another tag already has the new name it returns a conflict so the client can merge them instead.
+func (s *TagService) UpdateTag(c *gin.Context) {
+	id := c.Param("tagId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	var tag models.Tag
+	if err := c.BindJSON(&tag); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	tagID, err := uuid.Parse(id)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	tag.ID = tagID
+	tag.Name = strings.TrimSpace(tag.Name)
+	tag.User = *user
+
+	if err := pkg.ValidateModelStruct(tag); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.UpdateTag(orgID.String(), &tag); err != nil {
+		if strings.Contains(err.Error(), "tag not found") {
+			helpers.HandleError(c, err, http.StatusNotFound)
+			return
+		}
+		if strings.Contains(err.Error(), "already exists") {
+			helpers.HandleError(c, err, http.StatusConflict)
+			return
+		}
+
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tag updated successfully"})
+}
+
+// DeleteTag deletes a tag and removes it from every book and note.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *TagService) DeleteTag(c *gin.Context) {
+	id := c.Param("tagId")
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	if err := s.repo.DeleteTag(orgID.String(), id); err != nil {
+		if strings.Contains(err.Error(), "tag not found") {
+			helpers.HandleError(c, err, http.StatusNotFound)
+			return
+		}
+
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tag deleted successfully"})
+}
+
+// MergeTags merges the tags listed in the request body into the tag in the URL.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *TagService) MergeTags(c *gin.Context) {
+	id := c.Param("tagId")
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	var body struct {
+		TagIDs []string `json:"tag_ids" validate:"required,min=1,dive,uuid4"`
+	}
+
+	if err := c.BindJSON(&body); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := pkg.ValidateModelStruct(body); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.MergeTags(orgID.String(), id, body.TagIDs); err != nil {
+		if strings.Contains(err.Error(), "tag not found") {
+			helpers.HandleError(c, err, http.StatusNotFound)
+			return
+		}
+
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tags merged successfully"})
+}
+
+// AddTagToBook tags a book by tag name, creating the tag if the organization does not have it yet.
+//
+// It takes a gin.Context as a parameter and returns nothing.
+// The function binds the JSON from the request to a models.Tag struct, validates the name and color,
+// tags the book in the repository and returns the tag.
+func (s *TagService) AddTagToBook(c *gin.Context) {
+	bookID := c.Param("bookId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	id, err := pkg.GenerateRandomID()
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	tag := new(models.Tag)
+	if err := c.BindJSON(tag); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	tag.ID = id
+	tag.Name = strings.TrimSpace(tag.Name)
+	tag.UserID = user.ID
+	tag.User = *user
+
+	if err := pkg.ValidateModelStruct(tag); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.AddTagToBook(orgID.String(), bookID, tag); err != nil {
+		if strings.Contains(err.Error(), "book not found") {
+			helpers.HandleError(c, err, http.StatusNotFound)
+			return
+		}
+
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+// RemoveTagFromBook removes a tag from a book.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *TagService) RemoveTagFromBook(c *gin.Context) {
+	bookID := c.Param("bookId")
+	tagID := c.Param("tagId")
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	if err := s.repo.RemoveTagFromBook(orgID.String(), bookID, tagID); err != nil {
+		if strings.Contains(err.Error(), "tag not found") {
+			helpers.HandleError(c, err, http.StatusNotFound)
+			return
+		}
+
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
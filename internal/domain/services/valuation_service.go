@@ -0,0 +1,403 @@
+package services
+
+import (
+	"encoding/csv"
+	"math"
+	"mybooks/internal/domain/models"
+	"mybooks/internal/domain/repositories"
+	"mybooks/internal/infrastructure/helpers"
+	"mybooks/pkg"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ValuationService struct {
+	repo repositories.ValuationRepository
+}
+
+type ValuationTotals struct {
+	Copies     int64   `json:"copies"`
+	TotalPaid  float64 `json:"total_paid"`
+	TotalValue float64 `json:"total_value"`
+}
+
+type LibraryValuation struct {
+	LibraryID   *uuid.UUID `json:"library_id"`
+	LibraryName string     `json:"library_name"`
+	ValuationTotals
+}
+
+type YearValuation struct {
+	Year string `json:"year"`
+	ValuationTotals
+}
+
+type UnconvertedAmount struct {
+	Currency   string  `json:"currency"`
+	TotalPaid  float64 `json:"total_paid"`
+	TotalValue float64 `json:"total_value"`
+}
+
+type ValuationResponse struct {
+	Currency    string              `json:"currency"`
+	Total       ValuationTotals     `json:"total"`
+	Libraries   []LibraryValuation  `json:"libraries"`
+	Years       []YearValuation     `json:"years"`
+	Unconverted []UnconvertedAmount `json:"unconverted"`
+}
+
+// NewValuationService creates a new instance of the ValuationService struct.
+//
+// It takes a ValuationRepository as a parameter and returns a pointer to a ValuationService.
+func NewValuationService(repo repositories.ValuationRepository) *ValuationService {
+	return &ValuationService{
+		repo: repo,
+	}
+}
+
+// GetValuation sums what the active organization paid for its copies and what they are worth,
+// per library and per acquisition year, in the currency given by the "currency" query parameter.
+//
+// Amounts in other currencies are converted with the exchange rates of the organization. Amounts
+// whose currency has no rate to the requested one are left out of the totals and listed as unconverted.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ValuationService) GetValuation(c *gin.Context) {
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	query := struct {
+		Currency string `validate:"required,iso4217"`
+	}{
+		Currency: strings.ToUpper(strings.TrimSpace(c.Query("currency"))),
+	}
+
+	if err := pkg.ValidateModelStruct(query); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	groups, err := s.repo.GetValuationGroups(orgID.String())
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	rates, err := s.repo.GetExchangeRates(orgID.String())
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, buildValuation(query.Currency, groups, *rates))
+}
+
+// ExportValuation exports every copy of the active organization with its acquisition and valuation
+// details as a CSV file. Text typed by users is escaped so that spreadsheets do not run it as a formula.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ValuationService) ExportValuation(c *gin.Context) {
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	copies, err := s.repo.GetCopyValuations(orgID.String())
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="valuation.csv"`)
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+	writer.Write([]string{
+		"copy_id", "title", "author", "isbn", "library", "condition", "location", "acquired_on", "acquired_from",
+		"gift_from", "price", "currency", "value", "value_currency", "valued_on",
+	})
+
+	for _, bookCopy := range copies {
+		writer.Write([]string{
+			bookCopy.CopyID.String(),
+			csvText(bookCopy.Title),
+			csvText(bookCopy.Author),
+			csvText(bookCopy.ISBN),
+			csvText(bookCopy.LibraryName),
+			bookCopy.Condition,
+			csvText(bookCopy.Location),
+			bookCopy.AcquiredOn,
+			csvText(bookCopy.AcquiredFrom),
+			csvText(bookCopy.GiftFrom),
+			strconv.FormatFloat(bookCopy.Price, 'f', 2, 64),
+			bookCopy.Currency,
+			strconv.FormatFloat(bookCopy.Value, 'f', 2, 64),
+			bookCopy.ValueCurrency,
+			bookCopy.ValuedOn,
+		})
+	}
+
+	writer.Flush()
+}
+
+// GetExchangeRates retrieves the exchange rates of the active organization.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ValuationService) GetExchangeRates(c *gin.Context) {
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	rates, err := s.repo.GetExchangeRates(orgID.String())
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, rates)
+}
+
+// SetExchangeRate sets the rate of a pair of currencies for the active organization.
+//
+// It takes a gin.Context as a parameter and returns nothing.
+// The function binds the JSON from the request to a models.ExchangeRate struct, validates the struct
+// and saves the rate, replacing the previous rate of the pair if there is one.
+func (s *ValuationService) SetExchangeRate(c *gin.Context) {
+	rate := new(models.ExchangeRate)
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	id, err := pkg.GenerateRandomID()
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	if err := c.BindJSON(rate); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	rate.ID = id
+	rate.FromCurrency = strings.ToUpper(strings.TrimSpace(rate.FromCurrency))
+	rate.ToCurrency = strings.ToUpper(strings.TrimSpace(rate.ToCurrency))
+	rate.OrganizationID = organization.ID
+	rate.UserID = user.ID
+	rate.User = *user
+
+	if err := pkg.ValidateModelStruct(rate); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.SetExchangeRate(rate); err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// DeleteExchangeRate deletes an exchange rate of the active organization.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *ValuationService) DeleteExchangeRate(c *gin.Context) {
+	id := c.Param("rateId")
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	if err := s.repo.DeleteExchangeRate(orgID.String(), id); err != nil {
+		if strings.Contains(err.Error(), "exchange rate not found") {
+			helpers.HandleError(c, err, http.StatusNotFound)
+			return
+		}
+
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Exchange rate deleted successfully"})
+}
+
+// buildValuation converts the sums of every group to the requested currency and totals them
+// per library and per acquisition year. Libraries are ordered by name and years from the most recent,
+// with the copies of unknown acquisition date last.
+func buildValuation(currency string, groups []models.ValuationGroup, rates []models.ExchangeRate) ValuationResponse {
+	response := ValuationResponse{
+		Currency:    currency,
+		Libraries:   make([]LibraryValuation, 0),
+		Years:       make([]YearValuation, 0),
+		Unconverted: make([]UnconvertedAmount, 0),
+	}
+
+	libraries := make(map[string]*LibraryValuation)
+	years := make(map[string]*YearValuation)
+	unconverted := make(map[string]*UnconvertedAmount)
+
+	for _, group := range groups {
+		paid, paidConverted := convertAmount(group.Paid, group.Currency, currency, rates)
+		value, valueConverted := convertAmount(group.Value, group.ValueCurrency, currency, rates)
+
+		if !paidConverted {
+			unconvertedAmount(unconverted, group.Currency).TotalPaid += group.Paid
+		}
+		if !valueConverted {
+			unconvertedAmount(unconverted, group.ValueCurrency).TotalValue += group.Value
+		}
+
+		libraryKey := ""
+		if group.LibraryID != nil {
+			libraryKey = group.LibraryID.String()
+		}
+		library, ok := libraries[libraryKey]
+		if !ok {
+			library = &LibraryValuation{LibraryID: group.LibraryID, LibraryName: group.LibraryName}
+			libraries[libraryKey] = library
+		}
+
+		year, ok := years[group.Year]
+		if !ok {
+			year = &YearValuation{Year: group.Year}
+			years[group.Year] = year
+		}
+
+		for _, totals := range []*ValuationTotals{&response.Total, &library.ValuationTotals, &year.ValuationTotals} {
+			totals.Copies += group.Copies
+			totals.TotalPaid += paid
+			totals.TotalValue += value
+		}
+	}
+
+	roundTotals(&response.Total)
+
+	for _, library := range libraries {
+		roundTotals(&library.ValuationTotals)
+		response.Libraries = append(response.Libraries, *library)
+	}
+	sort.Slice(response.Libraries, func(i, j int) bool {
+		return response.Libraries[i].LibraryName < response.Libraries[j].LibraryName
+	})
+
+	for _, year := range years {
+		roundTotals(&year.ValuationTotals)
+		response.Years = append(response.Years, *year)
+	}
+	sort.Slice(response.Years, func(i, j int) bool {
+		if response.Years[i].Year == "" || response.Years[j].Year == "" {
+			return response.Years[j].Year == ""
+		}
+		return response.Years[i].Year > response.Years[j].Year
+	})
+
+	for _, amount := range unconverted {
+		amount.TotalPaid = roundAmount(amount.TotalPaid)
+		amount.TotalValue = roundAmount(amount.TotalValue)
+		response.Unconverted = append(response.Unconverted, *amount)
+	}
+	sort.Slice(response.Unconverted, func(i, j int) bool {
+		return response.Unconverted[i].Currency < response.Unconverted[j].Currency
+	})
+
+	return response
+}
+
+// convertAmount converts an amount to the target currency with a rate of the organization, or with the
+// inverse of the rate of the opposite pair. It reports false when the amount cannot be converted.
+// Zero amounts always convert, since copies without a price or a value have no currency.
+func convertAmount(amount float64, from, to string, rates []models.ExchangeRate) (float64, bool) {
+	if amount == 0 || from == to {
+		return amount, true
+	}
+
+	for _, rate := range rates {
+		if rate.FromCurrency == from && rate.ToCurrency == to {
+			return amount * rate.Rate, true
+		}
+	}
+
+	for _, rate := range rates {
+		if rate.FromCurrency == to && rate.ToCurrency == from {
+			return amount / rate.Rate, true
+		}
+	}
+
+	return 0, false
+}
+
+// unconvertedAmount returns the unconverted amount of a currency, adding it to the map the first time.
+func unconvertedAmount(amounts map[string]*UnconvertedAmount, currency string) *UnconvertedAmount {
+	amount, ok := amounts[currency]
+	if !ok {
+		amount = &UnconvertedAmount{Currency: currency}
+		amounts[currency] = amount
+	}
+
+	return amount
+}
+
+// roundTotals rounds the amounts of the totals to cents.
+func roundTotals(totals *ValuationTotals) {
+	totals.TotalPaid = roundAmount(totals.TotalPaid)
+	totals.TotalValue = roundAmount(totals.TotalValue)
+}
+
+// roundAmount rounds an amount to cents.
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// csvText escapes a text cell of a CSV export: a cell starting like a formula is prefixed with a quote,
+// so that spreadsheets show it as text instead of running it.
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+
+	return value
+}
@@ -0,0 +1,481 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mybooks/internal/domain/models"
+	"mybooks/internal/domain/repositories"
+	"mybooks/internal/infrastructure/helpers"
+	"mybooks/pkg"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// wishlistDefaultPriority is the priority of the wishlist items created without one.
+const wishlistDefaultPriority = 3
+
+type WishlistService struct {
+	repo repositories.WishlistRepository
+}
+
+type PublicWishlistItem struct {
+	ID          uuid.UUID             `json:"id"`
+	Title       string                `json:"title"`
+	Author      string                `json:"author"`
+	ISBN        string                `json:"isbn"`
+	Cover       string                `json:"cover"`
+	Priority    int                   `json:"priority"`
+	TargetPrice float64               `json:"target_price"`
+	Currency    string                `json:"currency"`
+	Links       []models.WishlistLink `json:"links"`
+}
+
+type PublicWishlistResponse struct {
+	Name  string               `json:"name"`
+	Items []PublicWishlistItem `json:"items"`
+}
+
+// NewWishlistService creates a new instance of the WishlistService struct.
+//
+// It takes a WishlistRepository as a parameter and returns a pointer to a WishlistService.
+func NewWishlistService(repo repositories.WishlistRepository) *WishlistService {
+	return &WishlistService{
+		repo: repo,
+	}
+}
+
+// CreateWishlistItem adds a book to the wishlist of the active organization.
+//
+// It takes a gin.Context as a parameter and returns nothing.
+// The function generates a random ID, binds the JSON from the request to a models.WishlistItem struct,
+// validates the struct, creates the item in the repository, and returns the ID of the created item.
+func (s *WishlistService) CreateWishlistItem(c *gin.Context) {
+	item := new(models.WishlistItem)
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	id, err := pkg.GenerateRandomID()
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	if err := c.BindJSON(item); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	item.ID = id
+	item.BookID = nil
+	item.BoughtAt = nil
+	item.OrganizationID = organization.ID
+	item.UserID = user.ID
+	item.User = *user
+
+	if err := prepareWishlistItem(item); err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	if err := pkg.ValidateModelStruct(item); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.CreateWishlistItem(item); err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusCreated, map[string]interface{}{
+		"id": item.ID,
+	})
+}
+
+// GetWishlist retrieves the wishlist of the active organization, most wanted first.
+//
+// With the "bought" query parameter set to true, the items already bought are returned instead,
+// most recently bought first.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *WishlistService) GetWishlist(c *gin.Context) {
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	bought, _ := strconv.ParseBool(c.Query("bought"))
+
+	items, err := s.repo.GetWishlist(orgID.String(), bought)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// GetWishlistItem retrieves a wishlist item of the active organization with its links.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *WishlistService) GetWishlistItem(c *gin.Context) {
+	id := c.Param("itemId")
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	item, err := s.repo.GetWishlistItem(orgID.String(), id)
+	if err != nil {
+		s.handleWishlistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// UpdateWishlistItem updates a wishlist item of the active organization.
+//
+// It takes a gin.Context as a parameter and returns nothing.
+// When the request body has links they replace the links of the item.
+func (s *WishlistService) UpdateWishlistItem(c *gin.Context) {
+	id := c.Param("itemId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	var item models.WishlistItem
+	if err := c.BindJSON(&item); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	itemID, err := uuid.Parse(id)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	item.ID = itemID
+	item.User = *user
+
+	if err := prepareWishlistItem(&item); err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	if err := pkg.ValidateModelStruct(item); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.UpdateWishlistItem(orgID.String(), &item); err != nil {
+		s.handleWishlistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Wishlist item updated successfully"})
+}
+
+// DeleteWishlistItem removes an item from the wishlist of the active organization.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *WishlistService) DeleteWishlistItem(c *gin.Context) {
+	id := c.Param("itemId")
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	if err := s.repo.DeleteWishlistItem(orgID.String(), id); err != nil {
+		s.handleWishlistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Wishlist item deleted successfully"})
+}
+
+// BuyWishlistItem marks a wishlist item as bought and adds it to the books of the active organization.
+//
+// It takes a gin.Context as a parameter and returns nothing.
+// The book is created from the title, author, ISBN and cover of the item. The request body is optional
+// and holds the purchase details of the copy, as accepted when adding a copy: where and when it was
+// bought, the price paid and the library to place it in. The copy is acquired today in the currency of
+// the item unless told otherwise. It returns the ID of the created book.
+func (s *WishlistService) BuyWishlistItem(c *gin.Context) {
+	id := c.Param("itemId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	var bookCopy models.Copy
+	if err := c.ShouldBindJSON(&bookCopy); err != nil && !errors.Is(err, io.EOF) {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	item, err := s.repo.GetWishlistItem(orgID.String(), id)
+	if err != nil {
+		s.handleWishlistError(c, err)
+		return
+	}
+
+	bookID, err := pkg.GenerateRandomID()
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	copyID, err := pkg.GenerateRandomID()
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	book := &models.Book{
+		ID:             bookID,
+		Title:          item.Title,
+		Author:         item.Author,
+		ISBN:           item.ISBN,
+		Cover:          item.Cover,
+		OrganizationID: organization.ID,
+		UserID:         user.ID,
+		User:           *user,
+	}
+
+	bookCopy.ID = copyID
+	bookCopy.BookID = bookID
+	bookCopy.Currency = strings.ToUpper(bookCopy.Currency)
+	bookCopy.ValueCurrency = strings.ToUpper(bookCopy.ValueCurrency)
+	if bookCopy.AcquiredOn == "" {
+		bookCopy.AcquiredOn = time.Now().Format(time.DateOnly)
+	}
+	if bookCopy.Currency == "" {
+		bookCopy.Currency = item.Currency
+	}
+	bookCopy.OrganizationID = organization.ID
+	bookCopy.UserID = user.ID
+	bookCopy.User = *user
+
+	if err := pkg.ValidateModelStruct(book); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := pkg.ValidateModelStruct(bookCopy); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.BuyWishlistItem(orgID.String(), id, book, &bookCopy); err != nil {
+		s.handleWishlistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, map[string]interface{}{
+		"id": book.ID,
+	})
+}
+
+// ShareWishlist creates a public link to the wishlist of the active organization, to share it with
+// people who want to give a book. Sharing it again creates a new link and disables the previous one.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *WishlistService) ShareWishlist(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	tokenString, err := helpers.GenerateSecureToken()
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	share := &models.WishlistShare{
+		OrganizationID: organization.ID,
+		Token:          tokenString,
+		UserID:         user.ID,
+	}
+
+	if err := s.repo.ShareWishlist(share); err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token": tokenString,
+		"url":   fmt.Sprintf("%s/wishlists/%s", os.Getenv("APP_URL"), tokenString),
+	})
+}
+
+// UnshareWishlist disables the public link to the wishlist of the active organization.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *WishlistService) UnshareWishlist(c *gin.Context) {
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	if err := s.repo.UnshareWishlist(orgID.String()); err != nil {
+		s.handleWishlistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Wishlist unshared successfully"})
+}
+
+// GetSharedWishlist retrieves the wishlist behind a public link. It needs no authentication and only
+// shows the items that were not bought yet, without their notes.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *WishlistService) GetSharedWishlist(c *gin.Context) {
+	tokenString := c.Param("token")
+
+	organization, items, err := s.repo.GetSharedWishlist(tokenString)
+	if err != nil {
+		s.handleWishlistError(c, err)
+		return
+	}
+
+	response := PublicWishlistResponse{
+		Name:  organization.Name,
+		Items: make([]PublicWishlistItem, 0),
+	}
+
+	for _, item := range *items {
+		response.Items = append(response.Items, PublicWishlistItem{
+			ID:          item.ID,
+			Title:       item.Title,
+			Author:      item.Author,
+			ISBN:        item.ISBN,
+			Cover:       item.Cover,
+			Priority:    item.Priority,
+			TargetPrice: item.TargetPrice,
+			Currency:    item.Currency,
+			Links:       item.Links,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// handleWishlistError maps the errors of the wishlist operations to HTTP status codes.
+func (s *WishlistService) handleWishlistError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		helpers.HandleError(c, err, http.StatusNotFound)
+	case strings.Contains(err.Error(), "insufficient library permissions"):
+		helpers.HandleError(c, err, http.StatusForbidden)
+	case strings.Contains(err.Error(), "already bought"),
+		strings.Contains(err.Error(), "smart library"):
+		helpers.HandleError(c, err, http.StatusConflict)
+	default:
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+	}
+}
+
+// prepareWishlistItem trims the details of a wishlist item, applies the default priority and gives
+// an ID to each of its links.
+func prepareWishlistItem(item *models.WishlistItem) error {
+	item.Title = strings.TrimSpace(item.Title)
+	item.Author = strings.TrimSpace(item.Author)
+	item.Currency = strings.ToUpper(strings.TrimSpace(item.Currency))
+	if item.Priority == 0 {
+		item.Priority = wishlistDefaultPriority
+	}
+
+	for i := range item.Links {
+		id, err := pkg.GenerateRandomID()
+		if err != nil {
+			return err
+		}
+
+		item.Links[i].ID = id
+		item.Links[i].WishlistItemID = item.ID
+		item.Links[i].Label = strings.TrimSpace(item.Links[i].Label)
+		item.Links[i].URL = strings.TrimSpace(item.Links[i].URL)
+	}
+
+	return nil
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"mybooks/internal/domain/models"
+	"mybooks/internal/domain/repositories"
+	"mybooks/internal/infrastructure/helpers"
+	"mybooks/pkg"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type WorkService struct {
+	repo repositories.WorkRepository
+}
+
+// NewWorkService creates a new instance of the WorkService struct.
+//
+// It takes a WorkRepository as a parameter and returns a pointer to a WorkService.
+func NewWorkService(repo repositories.WorkRepository) *WorkService {
+	return &WorkService{
+		repo: repo,
+	}
+}
+
+// GetAllWorks retrieves the works of the active organization with their editions, optionally filtered by title.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *WorkService) GetAllWorks(c *gin.Context) {
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	filters := make(map[string]interface{})
+
+	if title := strings.TrimSpace(c.Query("title")); title != "" {
+		filters["title"] = strings.ToLower(title)
+	}
+
+	works, err := s.repo.GetAllWorks(orgID.String(), filters)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, works)
+}
+
+// GetWorkByID retrieves a work by its ID with its editions and their copies.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *WorkService) GetWorkByID(c *gin.Context) {
+	id := c.Param("workId")
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	work, err := s.repo.GetWorkByID(orgID.String(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "work not found") {
+			helpers.HandleError(c, err, http.StatusNotFound)
+			return
+		}
+
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, work)
+}
+
+// UpdateWork renames a work or changes its author. The editions of the work keep their own title and author.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *WorkService) UpdateWork(c *gin.Context) {
+	id := c.Param("workId")
+
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	var work models.Work
+	if err := c.BindJSON(&work); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	workID, err := uuid.Parse(id)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	work.ID = workID
+	work.Title = strings.TrimSpace(work.Title)
+	work.Author = strings.TrimSpace(work.Author)
+	work.User = *user
+
+	if err := pkg.ValidateModelStruct(work); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.UpdateWork(orgID.String(), &work); err != nil {
+		if strings.Contains(err.Error(), "work not found") {
+			helpers.HandleError(c, err, http.StatusNotFound)
+			return
+		}
+
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Work updated successfully"})
+}
+
+// MergeWorks merges the works listed in the request body into the work in the URL, so their
+// editions become editions of the same work.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *WorkService) MergeWorks(c *gin.Context) {
+	id := c.Param("workId")
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	var body struct {
+		WorkIDs []string `json:"work_ids" validate:"required,min=1,dive,uuid4"`
+	}
+
+	if err := c.BindJSON(&body); err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := pkg.ValidateModelStruct(body); err != nil {
+		helpers.HandleError(c, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.repo.MergeWorks(orgID.String(), id, body.WorkIDs); err != nil {
+		if strings.Contains(err.Error(), "work not found") {
+			helpers.HandleError(c, err, http.StatusNotFound)
+			return
+		}
+
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Works merged successfully"})
+}
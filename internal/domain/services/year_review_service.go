@@ -0,0 +1,476 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"mybooks/internal/domain/models"
+	"mybooks/internal/domain/repositories"
+	"mybooks/internal/infrastructure/helpers"
+	"mybooks/pkg"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type YearReviewService struct {
+	repo repositories.YearReviewRepository
+}
+
+// NewYearReviewService creates a new instance of the YearReviewService struct.
+//
+// It takes a YearReviewRepository as a parameter and returns a pointer to a YearReviewService.
+func NewYearReviewService(repo repositories.YearReviewRepository) *YearReviewService {
+	return &YearReviewService{
+		repo: repo,
+	}
+}
+
+// GetYearReview retrieves the review of the reading of the user in a year: the books of the active organization
+// finished during the year, the pages read, the time spent in reading sessions, the top genres and authors, the
+// longest and highest-rated books and the books and pages read month by month, in the timezone of the user.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *YearReviewService) GetYearReview(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	year, err := yearReviewYear(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	review, err := s.yearReview(user, organization, year)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, review)
+}
+
+// ShareYearReview creates a public link to the review of the reading of the user in a year, to share it as JSON,
+// as an HTML page or as a PNG image. Sharing it again creates a new link and disables the previous one.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *YearReviewService) ShareYearReview(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	year, err := yearReviewYear(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	id, err := pkg.GenerateRandomID()
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	tokenString, err := helpers.GenerateSecureToken()
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	share := &models.YearReviewShare{
+		ID:             id,
+		Year:           year,
+		Token:          tokenString,
+		OrganizationID: organization.ID,
+		UserID:         user.ID,
+	}
+
+	if err := s.repo.ShareYearReview(share); err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token": tokenString,
+		"url":   fmt.Sprintf("%s/year-reviews/%s", os.Getenv("APP_URL"), tokenString),
+	})
+}
+
+// UnshareYearReview disables the public link to the review of the reading of the user in a year.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *YearReviewService) UnshareYearReview(c *gin.Context) {
+	user, err := helpers.GetUserFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	userID := user.ID
+
+	organization, err := helpers.GetOrganizationFromContext(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusUnauthorized)
+		return
+	}
+	orgID := organization.ID
+
+	year, err := yearReviewYear(c)
+	if err != nil {
+		helpers.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := s.repo.UnshareYearReview(userID.String(), orgID.String(), year); err != nil {
+		s.handleYearReviewError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Year review unshared successfully"})
+}
+
+// GetSharedYearReview retrieves the year review behind a public link as JSON. It needs no authentication.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *YearReviewService) GetSharedYearReview(c *gin.Context) {
+	review, err := s.sharedYearReview(c)
+	if err != nil {
+		s.handleYearReviewError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, review)
+}
+
+// GetSharedYearReviewHTML renders the year review behind a public link as a self-contained HTML page, with its
+// styles inline and no external resources. It needs no authentication.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *YearReviewService) GetSharedYearReviewHTML(c *gin.Context) {
+	review, err := s.sharedYearReview(c)
+	if err != nil {
+		s.handleYearReviewError(c, err)
+		return
+	}
+
+	var page bytes.Buffer
+	if err := yearReviewTemplate.Execute(&page, newYearReviewPage(review)); err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.Header("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+	c.Data(http.StatusOK, "text/html; charset=utf-8", page.Bytes())
+}
+
+// GetSharedYearReviewPNG renders the year review behind a public link as a PNG image, to be used as the preview
+// of the link. It needs no authentication.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - None.
+func (s *YearReviewService) GetSharedYearReviewPNG(c *gin.Context) {
+	review, err := s.sharedYearReview(c)
+	if err != nil {
+		s.handleYearReviewError(c, err)
+		return
+	}
+
+	var image bytes.Buffer
+	if err := pkg.RenderSummaryCardPNG(&image, yearReviewCard(review)); err != nil {
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.Data(http.StatusOK, "image/png", image.Bytes())
+}
+
+// yearReview computes the review of a user in an organization for a year, in the timezone of the user, with
+// every month of the year, including the months without books.
+func (s *YearReviewService) yearReview(user *models.User, organization *models.Organization, year int) (*models.YearReview, error) {
+	location := userLocation(user)
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, location)
+	end := start.AddDate(1, 0, 0)
+
+	review, err := s.repo.GetYearReview(user.ID.String(), organization.ID.String(), location.String(), start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	review.Year = year
+	review.Name = organization.Name
+	review.Timezone = location.String()
+
+	read := make(map[string]models.YearReviewMonth)
+	for _, month := range review.Months {
+		read[month.Month] = month
+	}
+
+	review.Months = make([]models.YearReviewMonth, 0, 12)
+	for month := start; month.Before(end); month = month.AddDate(0, 1, 0) {
+		value, ok := read[month.Format("2006-01")]
+		if !ok {
+			value = models.YearReviewMonth{Month: month.Format("2006-01")}
+		}
+		review.Months = append(review.Months, value)
+	}
+
+	return review, nil
+}
+
+// sharedYearReview computes the year review behind the token of a public link. The responses of public links are
+// not cached, so that a disabled link stops working at once, and not indexed by search engines.
+func (s *YearReviewService) sharedYearReview(c *gin.Context) (*models.YearReview, error) {
+	share, organization, err := s.repo.GetYearReviewShare(c.Param("token"))
+	if err != nil {
+		return nil, err
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Robots-Tag", "noindex")
+
+	return s.yearReview(&share.User, organization, share.Year)
+}
+
+// handleYearReviewError maps the errors of the year review operations to HTTP status codes.
+func (s *YearReviewService) handleYearReviewError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		helpers.HandleError(c, err, http.StatusNotFound)
+	default:
+		helpers.HandleError(c, err, http.StatusInternalServerError)
+	}
+}
+
+// yearReviewYear reads the year of a review from the path.
+func yearReviewYear(c *gin.Context) (int, error) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		return 0, err
+	}
+
+	if year < 1900 || year > 9999 {
+		return 0, errors.New("year must be between 1900 and 9999")
+	}
+
+	return year, nil
+}
+
+// yearReviewPage is the data of the HTML page of a year review.
+type yearReviewPage struct {
+	Review       *models.YearReview
+	ReadingTime  string
+	Months       []yearReviewPageMonth
+	HighestRated string
+}
+
+// yearReviewPageMonth is a bar of the month by month chart of the HTML page of a year review, with its height in
+// percent of the chart. The month with the most books fills 85% of it, to leave room for its count above the bar.
+type yearReviewPageMonth struct {
+	Label  string
+	Books  int64
+	Pages  int64
+	Height int
+}
+
+// newYearReviewPage prepares the data of the HTML page of a year review.
+func newYearReviewPage(review *models.YearReview) yearReviewPage {
+	page := yearReviewPage{
+		Review:      review,
+		ReadingTime: yearReviewReadingTime(review.ReadingTime),
+	}
+
+	highest := int64(0)
+	for _, month := range review.Months {
+		highest = max(highest, month.Books)
+	}
+
+	for _, month := range review.Months {
+		bar := yearReviewPageMonth{
+			Label: yearReviewMonthLabel(month.Month),
+			Books: month.Books,
+			Pages: month.Pages,
+		}
+		if highest > 0 {
+			bar.Height = int(month.Books * 85 / highest)
+		}
+		page.Months = append(page.Months, bar)
+	}
+
+	if review.HighestRated != nil {
+		page.HighestRated = strconv.FormatFloat(*review.HighestRated.Rating, 'f', -1, 64)
+	}
+
+	return page
+}
+
+// yearReviewCard prepares the content of the PNG image of a year review.
+func yearReviewCard(review *models.YearReview) pkg.SummaryCard {
+	card := pkg.SummaryCard{
+		Title: fmt.Sprintf("%s: %d in books", review.Name, review.Year),
+		Figures: []pkg.CardFigure{
+			{Value: strconv.FormatInt(review.BooksRead, 10), Label: "books read"},
+			{Value: strconv.FormatInt(review.PagesRead, 10), Label: "pages read"},
+			{Value: yearReviewReadingTime(review.ReadingTime), Label: "spent reading"},
+		},
+	}
+
+	var favorites []string
+	if len(review.Genres) > 0 {
+		favorites = append(favorites, "Top genre: "+review.Genres[0].Key)
+	}
+	if len(review.Authors) > 0 {
+		favorites = append(favorites, "Top author: "+review.Authors[0].Key)
+	}
+	card.Subtitle = strings.Join(favorites, " - ")
+
+	for _, month := range review.Months {
+		card.Bars = append(card.Bars, pkg.CardBar{Label: yearReviewMonthLabel(month.Month), Value: month.Books})
+	}
+
+	if review.Longest != nil {
+		card.Lines = append(card.Lines, fmt.Sprintf("Longest: %s (%d pages)", review.Longest.Title, review.Longest.Pages))
+	}
+	if review.HighestRated != nil {
+		rating := strconv.FormatFloat(*review.HighestRated.Rating, 'f', -1, 64)
+		card.Lines = append(card.Lines, fmt.Sprintf("Highest rated: %s (%s/5)", review.HighestRated.Title, rating))
+	}
+
+	return card
+}
+
+// yearReviewReadingTime formats a reading time in seconds as hours and minutes, such as "12h 05m".
+func yearReviewReadingTime(seconds int64) string {
+	return fmt.Sprintf("%dh %02dm", seconds/3600, seconds%3600/60)
+}
+
+// yearReviewMonthLabel turns a YYYY-MM month into the short name of the month, such as "Jan".
+func yearReviewMonthLabel(month string) string {
+	date, err := time.Parse("2006-01", month)
+	if err != nil {
+		return month
+	}
+	return date.Format("Jan")
+}
+
+// yearReviewTemplate is the HTML page of a year review. It is self-contained: the styles are inline, the chart is
+// drawn with plain elements and the covers of the books are left out, so the page loads no external resources.
+var yearReviewTemplate = template.Must(template.New("year-review").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{.Review.Name}}: {{.Review.Year}} in books</title>
+<style>
+body { margin: 0; background: #1e293b; color: #f8fafc; font-family: -apple-system, "Segoe UI", Roboto, Helvetica, Arial, sans-serif; }
+main { max-width: 820px; margin: 0 auto; padding: 48px 24px; }
+h1 { font-size: 2.4rem; margin: 0 0 8px; }
+h2 { font-size: 1.2rem; margin: 40px 0 16px; color: #f59e0b; }
+.muted { color: #94a3b8; }
+.figures { display: flex; flex-wrap: wrap; gap: 16px; margin-top: 32px; }
+.figure { flex: 1 1 160px; background: #334155; border-radius: 12px; padding: 20px; }
+.figure strong { display: block; font-size: 2rem; color: #f59e0b; }
+.chart { display: flex; align-items: flex-end; gap: 8px; height: 200px; border-bottom: 2px solid #94a3b8; }
+.month { flex: 1; display: flex; flex-direction: column; justify-content: flex-end; height: 100%; text-align: center; font-size: 0.8rem; }
+.bar { background: #f59e0b; border-radius: 4px 4px 0 0; }
+.labels { display: flex; gap: 8px; margin-top: 6px; }
+.labels span { flex: 1; text-align: center; font-size: 0.8rem; color: #94a3b8; }
+.highlights { display: flex; flex-wrap: wrap; gap: 16px; }
+.highlight { flex: 1 1 240px; background: #334155; border-radius: 12px; padding: 20px; }
+ol, ul { padding-left: 20px; }
+li { margin: 6px 0; }
+</style>
+</head>
+<body>
+<main>
+<h1>{{.Review.Name}}: {{.Review.Year}} in books</h1>
+<p class="muted">Year in review, in the {{.Review.Timezone}} timezone.</p>
+
+<section class="figures">
+<div class="figure"><strong>{{.Review.BooksRead}}</strong>books read</div>
+<div class="figure"><strong>{{.Review.PagesRead}}</strong>pages read</div>
+<div class="figure"><strong>{{.ReadingTime}}</strong>spent reading</div>
+</section>
+
+<h2>Month by month</h2>
+<div class="chart">
+{{- range .Months}}
+<div class="month" title="{{.Books}} books, {{.Pages}} pages">{{if .Books}}<span>{{.Books}}</span><div class="bar" style="height: {{.Height}}%"></div>{{end}}</div>
+{{- end}}
+</div>
+<div class="labels">{{range .Months}}<span>{{.Label}}</span>{{end}}</div>
+
+{{- if or .Review.Longest .Review.HighestRated}}
+<h2>Highlights</h2>
+<section class="highlights">
+{{- with .Review.Longest}}
+<div class="highlight"><span class="muted">Longest book</span><br><strong>{{.Title}}</strong><br>{{.Author}}, {{.Pages}} pages</div>
+{{- end}}
+{{- with .Review.HighestRated}}
+<div class="highlight"><span class="muted">Highest rated</span><br><strong>{{.Title}}</strong><br>{{.Author}}, {{$.HighestRated}}/5</div>
+{{- end}}
+</section>
+{{- end}}
+
+{{- if .Review.Genres}}
+<h2>Top genres</h2>
+<ol>{{range .Review.Genres}}<li>{{.Key}} <span class="muted">({{.Books}} books)</span></li>{{end}}</ol>
+{{- end}}
+
+{{- if .Review.Authors}}
+<h2>Top authors</h2>
+<ol>{{range .Review.Authors}}<li>{{.Key}} <span class="muted">({{.Books}} books)</span></li>{{end}}</ol>
+{{- end}}
+
+{{- if .Review.Books}}
+<h2>Books finished</h2>
+<ul>{{range .Review.Books}}<li><strong>{{.Title}}</strong> <span class="muted">by {{.Author}}</span></li>{{end}}</ul>
+{{- end}}
+</main>
+</body>
+</html>
+`))
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"mybooks/internal/domain/services"
+	"mybooks/internal/infrastructure/api/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ActivitiesHandler sets up the routes for the activities handler in the provided gin.Engine.
+//
+// Parameters:
+// - router: a pointer to a gin.Engine object representing the HTTP router.
+// - activityService: a pointer to a services.ActivityService object providing the activity-related operations.
+//
+// Returns: None.
+func ActivitiesHandler(router *gin.Engine, activityService *services.ActivityService) {
+	v1 := router.Group("/v1")
+	{
+		v1.GET("/feed", middlewares.AuthMiddleware(), activityService.GetFeed)
+
+		activityRouter := v1.Group("/activities")
+		{
+			activityRouter.PUT("/:activityId", middlewares.AuthMiddleware(), activityService.UpdateActivity)
+			activityRouter.DELETE("/:activityId", middlewares.AuthMiddleware(), activityService.DeleteActivity)
+		}
+
+		activitySettingRouter := v1.Group("/activity-settings")
+		{
+			activitySettingRouter.GET("", middlewares.AuthMiddleware(), activityService.GetActivitySettings)
+			activitySettingRouter.PUT("/:kind", middlewares.AuthMiddleware(), activityService.SetActivitySetting)
+		}
+
+		// Public activities are shown on public profiles and need no authentication
+		v1.GET("/users/:handle/activities", activityService.GetPublicActivities)
+	}
+}
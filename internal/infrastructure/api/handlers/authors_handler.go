@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"mybooks/internal/domain/services"
+	"mybooks/internal/infrastructure/api/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuthorsHandler registers the author handler with the provided gin.Engine and services.AuthorService.
+//
+// Parameters:
+// - router: a pointer to a gin.Engine object representing the HTTP router.
+// - authorService: a pointer to a services.AuthorService object providing the author-related operations.
+//
+// Returns: None.
+func AuthorsHandler(router *gin.Engine, authorService *services.AuthorService) {
+	v1 := router.Group("/v1")
+	{
+		authorsRouter := v1.Group("/authors")
+		{
+			authorsRouter.GET("/", middlewares.AuthMiddleware(), authorService.GetAllAuthors)
+			authorsRouter.GET("/:authorId", middlewares.AuthMiddleware(), authorService.GetAuthorByID)
+			authorsRouter.PUT("/:authorId", middlewares.AuthMiddleware(), authorService.UpdateAuthor)
+			authorsRouter.POST("/:authorId/merge", middlewares.AuthMiddleware(), authorService.MergeAuthors)
+		}
+
+		v1.PUT("/books/:bookId/authors", middlewares.AuthMiddleware(), authorService.SetBookAuthors)
+	}
+}
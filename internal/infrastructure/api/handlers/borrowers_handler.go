@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"mybooks/internal/domain/services"
+	"mybooks/internal/infrastructure/api/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BorrowersHandler sets up the routes for the borrower handler in the provided gin.Engine.
+//
+// Parameters:
+// - router: a pointer to a gin.Engine object representing the HTTP router.
+// - borrowerService: a pointer to a services.BorrowerService object providing the borrower-related operations.
+//
+// Returns: None.
+func BorrowersHandler(router *gin.Engine, borrowerService *services.BorrowerService) {
+	v1 := router.Group("/v1")
+	{
+		borrowersRouter := v1.Group("/borrowers")
+		{
+			borrowersRouter.GET("/", middlewares.AuthMiddleware(), borrowerService.GetAllBorrowers)
+			borrowersRouter.POST("/", middlewares.AuthMiddleware(), borrowerService.CreateBorrower)
+			borrowersRouter.GET("/:borrowerId", middlewares.AuthMiddleware(), borrowerService.GetBorrowerByID)
+			borrowersRouter.PUT("/:borrowerId", middlewares.AuthMiddleware(), borrowerService.UpdateBorrower)
+			borrowersRouter.DELETE("/:borrowerId", middlewares.AuthMiddleware(), borrowerService.DeleteBorrower)
+			borrowersRouter.GET("/:borrowerId/loans", middlewares.AuthMiddleware(), borrowerService.GetBorrowerLoans)
+			borrowersRouter.POST("/:borrowerId/merge", middlewares.AuthMiddleware(), borrowerService.MergeBorrowers)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"mybooks/internal/domain/services"
+	"mybooks/internal/infrastructure/api/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClubsHandler sets up the routes for the book clubs handler in the provided gin.Engine.
+//
+// Parameters:
+// - router: a pointer to a gin.Engine object representing the HTTP router.
+// - clubService: a pointer to a services.ClubService object providing the book club-related operations.
+//
+// Returns: None.
+func ClubsHandler(router *gin.Engine, clubService *services.ClubService) {
+	v1 := router.Group("/v1")
+	{
+		clubsRouter := v1.Group("/clubs")
+		{
+			clubsRouter.GET("/", middlewares.AuthMiddleware(), clubService.GetClubs)
+			clubsRouter.POST("/", middlewares.AuthMiddleware(), clubService.CreateClub)
+			clubsRouter.POST("/join", middlewares.AuthMiddleware(), clubService.JoinClub)
+			clubsRouter.GET("/:clubId", middlewares.AuthMiddleware(), clubService.GetClub)
+			clubsRouter.PUT("/:clubId", middlewares.AuthMiddleware(), clubService.UpdateClub)
+			clubsRouter.DELETE("/:clubId", middlewares.AuthMiddleware(), clubService.DeleteClub)
+			clubsRouter.PUT("/:clubId/book", middlewares.AuthMiddleware(), clubService.SetClubBook)
+			clubsRouter.POST("/:clubId/join-code", middlewares.AuthMiddleware(), clubService.ResetJoinCode)
+			clubsRouter.PUT("/:clubId/progress", middlewares.AuthMiddleware(), clubService.UpdateClubProgress)
+			clubsRouter.GET("/:clubId/members", middlewares.AuthMiddleware(), clubService.GetClubMembers)
+			clubsRouter.PUT("/:clubId/members/:userId", middlewares.AuthMiddleware(), clubService.UpdateClubMember)
+			clubsRouter.DELETE("/:clubId/members/:userId", middlewares.AuthMiddleware(), clubService.RemoveClubMember)
+			clubsRouter.GET("/:clubId/sections", middlewares.AuthMiddleware(), clubService.GetClubSections)
+			clubsRouter.POST("/:clubId/sections", middlewares.AuthMiddleware(), clubService.CreateClubSection)
+			clubsRouter.PUT("/:clubId/sections/:sectionId", middlewares.AuthMiddleware(), clubService.UpdateClubSection)
+			clubsRouter.DELETE("/:clubId/sections/:sectionId", middlewares.AuthMiddleware(), clubService.DeleteClubSection)
+			clubsRouter.GET("/:clubId/sections/:sectionId/posts", middlewares.AuthMiddleware(), clubService.GetClubPosts)
+			clubsRouter.POST("/:clubId/sections/:sectionId/posts", middlewares.AuthMiddleware(), clubService.CreateClubPost)
+			clubsRouter.DELETE("/:clubId/sections/:sectionId/posts/:postId", middlewares.AuthMiddleware(), clubService.DeleteClubPost)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"mybooks/internal/domain/services"
+	"mybooks/internal/infrastructure/api/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FollowsHandler sets up the routes for the follows handler in the provided gin.Engine.
+//
+// Parameters:
+// - router: a pointer to a gin.Engine object representing the HTTP router.
+// - followService: a pointer to a services.FollowService object providing the follow-related operations.
+//
+// Returns: None.
+func FollowsHandler(router *gin.Engine, followService *services.FollowService) {
+	v1 := router.Group("/v1")
+	{
+		v1.POST("/users/:handle/follow", middlewares.AuthMiddleware(), followService.Follow)
+		v1.DELETE("/users/:handle/follow", middlewares.AuthMiddleware(), followService.Unfollow)
+
+		followRouter := v1.Group("/follows")
+		{
+			followRouter.GET("/followers", middlewares.AuthMiddleware(), followService.GetFollowers)
+			followRouter.GET("/following", middlewares.AuthMiddleware(), followService.GetFollowing)
+			followRouter.POST("/followers/:userId/approve", middlewares.AuthMiddleware(), followService.ApproveFollower)
+			followRouter.DELETE("/followers/:userId", middlewares.AuthMiddleware(), followService.RemoveFollower)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"mybooks/internal/domain/services"
+	"mybooks/internal/infrastructure/api/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GoalsHandler sets up the routes for the goal handler in the provided gin.Engine.
+//
+// Parameters:
+// - router: a pointer to a gin.Engine object representing the HTTP router.
+// - goalService: a pointer to a services.GoalService object providing the goal-related operations.
+//
+// Returns: None.
+func GoalsHandler(router *gin.Engine, goalService *services.GoalService) {
+	v1 := router.Group("/v1")
+	{
+		goalsRouter := v1.Group("/goals")
+		{
+			goalsRouter.GET("/", middlewares.AuthMiddleware(), goalService.GetGoals)
+			goalsRouter.GET("/:year/summary", middlewares.AuthMiddleware(), goalService.GetYearSummary)
+			goalsRouter.PUT("/:year", middlewares.AuthMiddleware(), goalService.SetGoal)
+			goalsRouter.DELETE("/:year/:kind", middlewares.AuthMiddleware(), goalService.DeleteGoal)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"mybooks/internal/domain/services"
+	"mybooks/internal/infrastructure/api/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HoldsHandler sets up the routes for the hold handler in the provided gin.Engine.
+//
+// Parameters:
+// - router: a pointer to a gin.Engine object representing the HTTP router.
+// - holdService: a pointer to a services.HoldService object providing the hold-related operations.
+//
+// Returns: None.
+func HoldsHandler(router *gin.Engine, holdService *services.HoldService) {
+	v1 := router.Group("/v1")
+	{
+		holdsRouter := v1.Group("/holds")
+		{
+			holdsRouter.GET("/", middlewares.AuthMiddleware(), holdService.GetHolds)
+			holdsRouter.POST("/", middlewares.AuthMiddleware(), holdService.CreateHold)
+			holdsRouter.DELETE("/:holdId", middlewares.AuthMiddleware(), holdService.CancelHold)
+		}
+
+		v1.GET("/books/:bookId/holds", middlewares.AuthMiddleware(), holdService.GetBookHolds)
+	}
+}
@@ -20,12 +20,20 @@ func LibrariesHandler(router *gin.Engine, libraryService *services.LibraryServic
 		librariesRouter := v1.Group("/libraries")
 		{
 			librariesRouter.GET("/", middlewares.AuthMiddleware(), libraryService.GetAllLibraries)
+			librariesRouter.GET("/tree", middlewares.AuthMiddleware(), libraryService.GetLibraryTree)
 			librariesRouter.GET("/:libraryId", middlewares.AuthMiddleware(), libraryService.GetLibraryByID)
 			librariesRouter.POST("/", middlewares.AuthMiddleware(), libraryService.CreateLibrary)
 			librariesRouter.PUT("/:libraryId", middlewares.AuthMiddleware(), libraryService.UpdateLibrary)
 			librariesRouter.DELETE("/:libraryId", middlewares.AuthMiddleware(), libraryService.DeleteLibrary)
+			librariesRouter.PUT("/:libraryId/move", middlewares.AuthMiddleware(), libraryService.MoveLibrary)
+			librariesRouter.PUT("/:libraryId/visibility", middlewares.AuthMiddleware(), libraryService.SetLibraryVisibility)
 			librariesRouter.POST("/:libraryId/books/:bookId", middlewares.AuthMiddleware(), libraryService.AddBookToLibrary)
 			librariesRouter.DELETE("/:libraryId/books/:bookId", middlewares.AuthMiddleware(), libraryService.RemoveBookFromLibrary)
+			librariesRouter.PUT("/:libraryId/books", middlewares.AuthMiddleware(), libraryService.ReorderLibraryBooks)
+			librariesRouter.PUT("/:libraryId/books/:bookId/position", middlewares.AuthMiddleware(), libraryService.MoveBookInLibrary)
 		}
+
+		// Public libraries are found from the public profile of their creator and need no authentication
+		v1.GET("/users/:handle/libraries/:libraryId", libraryService.GetPublicLibrary)
 	}
 }
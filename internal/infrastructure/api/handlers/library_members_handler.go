@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"mybooks/internal/domain/services"
+	"mybooks/internal/infrastructure/api/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LibraryMembersHandler sets up the routes for the library members and invitations in the provided gin.Engine.
+//
+// Parameters:
+// - router: a pointer to a gin.Engine object representing the HTTP router.
+// - libraryMemberService: a pointer to a services.LibraryMemberService object providing the sharing-related operations.
+//
+// Returns: None.
+func LibraryMembersHandler(router *gin.Engine, libraryMemberService *services.LibraryMemberService) {
+	v1 := router.Group("/v1")
+	{
+		librariesRouter := v1.Group("/libraries")
+		{
+			librariesRouter.GET("/:libraryId/members", middlewares.AuthMiddleware(), libraryMemberService.GetLibraryMembers)
+			librariesRouter.PUT("/:libraryId/members/:userId", middlewares.AuthMiddleware(), libraryMemberService.UpdateLibraryMember)
+			librariesRouter.DELETE("/:libraryId/members/:userId", middlewares.AuthMiddleware(), libraryMemberService.RemoveLibraryMember)
+			librariesRouter.GET("/:libraryId/invitations", middlewares.AuthMiddleware(), libraryMemberService.GetLibraryInvitations)
+			librariesRouter.POST("/:libraryId/invitations", middlewares.AuthMiddleware(), libraryMemberService.CreateInvitation)
+			librariesRouter.DELETE("/:libraryId/invitations/:invitationId", middlewares.AuthMiddleware(), libraryMemberService.RevokeInvitation)
+		}
+
+		invitationsRouter := v1.Group("/invitations")
+		{
+			invitationsRouter.GET("/", middlewares.AuthMiddleware(), libraryMemberService.GetPendingInvitations)
+			invitationsRouter.POST("/:token/accept", middlewares.AuthMiddleware(), libraryMemberService.AcceptInvitation)
+			invitationsRouter.POST("/:token/decline", middlewares.AuthMiddleware(), libraryMemberService.DeclineInvitation)
+		}
+	}
+}
@@ -21,6 +21,9 @@ func LoanHandler(router *gin.Engine, loanService *services.LoanService) {
 		{
 			loansRouter.POST("/", middlewares.AuthMiddleware(), loanService.CreateLoan)
 			loansRouter.GET("/", middlewares.AuthMiddleware(), loanService.GetAllLoans)
+			loansRouter.POST("/requests", middlewares.AuthMiddleware(), loanService.RequestLoan)
+			loansRouter.PUT("/:loanId/approve", middlewares.AuthMiddleware(), loanService.ApproveLoan)
+			loansRouter.PUT("/:loanId/decline", middlewares.AuthMiddleware(), loanService.DeclineLoan)
 			loansRouter.PUT("/:loanId/return", middlewares.AuthMiddleware(), loanService.ReturnLoan)
 		}
 	}
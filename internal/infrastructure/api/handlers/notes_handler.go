@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"mybooks/internal/domain/services"
+	"mybooks/internal/infrastructure/api/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NotesHandler sets up the routes for the note handler in the provided gin.Engine.
+//
+// Parameters:
+// - router: a pointer to a gin.Engine object representing the HTTP router.
+// - noteService: a pointer to a services.NoteService object providing the note-related operations.
+//
+// Returns: None.
+func NotesHandler(router *gin.Engine, noteService *services.NoteService) {
+	v1 := router.Group("/v1")
+	{
+		notesRouter := v1.Group("/notes")
+		{
+			notesRouter.GET("/", middlewares.AuthMiddleware(), noteService.SearchNotes)
+			notesRouter.PUT("/:noteId", middlewares.AuthMiddleware(), noteService.UpdateNote)
+			notesRouter.DELETE("/:noteId", middlewares.AuthMiddleware(), noteService.DeleteNote)
+			notesRouter.POST("/import/kindle", middlewares.AuthMiddleware(), noteService.ImportKindleClippings)
+			notesRouter.POST("/import/kobo", middlewares.AuthMiddleware(), noteService.ImportKoboAnnotations)
+		}
+
+		booksRouter := v1.Group("/books")
+		{
+			booksRouter.GET("/:bookId/notes", middlewares.AuthMiddleware(), noteService.GetBookNotes)
+			booksRouter.POST("/:bookId/notes", middlewares.AuthMiddleware(), noteService.CreateNote)
+			booksRouter.GET("/:bookId/notes/export", middlewares.AuthMiddleware(), noteService.ExportBookNotes)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"mybooks/internal/domain/services"
+	"mybooks/internal/infrastructure/api/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OrganizationsHandler sets up the routes for the organization handler in the provided gin.Engine.
+//
+// Parameters:
+// - router: a pointer to a gin.Engine object representing the HTTP router.
+// - organizationService: a pointer to a services.OrganizationService object providing the organization-related operations.
+//
+// Returns: None.
+func OrganizationsHandler(router *gin.Engine, organizationService *services.OrganizationService) {
+	v1 := router.Group("/v1")
+	{
+		organizationsRouter := v1.Group("/organizations")
+		{
+			organizationsRouter.GET("/", middlewares.AuthMiddleware(), organizationService.GetAllOrganizations)
+			organizationsRouter.POST("/", middlewares.AuthMiddleware(), organizationService.CreateOrganization)
+			organizationsRouter.PUT("/:organizationId", middlewares.AuthMiddleware(), organizationService.UpdateOrganization)
+			organizationsRouter.DELETE("/:organizationId", middlewares.AuthMiddleware(), organizationService.DeleteOrganization)
+			organizationsRouter.GET("/:organizationId/members", middlewares.AuthMiddleware(), organizationService.GetOrganizationMembers)
+			organizationsRouter.POST("/:organizationId/members", middlewares.AuthMiddleware(), organizationService.AddOrganizationMember)
+			organizationsRouter.PUT("/:organizationId/members/:userId", middlewares.AuthMiddleware(), organizationService.UpdateOrganizationMember)
+			organizationsRouter.DELETE("/:organizationId/members/:userId", middlewares.AuthMiddleware(), organizationService.RemoveOrganizationMember)
+		}
+	}
+}
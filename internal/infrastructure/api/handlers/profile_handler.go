@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"mybooks/internal/domain/services"
+	"mybooks/internal/infrastructure/api/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProfileHandler sets up the routes for the profile handler in the provided gin.Engine.
+//
+// Parameters:
+// - router: a pointer to a gin.Engine object representing the HTTP router.
+// - profileService: a pointer to a services.ProfileService object providing the profile-related operations.
+//
+// Returns: None.
+func ProfileHandler(router *gin.Engine, profileService *services.ProfileService) {
+	v1 := router.Group("/v1")
+	{
+		profileRouter := v1.Group("/profile")
+		{
+			profileRouter.PUT("/timezone", middlewares.AuthMiddleware(), profileService.UpdateTimezone)
+			profileRouter.PUT("/public", middlewares.AuthMiddleware(), profileService.UpdatePublicProfile)
+		}
+
+		// Public profiles are found by their handle and need no authentication
+		v1.GET("/users/:handle", profileService.GetPublicProfile)
+	}
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"mybooks/internal/domain/services"
+	"mybooks/internal/infrastructure/api/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReadingSessionsHandler sets up the routes for the reading session handler in the provided gin.Engine.
+//
+// Parameters:
+// - router: a pointer to a gin.Engine object representing the HTTP router.
+// - readingSessionService: a pointer to a services.ReadingSessionService object providing the reading session-related operations.
+//
+// Returns: None.
+func ReadingSessionsHandler(router *gin.Engine, readingSessionService *services.ReadingSessionService) {
+	v1 := router.Group("/v1")
+	{
+		readingSessionsRouter := v1.Group("/reading-sessions")
+		{
+			readingSessionsRouter.GET("/", middlewares.AuthMiddleware(), readingSessionService.GetReadingSessions)
+			readingSessionsRouter.GET("/daily", middlewares.AuthMiddleware(), readingSessionService.GetReadingDays)
+			readingSessionsRouter.PUT("/:sessionId", middlewares.AuthMiddleware(), readingSessionService.UpdateReadingSession)
+			readingSessionsRouter.DELETE("/:sessionId", middlewares.AuthMiddleware(), readingSessionService.DeleteReadingSession)
+		}
+
+		booksRouter := v1.Group("/books")
+		{
+			booksRouter.GET("/:bookId/reading-sessions", middlewares.AuthMiddleware(), readingSessionService.GetBookReadingSessions)
+			booksRouter.POST("/:bookId/reading-sessions/start", middlewares.AuthMiddleware(), readingSessionService.StartReadingSession)
+			booksRouter.POST("/:bookId/reading-sessions/stop", middlewares.AuthMiddleware(), readingSessionService.StopReadingSession)
+		}
+	}
+}
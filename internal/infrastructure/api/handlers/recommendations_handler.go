@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"mybooks/internal/domain/services"
+	"mybooks/internal/infrastructure/api/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RecommendationsHandler sets up the routes for the recommendations handler in the provided gin.Engine.
+//
+// Parameters:
+// - router: a pointer to a gin.Engine object representing the HTTP router.
+// - recommendationService: a pointer to a services.RecommendationService object providing the recommendation-related operations.
+//
+// Returns: None.
+func RecommendationsHandler(router *gin.Engine, recommendationService *services.RecommendationService) {
+	v1 := router.Group("/v1")
+	{
+		v1.GET("/recommendations", middlewares.AuthMiddleware(), recommendationService.GetRecommendations)
+	}
+}
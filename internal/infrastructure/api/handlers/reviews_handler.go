@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"mybooks/internal/domain/services"
+	"mybooks/internal/infrastructure/api/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReviewsHandler sets up the routes for the review handler in the provided gin.Engine.
+//
+// Parameters:
+// - router: a pointer to a gin.Engine object representing the HTTP router.
+// - reviewService: a pointer to a services.ReviewService object providing the review-related operations.
+//
+// Returns: None.
+func ReviewsHandler(router *gin.Engine, reviewService *services.ReviewService) {
+	v1 := router.Group("/v1")
+	{
+		v1.GET("/reviews", middlewares.AuthMiddleware(), reviewService.GetReviews)
+
+		booksRouter := v1.Group("/books")
+		{
+			booksRouter.GET("/:bookId/review", middlewares.AuthMiddleware(), reviewService.GetReview)
+			booksRouter.PUT("/:bookId/review", middlewares.AuthMiddleware(), reviewService.SetReview)
+			booksRouter.DELETE("/:bookId/review", middlewares.AuthMiddleware(), reviewService.DeleteReview)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"mybooks/internal/domain/services"
+	"mybooks/internal/infrastructure/api/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SeriesHandler sets up the routes for the series handler in the provided gin.Engine.
+//
+// Parameters:
+// - router: a pointer to a gin.Engine object representing the HTTP router.
+// - seriesService: a pointer to a services.SeriesService object providing the series-related operations.
+//
+// Returns: None.
+func SeriesHandler(router *gin.Engine, seriesService *services.SeriesService) {
+	v1 := router.Group("/v1")
+	{
+		seriesRouter := v1.Group("/series")
+		{
+			seriesRouter.GET("/", middlewares.AuthMiddleware(), seriesService.GetAllSeries)
+			seriesRouter.GET("/next-unread", middlewares.AuthMiddleware(), seriesService.GetNextUnreadInSeries)
+			seriesRouter.GET("/:seriesId", middlewares.AuthMiddleware(), seriesService.GetSeriesByID)
+			seriesRouter.POST("/", middlewares.AuthMiddleware(), seriesService.CreateSeries)
+			seriesRouter.PUT("/:seriesId", middlewares.AuthMiddleware(), seriesService.UpdateSeries)
+			seriesRouter.DELETE("/:seriesId", middlewares.AuthMiddleware(), seriesService.DeleteSeries)
+			seriesRouter.PUT("/:seriesId/books/:bookId", middlewares.AuthMiddleware(), seriesService.AddBookToSeries)
+			seriesRouter.DELETE("/:seriesId/books/:bookId", middlewares.AuthMiddleware(), seriesService.RemoveBookFromSeries)
+		}
+	}
+}
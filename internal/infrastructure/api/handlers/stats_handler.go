@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"mybooks/internal/domain/services"
+	"mybooks/internal/infrastructure/api/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StatsHandler sets up the routes for the stats handler in the provided gin.Engine.
+//
+// Parameters:
+// - router: a pointer to a gin.Engine object representing the HTTP router.
+// - statsService: a pointer to a services.StatsService object providing the statistics of the collection.
+//
+// Returns: None.
+func StatsHandler(router *gin.Engine, statsService *services.StatsService) {
+	v1 := router.Group("/v1")
+	{
+		v1.GET("/stats", middlewares.AuthMiddleware(), statsService.GetStats)
+	}
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"mybooks/internal/domain/services"
+	"mybooks/internal/infrastructure/api/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TagsHandler registers the tag handler with the provided gin.Engine and services.TagService.
+//
+// Parameters:
+// - router: a pointer to a gin.Engine object representing the HTTP router.
+// - tagService: a pointer to a services.TagService object providing the tag-related operations.
+//
+// Returns: None.
+func TagsHandler(router *gin.Engine, tagService *services.TagService) {
+	v1 := router.Group("/v1")
+	{
+		tagsRouter := v1.Group("/tags")
+		{
+			tagsRouter.GET("/", middlewares.AuthMiddleware(), tagService.GetAllTags)
+			tagsRouter.POST("/", middlewares.AuthMiddleware(), tagService.CreateTag)
+			tagsRouter.PUT("/:tagId", middlewares.AuthMiddleware(), tagService.UpdateTag)
+			tagsRouter.DELETE("/:tagId", middlewares.AuthMiddleware(), tagService.DeleteTag)
+			tagsRouter.POST("/:tagId/merge", middlewares.AuthMiddleware(), tagService.MergeTags)
+		}
+
+		v1.POST("/books/:bookId/tags", middlewares.AuthMiddleware(), tagService.AddTagToBook)
+		v1.DELETE("/books/:bookId/tags/:tagId", middlewares.AuthMiddleware(), tagService.RemoveTagFromBook)
+	}
+}
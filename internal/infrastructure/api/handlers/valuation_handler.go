@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"mybooks/internal/domain/services"
+	"mybooks/internal/infrastructure/api/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ValuationHandler sets up the routes for the valuation handler in the provided gin.Engine.
+//
+// Parameters:
+// - router: a pointer to a gin.Engine object representing the HTTP router.
+// - valuationService: a pointer to a services.ValuationService object providing the valuation-related operations.
+//
+// Returns: None.
+func ValuationHandler(router *gin.Engine, valuationService *services.ValuationService) {
+	v1 := router.Group("/v1")
+	{
+		valuationRouter := v1.Group("/valuation")
+		{
+			valuationRouter.GET("/", middlewares.AuthMiddleware(), valuationService.GetValuation)
+			valuationRouter.GET("/export", middlewares.AuthMiddleware(), valuationService.ExportValuation)
+		}
+
+		exchangeRatesRouter := v1.Group("/exchange-rates")
+		{
+			exchangeRatesRouter.GET("/", middlewares.AuthMiddleware(), valuationService.GetExchangeRates)
+			exchangeRatesRouter.PUT("/", middlewares.AuthMiddleware(), valuationService.SetExchangeRate)
+			exchangeRatesRouter.DELETE("/:rateId", middlewares.AuthMiddleware(), valuationService.DeleteExchangeRate)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"mybooks/internal/domain/services"
+	"mybooks/internal/infrastructure/api/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WishlistHandler sets up the routes for the wishlist handler in the provided gin.Engine.
+//
+// Parameters:
+// - router: a pointer to a gin.Engine object representing the HTTP router.
+// - wishlistService: a pointer to a services.WishlistService object providing the wishlist-related operations.
+//
+// Returns: None.
+func WishlistHandler(router *gin.Engine, wishlistService *services.WishlistService) {
+	v1 := router.Group("/v1")
+	{
+		wishlistRouter := v1.Group("/wishlist")
+		{
+			wishlistRouter.GET("/", middlewares.AuthMiddleware(), wishlistService.GetWishlist)
+			wishlistRouter.POST("/", middlewares.AuthMiddleware(), wishlistService.CreateWishlistItem)
+			wishlistRouter.POST("/share", middlewares.AuthMiddleware(), wishlistService.ShareWishlist)
+			wishlistRouter.DELETE("/share", middlewares.AuthMiddleware(), wishlistService.UnshareWishlist)
+			wishlistRouter.GET("/:itemId", middlewares.AuthMiddleware(), wishlistService.GetWishlistItem)
+			wishlistRouter.PUT("/:itemId", middlewares.AuthMiddleware(), wishlistService.UpdateWishlistItem)
+			wishlistRouter.DELETE("/:itemId", middlewares.AuthMiddleware(), wishlistService.DeleteWishlistItem)
+			wishlistRouter.POST("/:itemId/buy", middlewares.AuthMiddleware(), wishlistService.BuyWishlistItem)
+		}
+
+		// Public wishlists are shared by link and need no authentication
+		v1.GET("/wishlists/:token", wishlistService.GetSharedWishlist)
+	}
+}
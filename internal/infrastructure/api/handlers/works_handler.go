@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"mybooks/internal/domain/services"
+	"mybooks/internal/infrastructure/api/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WorksHandler sets up the routes for the work and copy handlers in the provided gin.Engine.
+//
+// Parameters:
+// - router: a pointer to a gin.Engine object representing the HTTP router.
+// - workService: a pointer to a services.WorkService object providing the work-related operations.
+// - copyService: a pointer to a services.CopyService object providing the copy-related operations.
+//
+// Returns: None.
+func WorksHandler(router *gin.Engine, workService *services.WorkService, copyService *services.CopyService) {
+	v1 := router.Group("/v1")
+	{
+		worksRouter := v1.Group("/works")
+		{
+			worksRouter.GET("/", middlewares.AuthMiddleware(), workService.GetAllWorks)
+			worksRouter.GET("/:workId", middlewares.AuthMiddleware(), workService.GetWorkByID)
+			worksRouter.PUT("/:workId", middlewares.AuthMiddleware(), workService.UpdateWork)
+			worksRouter.POST("/:workId/merge", middlewares.AuthMiddleware(), workService.MergeWorks)
+		}
+
+		copiesRouter := v1.Group("/copies")
+		{
+			copiesRouter.PUT("/:copyId", middlewares.AuthMiddleware(), copyService.UpdateCopy)
+			copiesRouter.DELETE("/:copyId", middlewares.AuthMiddleware(), copyService.DeleteCopy)
+		}
+
+		v1.GET("/books/:bookId/copies", middlewares.AuthMiddleware(), copyService.GetBookCopies)
+		v1.POST("/books/:bookId/copies", middlewares.AuthMiddleware(), copyService.CreateCopy)
+	}
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"mybooks/internal/domain/services"
+	"mybooks/internal/infrastructure/api/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// YearReviewsHandler sets up the routes for the year reviews handler in the provided gin.Engine.
+//
+// Parameters:
+// - router: a pointer to a gin.Engine object representing the HTTP router.
+// - yearReviewService: a pointer to a services.YearReviewService object providing the year review-related operations.
+//
+// Returns: None.
+func YearReviewsHandler(router *gin.Engine, yearReviewService *services.YearReviewService) {
+	v1 := router.Group("/v1")
+	{
+		yearReviewRouter := v1.Group("/year-review")
+		{
+			yearReviewRouter.GET("/:year", middlewares.AuthMiddleware(), yearReviewService.GetYearReview)
+			yearReviewRouter.POST("/:year/share", middlewares.AuthMiddleware(), yearReviewService.ShareYearReview)
+			yearReviewRouter.DELETE("/:year/share", middlewares.AuthMiddleware(), yearReviewService.UnshareYearReview)
+		}
+
+		// Public year reviews are shared by link and need no authentication
+		v1.GET("/year-reviews/:token", yearReviewService.GetSharedYearReview)
+		v1.GET("/year-reviews/:token/html", yearReviewService.GetSharedYearReviewHTML)
+		v1.GET("/year-reviews/:token/png", yearReviewService.GetSharedYearReviewPNG)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"mybooks/internal/infrastructure/constants"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -31,6 +32,12 @@ import (
 // An anonymous struct containing the user's public information is created.
 // This struct is attached to the request context.
 //
+// The active organization is selected by the "X-Organization-ID" header, or is the personal
+// organization of the user when the header is missing. If the user is not a member of the
+// selected organization, the request is aborted with a 403 Forbidden status, and if the organization
+// cannot be retrieved, with a 500 Internal Server Error status.
+// The organization, with the role of the user in it, is attached to the request context.
+//
 // The next handler in the chain is called.
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -91,6 +98,29 @@ func AuthMiddleware() gin.HandlerFunc {
 		// Attach the public user to the context
 		c.Set("user", user)
 
+		// Select the active organization, the personal organization of the user by default
+		organizationID := c.GetHeader(constants.OrganizationHeaderName)
+		if organizationID != "" {
+			if _, err := uuid.Parse(organizationID); err != nil {
+				c.AbortWithStatus(http.StatusBadRequest)
+				return
+			}
+		}
+
+		organization, err := repositories.NewOrganizationRepository(config.DB()).GetActiveOrganization(user.ID.String(), organizationID)
+		if err != nil {
+			if strings.Contains(err.Error(), "organization not found") || strings.Contains(err.Error(), "insufficient") {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		// Attach the active organization to the context
+		c.Set("organization", organization)
+
 		// Continue with the next handler
 		c.Next()
 	}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"log"
+	"mybooks/internal/domain/events"
 	"mybooks/internal/domain/repositories"
 	"mybooks/internal/domain/services"
 	"mybooks/internal/infrastructure/api/handlers"
@@ -9,6 +10,7 @@ import (
 	"mybooks/internal/infrastructure/config"
 	"net/http"
 	"os"
+	"time"
 
 	"mybooks/docs"
 
@@ -30,6 +32,8 @@ import (
 // connection.
 // It registers the authentication, libraries, books, profile, billing, loan, and
 // reading handlers with the Gin instance.
+// It starts the background jobs expiring the holds that were not picked up and stopping the
+// forgotten reading sessions, and records the activities of the feeds from the domain events.
 // It adds a health check handler that returns "OK" with a status code of 200.
 // It gets the HTTP port from the environment variable or sets it to "8080" if
 // it is not set.
@@ -70,12 +74,62 @@ func StartServer() error {
 	bookService := services.NewBookService(repositories.NewBookRepository(config.DB()))
 	libraryService := services.NewLibraryService(repositories.NewLibraryRepository(config.DB()))
 	loanService := services.NewLoanService(repositories.NewLoanRepository(config.DB()))
+	authorService := services.NewAuthorService(repositories.NewAuthorRepository(config.DB()))
+	seriesService := services.NewSeriesService(repositories.NewSeriesRepository(config.DB()))
+	tagService := services.NewTagService(repositories.NewTagRepository(config.DB()))
+	libraryMemberService := services.NewLibraryMemberService(repositories.NewLibraryMemberRepository(config.DB()))
+	organizationService := services.NewOrganizationService(repositories.NewOrganizationRepository(config.DB()))
+	holdService := services.NewHoldService(repositories.NewHoldRepository(config.DB()))
+	borrowerService := services.NewBorrowerService(repositories.NewBorrowerRepository(config.DB()))
+	workService := services.NewWorkService(repositories.NewWorkRepository(config.DB()))
+	copyService := services.NewCopyService(repositories.NewCopyRepository(config.DB()))
+	valuationService := services.NewValuationService(repositories.NewValuationRepository(config.DB()))
+	wishlistService := services.NewWishlistService(repositories.NewWishlistRepository(config.DB()))
+	reviewService := services.NewReviewService(repositories.NewReviewRepository(config.DB()))
+	noteService := services.NewNoteService(repositories.NewNoteRepository(config.DB()))
+	readingSessionService := services.NewReadingSessionService(repositories.NewReadingSessionRepository(config.DB()))
+	goalService := services.NewGoalService(repositories.NewGoalRepository(config.DB()))
+	profileService := services.NewProfileService(repositories.NewProfileRepository(config.DB()))
+	statsService := services.NewStatsService(repositories.NewStatsRepository(config.DB()))
+	yearReviewService := services.NewYearReviewService(repositories.NewYearReviewRepository(config.DB()))
+	followService := services.NewFollowService(repositories.NewFollowRepository(config.DB()))
+	activityService := services.NewActivityService(repositories.NewActivityRepository(config.DB()))
+	clubService := services.NewClubService(repositories.NewClubRepository(config.DB()))
+	recommendationService := services.NewRecommendationService(repositories.NewRecommendationRepository(config.DB()))
+
+	// Domain events
+	events.Subscribe(activityService.RecordEvent)
+
+	// Background jobs
+	go holdService.ExpireHolds(15 * time.Minute)
+	go readingSessionService.StopForgottenReadingSessions(15 * time.Minute)
 
 	// Routes
 	handlers.AuthHandler(router, authService)
 	handlers.LibrariesHandler(router, libraryService)
 	handlers.BooksHandler(router, bookService)
 	handlers.LoanHandler(router, loanService)
+	handlers.AuthorsHandler(router, authorService)
+	handlers.SeriesHandler(router, seriesService)
+	handlers.TagsHandler(router, tagService)
+	handlers.LibraryMembersHandler(router, libraryMemberService)
+	handlers.OrganizationsHandler(router, organizationService)
+	handlers.HoldsHandler(router, holdService)
+	handlers.BorrowersHandler(router, borrowerService)
+	handlers.WorksHandler(router, workService, copyService)
+	handlers.ValuationHandler(router, valuationService)
+	handlers.WishlistHandler(router, wishlistService)
+	handlers.ReviewsHandler(router, reviewService)
+	handlers.NotesHandler(router, noteService)
+	handlers.ReadingSessionsHandler(router, readingSessionService)
+	handlers.GoalsHandler(router, goalService)
+	handlers.ProfileHandler(router, profileService)
+	handlers.StatsHandler(router, statsService)
+	handlers.YearReviewsHandler(router, yearReviewService)
+	handlers.FollowsHandler(router, followService)
+	handlers.ActivitiesHandler(router, activityService)
+	handlers.ClubsHandler(router, clubService)
+	handlers.RecommendationsHandler(router, recommendationService)
 
 	// Others routes
 	router.GET("/v1/health", func(c *gin.Context) {
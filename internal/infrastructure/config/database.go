@@ -31,8 +31,21 @@ func DatabaseInit() {
 		panic(e)
 	}
 
+	// Use the custom join tables, which carry extra columns
+	if e = database.SetupJoinTable(&models.Library{}, "Books", &models.BookLibrary{}); e != nil {
+		panic(e)
+	}
+	if e = database.SetupJoinTable(&models.Book{}, "Libraries", &models.BookLibrary{}); e != nil {
+		panic(e)
+	}
+
 	// Migrate the schema
-	database.AutoMigrate(&models.User{}, &models.Book{}, &models.Library{}, &models.Loan{}, &models.ValidationToken{})
+	database.AutoMigrate(&models.User{}, &models.Book{}, &models.Library{}, &models.BookLibrary{}, &models.Loan{}, &models.ValidationToken{}, &models.Author{}, &models.BookAuthor{}, &models.Series{}, &models.BookSeries{}, &models.Tag{}, &models.LibraryMember{}, &models.LibraryInvitation{}, &models.Organization{}, &models.OrganizationMember{}, &models.Hold{}, &models.Borrower{}, &models.Work{}, &models.Copy{}, &models.ExchangeRate{}, &models.WishlistItem{}, &models.WishlistLink{}, &models.WishlistShare{}, &models.Review{}, &models.Note{}, &models.ReadingSession{}, &models.ReadingGoal{}, &models.YearReviewShare{}, &models.Follow{}, &models.Activity{}, &models.ActivitySetting{}, &models.Club{}, &models.ClubMember{}, &models.ClubSection{}, &models.ClubPost{})
+
+	// Migrate the data
+	if e = RunMigrations(database); e != nil {
+		panic(e)
+	}
 }
 
 // DB returns the *gorm.DB object representing the database connection.
@@ -0,0 +1,417 @@
+package config
+
+import (
+	"mybooks/internal/domain/models"
+	"mybooks/pkg"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RunMigrations runs the data migrations that cannot be expressed by AutoMigrate.
+//
+// Every migration must be idempotent, since they run on every start of the server.
+//
+// Parameters:
+// - db: a pointer to a gorm.DB object representing the database connection.
+//
+// Returns:
+// - error: an error object if one of the migrations failed.
+func RunMigrations(db *gorm.DB) error {
+	migrations := []func(*gorm.DB) error{
+		migrateBookAuthors,
+		migrateLibraryPositions,
+		migrateLibraryOwners,
+		migrateOrganizations,
+		migrateLoanStatuses,
+		migrateLoanRequestIndex,
+		migrateBorrowers,
+		migrateWorks,
+		migrateCopies,
+		migrateNoteSearchIndex,
+		migrateBookReadDates,
+		migrateReadingSessionInProgressIndex,
+	}
+
+	for _, migration := range migrations {
+		if err := migration(db); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateBookAuthors splits the free-text author of the books without credits into author rows.
+func migrateBookAuthors(db *gorm.DB) error {
+	var books []struct {
+		ID     uuid.UUID
+		Author string
+		UserID uuid.UUID
+	}
+
+	err := db.Table("books").
+		Select("id, author, user_id").
+		Where("NOT EXISTS (SELECT 1 FROM book_authors WHERE book_authors.book_id = books.id)").
+		Find(&books).Error
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		authorIDs := make(map[string]uuid.UUID)
+
+		for _, book := range books {
+			for position, name := range pkg.SplitAuthorNames(book.Author) {
+				key := book.UserID.String() + ":" + strings.ToLower(name)
+
+				authorID, ok := authorIDs[key]
+				if !ok {
+					var existingIDs []uuid.UUID
+					if err := tx.Table("authors").
+						Where("user_id = ? AND LOWER(name) = LOWER(?)", book.UserID, name).
+						Limit(1).
+						Pluck("id", &existingIDs).Error; err != nil {
+						return err
+					}
+
+					if len(existingIDs) > 0 {
+						authorID = existingIDs[0]
+					}
+				}
+
+				if authorID == uuid.Nil {
+					id, err := pkg.GenerateRandomID()
+					if err != nil {
+						return err
+					}
+
+					authorID = id
+					if err := tx.Exec("INSERT INTO authors (id, name, sort_name, user_id, created_at, updated_at) VALUES (?, ?, ?, ?, NOW(), NOW())",
+						authorID, name, pkg.AuthorSortName(name), book.UserID).Error; err != nil {
+						return err
+					}
+				}
+
+				authorIDs[key] = authorID
+
+				if err := tx.Table("book_authors").Clauses(clause.OnConflict{DoNothing: true}).Create(map[string]interface{}{
+					"book_id":   book.ID,
+					"author_id": authorID,
+					"role":      "author",
+					"position":  position,
+				}).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// migrateLibraryPositions places the books added to a library before manual ordering existed
+// after the ordered books, in the order they were added to the collection. Ordering only gives
+// positive positions, so the books still at the default position of 0 are the ones to place.
+func migrateLibraryPositions(db *gorm.DB) error {
+	return db.Exec(`UPDATE book_library SET position = ranked.position FROM (
+		SELECT bl.library_id, bl.book_id,
+			COALESCE((SELECT MAX(m.position) FROM book_library m WHERE m.library_id = bl.library_id), 0)
+				+ ROW_NUMBER() OVER (PARTITION BY bl.library_id ORDER BY b.created_at, bl.book_id) * ? AS position
+		FROM book_library bl JOIN books b ON b.id = bl.book_id
+		WHERE bl.position = 0
+	) ranked WHERE book_library.library_id = ranked.library_id AND book_library.book_id = ranked.book_id`,
+		models.LibraryPositionGap).Error
+}
+
+// migrateLibraryOwners makes the creator of every library created before sharing existed its owner.
+// Libraries created since then always keep an owner, so a creator removed from one is not made its
+// owner again.
+func migrateLibraryOwners(db *gorm.DB) error {
+	return db.Exec(`INSERT INTO library_members (library_id, user_id, role, created_at, updated_at)
+		SELECT id, user_id, 'owner', created_at, NOW() FROM libraries
+		WHERE NOT EXISTS (SELECT 1 FROM library_members m WHERE m.library_id = libraries.id)
+		ON CONFLICT DO NOTHING`).Error
+}
+
+// migrateOrganizations creates the personal organization of every user registered before organizations
+// existed and moves the books, libraries, loans, authors, series and tags of the user into it.
+func migrateOrganizations(db *gorm.DB) error {
+	var userIDs []uuid.UUID
+
+	err := db.Table("users").
+		Where("NOT EXISTS (SELECT 1 FROM organizations WHERE organizations.personal AND organizations.user_id = users.id)").
+		Pluck("id", &userIDs).Error
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, userID := range userIDs {
+			id, err := pkg.GenerateRandomID()
+			if err != nil {
+				return err
+			}
+
+			if err := tx.Exec("INSERT INTO organizations (id, name, personal, user_id, created_at, updated_at) VALUES (?, 'Personal', TRUE, ?, NOW(), NOW())",
+				id, userID).Error; err != nil {
+				return err
+			}
+
+			if err := tx.Exec("INSERT INTO organization_members (organization_id, user_id, role, created_at, updated_at) VALUES (?, ?, 'owner', NOW(), NOW())",
+				id, userID).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, table := range []string{"books", "libraries", "loans", "authors", "series", "tags"} {
+			if err := tx.Exec(`UPDATE ` + table + ` SET organization_id = organizations.id FROM organizations
+				WHERE ` + table + `.organization_id IS NULL AND organizations.personal AND organizations.user_id = ` + table + `.user_id`).Error; err != nil {
+				return err
+			}
+		}
+
+		// The names of authors and tags are now unique per organization instead of per user
+		if err := tx.Exec("DROP INDEX IF EXISTS idx_authors_user_name").Error; err != nil {
+			return err
+		}
+
+		return tx.Exec("DROP INDEX IF EXISTS idx_tags_user_name").Error
+	})
+}
+
+// migrateLoanStatuses marks the loans returned before loans had a status as returned.
+func migrateLoanStatuses(db *gorm.DB) error {
+	return db.Model(&models.Loan{}).
+		Where("is_returned AND status <> ?", models.LoanStatusReturned).
+		Update("status", models.LoanStatusReturned).Error
+}
+
+// migrateLoanRequestIndex allows a borrower a single pending request per book. The duplicated requests
+// made before the index existed are declined, keeping the oldest.
+func migrateLoanRequestIndex(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`UPDATE loans SET status = ? WHERE status = ? AND EXISTS (
+			SELECT 1 FROM loans older WHERE older.book_id = loans.book_id AND older.borrower_id = loans.borrower_id
+				AND older.status = loans.status AND (older.created_at, older.id) < (loans.created_at, loans.id)
+		)`, models.LoanStatusDeclined, models.LoanStatusRequested).Error; err != nil {
+			return err
+		}
+
+		return tx.Exec("CREATE UNIQUE INDEX IF NOT EXISTS " + models.LoanRequestIndex + " ON loans (book_id, borrower_id) WHERE status = '" + models.LoanStatusRequested + "'").Error
+	})
+}
+
+// migrateBorrowers groups the borrower names typed on the loans made before the contact book existed
+// into borrowers. Names that only differ by case or spacing become the same borrower, named after the
+// most used spelling.
+func migrateBorrowers(db *gorm.DB) error {
+	var loans []struct {
+		ID             uuid.UUID
+		BorrowerName   string
+		OrganizationID uuid.UUID
+		UserID         uuid.UUID
+	}
+
+	err := db.Table("loans").
+		Select("id, borrower_name, organization_id, user_id").
+		Where("borrower_contact_id IS NULL AND borrower_id IS NULL AND organization_id IS NOT NULL").
+		Order("created_at").
+		Find(&loans).Error
+	if err != nil {
+		return err
+	}
+
+	type group struct {
+		organizationID uuid.UUID
+		userID         uuid.UUID
+		normalizedName string
+		spellings      map[string]int
+		loanIDs        []uuid.UUID
+	}
+
+	groups := make(map[string]*group)
+	keys := make([]string, 0)
+	for _, loan := range loans {
+		normalizedName := pkg.NormalizeBorrowerName(loan.BorrowerName)
+		if normalizedName == "" {
+			continue
+		}
+
+		key := loan.OrganizationID.String() + ":" + normalizedName
+		if _, ok := groups[key]; !ok {
+			groups[key] = &group{
+				organizationID: loan.OrganizationID,
+				userID:         loan.UserID,
+				normalizedName: normalizedName,
+				spellings:      make(map[string]int),
+			}
+			keys = append(keys, key)
+		}
+
+		groups[key].spellings[strings.Join(strings.Fields(loan.BorrowerName), " ")]++
+		groups[key].loanIDs = append(groups[key].loanIDs, loan.ID)
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, key := range keys {
+			group := groups[key]
+
+			var existingIDs []uuid.UUID
+			if err := tx.Table("borrowers").
+				Where("organization_id = ? AND normalized_name = ?", group.organizationID, group.normalizedName).
+				Limit(1).
+				Pluck("id", &existingIDs).Error; err != nil {
+				return err
+			}
+
+			var borrowerID uuid.UUID
+			if len(existingIDs) > 0 {
+				borrowerID = existingIDs[0]
+			} else {
+				name := ""
+				for spelling, count := range group.spellings {
+					if count > group.spellings[name] || (count == group.spellings[name] && spelling < name) {
+						name = spelling
+					}
+				}
+
+				id, err := pkg.GenerateRandomID()
+				if err != nil {
+					return err
+				}
+
+				borrowerID = id
+				if err := tx.Exec("INSERT INTO borrowers (id, name, normalized_name, organization_id, user_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?, NOW(), NOW())",
+					borrowerID, name, group.normalizedName, group.organizationID, group.userID).Error; err != nil {
+					return err
+				}
+			}
+
+			if err := tx.Table("loans").Where("id IN ?", group.loanIDs).Update("borrower_contact_id", borrowerID).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// migrateWorks groups the books created before works existed into works. Books of an organization with
+// the same title and author, regardless of case, become editions of the same work.
+func migrateWorks(db *gorm.DB) error {
+	var books []struct {
+		ID             uuid.UUID
+		Title          string
+		Author         string
+		OrganizationID uuid.UUID
+		UserID         uuid.UUID
+	}
+
+	err := db.Table("books").
+		Select("id, title, author, organization_id, user_id").
+		Where("work_id IS NULL AND organization_id IS NOT NULL").
+		Order("created_at").
+		Find(&books).Error
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		workIDs := make(map[string]uuid.UUID)
+
+		for _, book := range books {
+			key := book.OrganizationID.String() + ":" + strings.ToLower(book.Title) + ":" + strings.ToLower(book.Author)
+
+			workID, ok := workIDs[key]
+			if !ok {
+				id, err := pkg.GenerateRandomID()
+				if err != nil {
+					return err
+				}
+
+				workID = id
+				if err := tx.Exec("INSERT INTO works (id, title, author, organization_id, user_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?, NOW(), NOW())",
+					workID, book.Title, book.Author, book.OrganizationID, book.UserID).Error; err != nil {
+					return err
+				}
+
+				workIDs[key] = workID
+			}
+
+			if err := tx.Exec("UPDATE books SET work_id = ? WHERE id = ?", workID, book.ID).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// migrateCopies gives a copy to every book created before copies existed and attaches the loans made
+// before then to the copy of their book. The last copy of a book cannot be deleted, so the books without
+// copies are the ones created before then.
+func migrateCopies(db *gorm.DB) error {
+	var books []struct {
+		ID             uuid.UUID
+		OrganizationID uuid.UUID
+		UserID         uuid.UUID
+	}
+
+	err := db.Table("books").
+		Select("id, organization_id, user_id").
+		Where("NOT EXISTS (SELECT 1 FROM copies WHERE copies.book_id = books.id)").
+		Find(&books).Error
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, book := range books {
+			id, err := pkg.GenerateRandomID()
+			if err != nil {
+				return err
+			}
+
+			if err := tx.Exec("INSERT INTO copies (id, book_id, organization_id, user_id, created_at, updated_at) VALUES (?, ?, ?, ?, NOW(), NOW())",
+				id, book.ID, book.OrganizationID, book.UserID).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Exec(`UPDATE loans SET copy_id = (
+			SELECT copies.id FROM copies WHERE CAST(copies.book_id AS TEXT) = loans.book_id ORDER BY copies.created_at LIMIT 1
+		) WHERE copy_id IS NULL AND status NOT IN ?`, []string{models.LoanStatusRequested, models.LoanStatusDeclined}).Error
+	})
+}
+
+// migrateNoteSearchIndex creates the full-text search index of the notes, on the same expression as the searches.
+func migrateNoteSearchIndex(db *gorm.DB) error {
+	return db.Exec("CREATE INDEX IF NOT EXISTS idx_notes_search ON notes USING GIN (to_tsvector('simple', text || ' ' || comment))").Error
+}
+
+// migrateBookReadDates dates the books marked as read before the read date existed with their last update.
+func migrateBookReadDates(db *gorm.DB) error {
+	return db.Exec("UPDATE books SET read_at = updated_at WHERE read = true AND read_at IS NULL").Error
+}
+
+// migrateReadingSessionInProgressIndex allows a user a single reading session in progress. The sessions left in
+// progress before the index existed are stopped automatically when the latest session of the user started.
+func migrateReadingSessionInProgressIndex(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`UPDATE reading_sessions SET ended_at = latest.started_at,
+			duration = GREATEST(EXTRACT(EPOCH FROM latest.started_at - reading_sessions.started_at), 0),
+			auto_stopped = TRUE
+		FROM (
+			SELECT DISTINCT ON (user_id) id, user_id, started_at FROM reading_sessions
+			WHERE ended_at IS NULL ORDER BY user_id, started_at DESC, id DESC
+		) latest
+		WHERE reading_sessions.user_id = latest.user_id AND reading_sessions.ended_at IS NULL AND reading_sessions.id <> latest.id`).Error; err != nil {
+			return err
+		}
+
+		return tx.Exec("CREATE UNIQUE INDEX IF NOT EXISTS " + models.ReadingSessionInProgressIndex + " ON reading_sessions (user_id) WHERE ended_at IS NULL").Error
+	})
+}
@@ -6,4 +6,7 @@ const (
 
 	// AuthCookieName represents the name of the authentication cookie.
 	AuthCookieName = "access_token"
+
+	// OrganizationHeaderName represents the name of the header selecting the active organization.
+	OrganizationHeaderName = "X-Organization-ID"
 )
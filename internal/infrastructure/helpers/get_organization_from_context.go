@@ -0,0 +1,26 @@
+package helpers
+
+import (
+	"errors"
+	"mybooks/internal/domain/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetOrganizationFromContext retrieves the active organization from the gin.Context and returns a pointer to the models.Organization and an error.
+//
+// The active organization is selected by the AuthMiddleware and carries the role of the user in it.
+//
+// Parameters:
+// - c: a pointer to a gin.Context object representing the HTTP request and response.
+//
+// Returns:
+// - *models.Organization
+func GetOrganizationFromContext(c *gin.Context) (*models.Organization, error) {
+	organization, exists := c.Get("organization")
+	if !exists {
+		return nil, errors.New("organization not found")
+	}
+
+	return organization.(*models.Organization), nil
+}
@@ -0,0 +1,49 @@
+package pkg
+
+import "strings"
+
+// nameSuffixes are tokens that follow a surname and should stay attached to the given names.
+var nameSuffixes = map[string]bool{
+	"jr": true, "jr.": true, "sr": true, "sr.": true, "ii": true, "iii": true, "iv": true, "filho": true, "neto": true,
+}
+
+// surnameParticles are case-insensitive prefixes that belong to the surname (e.g. "Le Guin", "de Assis").
+var surnameParticles = map[string]bool{
+	"de": true, "da": true, "do": true, "dos": true, "das": true, "del": true, "della": true, "di": true,
+	"du": true, "la": true, "le": true, "van": true, "von": true, "der": true, "den": true, "ten": true,
+}
+
+// AuthorSortName builds the "Last, First" form of an author name used for alphabetical sorting.
+//
+// Surname particles such as "de" or "Le" are kept with the surname and suffixes such as "Jr."
+// are kept at the end, so "Ursula K. Le Guin" becomes "Le Guin, Ursula K." and
+// "Martin Luther King Jr." becomes "King, Martin Luther Jr.". Single-word names are returned as is.
+//
+// Parameters:
+// - name: the author name in natural order.
+//
+// Returns:
+// - string: the sort name.
+func AuthorSortName(name string) string {
+	tokens := strings.Fields(name)
+	if len(tokens) < 2 {
+		return strings.Join(tokens, " ")
+	}
+
+	suffix := ""
+	if last := tokens[len(tokens)-1]; nameSuffixes[strings.ToLower(last)] {
+		suffix = " " + last
+		tokens = tokens[:len(tokens)-1]
+	}
+
+	if len(tokens) < 2 {
+		return strings.Join(tokens, " ") + suffix
+	}
+
+	start := len(tokens) - 1
+	for start > 1 && surnameParticles[strings.ToLower(tokens[start-1])] {
+		start--
+	}
+
+	return strings.Join(tokens[start:], " ") + ", " + strings.Join(tokens[:start], " ") + suffix
+}
@@ -0,0 +1,17 @@
+package pkg
+
+import "strings"
+
+// NormalizeBorrowerName returns the case-insensitive form of a borrower name used for matching and uniqueness.
+//
+// The name is lowercased and runs of whitespace are collapsed into a single space,
+// so "Jane Doe", " jane  doe " and "JANE DOE" all normalize to "jane doe".
+//
+// Parameters:
+// - name: the borrower name as typed by the user.
+//
+// Returns:
+// - string: the normalized borrower name.
+func NormalizeBorrowerName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
@@ -0,0 +1,17 @@
+package pkg
+
+import "strings"
+
+// NormalizeTagName returns the case-insensitive form of a tag name used for matching and uniqueness.
+//
+// The name is lowercased and runs of whitespace are collapsed into a single space,
+// so "Sci-Fi", " sci-fi " and "SCI-FI" all normalize to "sci-fi".
+//
+// Parameters:
+// - name: the tag name as typed by the user.
+//
+// Returns:
+// - string: the normalized tag name.
+func NormalizeTagName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
@@ -0,0 +1,212 @@
+package pkg
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Clipping is a highlight, note or bookmark exported from an e-reader.
+type Clipping struct {
+	Title    string
+	Author   string
+	Kind     string // "highlight", "note" or "bookmark"
+	Text     string
+	Comment  string
+	Page     int
+	Location string
+	AddedAt  time.Time
+	SourceID string // identifies the clipping on the e-reader, when it has an identifier
+}
+
+// kindleSeparator is the line closing every clipping of a Kindle clippings file.
+const kindleSeparator = "=========="
+
+var (
+	kindleTitleAuthor = regexp.MustCompile(`^(.*?)\s*\(([^()]*)\)\s*$`)
+	kindlePage        = regexp.MustCompile(`(?i)\b(?:page|seite|p[áa]gina)\s+(\d+)`)
+	kindleLocation    = regexp.MustCompile(`(?i)(?:\blocation|\bloc\.|\bemplacement|\bposition|\bposici[óo]n|\bposizione|\bposiç[ãa]o)\s*([\d]+(?:-[\d]+)?)`)
+	kindleTime        = regexp.MustCompile(`(\d{1,2}):(\d{2})(?::(\d{2}))?\s*([AaPp]\.?\s?[Mm]\.?)?`)
+	kindleYear        = regexp.MustCompile(`\b(\d{4})\b`)
+	kindleDay         = regexp.MustCompile(`\b(\d{1,2})\b\.?`)
+	kindleWord        = regexp.MustCompile(`[\p{L}]+`)
+)
+
+// kindleKinds maps the words naming the kind of a clipping in the Kindle locales to the clipping kinds.
+var kindleKinds = map[string]string{
+	"highlight":      "highlight",
+	"surlignement":   "highlight",
+	"markierung":     "highlight",
+	"subrayado":      "highlight",
+	"evidenziazione": "highlight",
+	"destaque":       "highlight",
+	"markering":      "highlight",
+	"note":           "note",
+	"notiz":          "note",
+	"nota":           "note",
+	"notitie":        "note",
+	"bookmark":       "bookmark",
+	"signet":         "bookmark",
+	"lesezeichen":    "bookmark",
+	"marcador":       "bookmark",
+	"segnalibro":     "bookmark",
+	"bladwijzer":     "bookmark",
+}
+
+// kindleMonths maps the month names and abbreviations of the Kindle locales to months.
+var kindleMonths = map[string]time.Month{
+	"january": time.January, "jan": time.January, "janvier": time.January, "janv": time.January, "januar": time.January, "enero": time.January, "gennaio": time.January, "janeiro": time.January, "januari": time.January,
+	"february": time.February, "feb": time.February, "février": time.February, "févr": time.February, "februar": time.February, "febrero": time.February, "febbraio": time.February, "fevereiro": time.February, "februari": time.February,
+	"march": time.March, "mar": time.March, "mars": time.March, "märz": time.March, "marzo": time.March, "março": time.March, "maart": time.March,
+	"april": time.April, "apr": time.April, "avril": time.April, "abril": time.April, "aprile": time.April,
+	"may": time.May, "mai": time.May, "mayo": time.May, "maggio": time.May, "maio": time.May, "mei": time.May,
+	"june": time.June, "jun": time.June, "juin": time.June, "juni": time.June, "junio": time.June, "giugno": time.June, "junho": time.June,
+	"july": time.July, "jul": time.July, "juillet": time.July, "juil": time.July, "juli": time.July, "julio": time.July, "luglio": time.July, "julho": time.July,
+	"august": time.August, "aug": time.August, "août": time.August, "agosto": time.August, "augustus": time.August,
+	"september": time.September, "sep": time.September, "sept": time.September, "septembre": time.September, "septiembre": time.September, "settembre": time.September, "setembro": time.September,
+	"october": time.October, "oct": time.October, "octobre": time.October, "oktober": time.October, "octubre": time.October, "ottobre": time.October, "outubro": time.October,
+	"november": time.November, "nov": time.November, "novembre": time.November, "noviembre": time.November, "novembro": time.November,
+	"december": time.December, "dec": time.December, "décembre": time.December, "déc": time.December, "dezember": time.December, "diciembre": time.December, "dicembre": time.December, "dezembro": time.December,
+}
+
+// ParseKindleClippings parses the "My Clippings.txt" file of a Kindle.
+//
+// Every clipping is made of a "Title (Author)" line, a metadata line with the kind of the clipping,
+// its page and location and the date it was added, the text of the clipping and a "==========" line.
+// The metadata line is understood in English, French, German, Spanish, Italian, Portuguese and Dutch,
+// with the day before or after the month and 12 or 24-hour times. Malformed clippings are skipped,
+// and the date is left zero when it cannot be read.
+//
+// Parameters:
+// - r: the content of the clippings file.
+//
+// Returns:
+// - []Clipping: the clippings found in the file, in the file order.
+// - error: an error if the file could not be read.
+func ParseKindleClippings(r io.Reader) ([]Clipping, error) {
+	clippings := make([]Clipping, 0)
+	block := make([]string, 0)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(clippings) == 0 && len(block) == 0 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+
+		if strings.TrimSpace(line) != kindleSeparator {
+			block = append(block, line)
+			continue
+		}
+
+		if clipping, ok := parseKindleClipping(block); ok {
+			clippings = append(clippings, clipping)
+		}
+		block = block[:0]
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return clippings, nil
+}
+
+// parseKindleClipping parses the lines of a clipping, without its separator line.
+func parseKindleClipping(lines []string) (Clipping, bool) {
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	if len(lines) < 2 {
+		return Clipping{}, false
+	}
+
+	clipping := Clipping{Title: strings.TrimSpace(strings.TrimPrefix(lines[0], "\ufeff"))}
+	if match := kindleTitleAuthor.FindStringSubmatch(clipping.Title); match != nil && match[1] != "" {
+		clipping.Title = match[1]
+		clipping.Author = strings.TrimSpace(match[2])
+	}
+
+	metadata := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(lines[1]), "-"))
+	parts := strings.Split(metadata, "|")
+
+	clipping.Kind = kindleKind(parts[0])
+	if clipping.Kind == "" {
+		return Clipping{}, false
+	}
+
+	if match := kindlePage.FindStringSubmatch(metadata); match != nil {
+		clipping.Page, _ = strconv.Atoi(match[1])
+	}
+	if match := kindleLocation.FindStringSubmatch(metadata); match != nil {
+		clipping.Location = match[1]
+	}
+	if len(parts) > 1 {
+		clipping.AddedAt = parseKindleDate(parts[len(parts)-1])
+	}
+
+	clipping.Text = strings.TrimSpace(strings.Join(lines[2:], "\n"))
+
+	return clipping, true
+}
+
+// kindleKind returns the kind of a clipping named in the first part of its metadata line.
+func kindleKind(value string) string {
+	for _, word := range kindleWord.FindAllString(strings.ToLower(value), -1) {
+		if kind, ok := kindleKinds[word]; ok {
+			return kind
+		}
+	}
+
+	return ""
+}
+
+// parseKindleDate reads the date a clipping was added from the last part of its metadata line, such as
+// "Added on Sunday, March 5, 2017 10:04:03 PM" or "Ajouté le lundi 5 mars 2018 10:04:03".
+func parseKindleDate(value string) time.Time {
+	timeMatch := kindleTime.FindStringSubmatchIndex(value)
+	if timeMatch == nil {
+		return time.Time{}
+	}
+
+	clock := kindleTime.FindStringSubmatch(value)
+	rest := value[:timeMatch[0]] + " " + value[timeMatch[1]:]
+
+	yearMatch := kindleYear.FindStringSubmatchIndex(rest)
+	if yearMatch == nil {
+		return time.Time{}
+	}
+	year, _ := strconv.Atoi(rest[yearMatch[2]:yearMatch[3]])
+	rest = rest[:yearMatch[0]] + " " + rest[yearMatch[1]:]
+
+	var month time.Month
+	for _, word := range kindleWord.FindAllString(strings.ToLower(rest), -1) {
+		if m, ok := kindleMonths[word]; ok {
+			month = m
+			break
+		}
+	}
+
+	dayMatch := kindleDay.FindStringSubmatch(rest)
+	if month == 0 || dayMatch == nil {
+		return time.Time{}
+	}
+	day, _ := strconv.Atoi(dayMatch[1])
+
+	hour, _ := strconv.Atoi(clock[1])
+	minute, _ := strconv.Atoi(clock[2])
+	second, _ := strconv.Atoi(clock[3])
+	switch meridiem := strings.ToLower(strings.NewReplacer(".", "", " ", "").Replace(clock[4])); {
+	case meridiem == "pm" && hour < 12:
+		hour += 12
+	case meridiem == "am" && hour == 12:
+		hour = 0
+	}
+
+	return time.Date(year, month, day, hour, minute, second, 0, time.UTC)
+}
@@ -0,0 +1,83 @@
+package pkg
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// koboAnnotationsSQL selects the highlights and notes of the Bookmark table of a Kobo database, with the
+// title and author of their book. Bookmarks without text nor annotation are dog-ears and are left out.
+const koboAnnotationsSQL = `SELECT Bookmark.BookmarkID, COALESCE(content.Title, ''), COALESCE(content.Attribution, ''),
+	COALESCE(Bookmark.Text, ''), COALESCE(Bookmark.Annotation, ''), COALESCE(Bookmark.DateCreated, '')
+	FROM Bookmark
+	LEFT JOIN content ON content.ContentID = Bookmark.VolumeID AND content.ContentType = 6
+	WHERE COALESCE(Bookmark.Text, '') <> '' OR COALESCE(Bookmark.Annotation, '') <> ''
+	ORDER BY Bookmark.DateCreated`
+
+// koboDateLayouts are the layouts of the dates stored by the Kobo firmwares.
+var koboDateLayouts = []string{
+	"2006-01-02T15:04:05.000",
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+}
+
+// ReadKoboAnnotations reads the highlights and notes stored in the KoboReader.sqlite database of a Kobo.
+//
+// A bookmark with highlighted text is a highlight, and its annotation, if any, is the comment on it.
+// A bookmark with only an annotation is a note. The ID of the bookmark is kept as the source ID of the
+// clipping, so the same annotation is recognized when the database is imported again.
+//
+// Parameters:
+// - path: the path of the database file.
+//
+// Returns:
+// - []Clipping: the highlights and notes found in the database, oldest first.
+// - error: an error if the file is not a Kobo database or could not be read.
+func ReadKoboAnnotations(path string) ([]Clipping, error) {
+	db, err := sql.Open("sqlite", "file:"+url.PathEscape(path)+"?mode=ro")
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query(koboAnnotationsSQL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Kobo database: %w", err)
+	}
+	defer rows.Close()
+
+	clippings := make([]Clipping, 0)
+	for rows.Next() {
+		var clipping Clipping
+		var dateCreated string
+
+		if err := rows.Scan(&clipping.SourceID, &clipping.Title, &clipping.Author, &clipping.Text, &clipping.Comment, &dateCreated); err != nil {
+			return nil, err
+		}
+
+		clipping.Kind = "highlight"
+		clipping.Text = strings.TrimSpace(clipping.Text)
+		clipping.Comment = strings.TrimSpace(clipping.Comment)
+		if clipping.Text == "" {
+			clipping.Kind = "note"
+			clipping.Text, clipping.Comment = clipping.Comment, ""
+		}
+
+		for _, layout := range koboDateLayouts {
+			if addedAt, err := time.Parse(layout, dateCreated); err == nil {
+				clipping.AddedAt = addedAt
+				break
+			}
+		}
+
+		clippings = append(clippings, clipping)
+	}
+
+	return clippings, rows.Err()
+}
@@ -0,0 +1,179 @@
+package pkg
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	markdownHeading      = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	markdownRule         = regexp.MustCompile(`^(?:-{3,}|\*{3,}|_{3,})\s*$`)
+	markdownUnordered    = regexp.MustCompile(`^[-*+]\s+(.*)$`)
+	markdownOrdered      = regexp.MustCompile(`^\d+[.)]\s+(.*)$`)
+	markdownSpoilerStart = regexp.MustCompile(`^:::\s*spoiler\b\s*(.*)$`)
+	markdownCodeSpan     = regexp.MustCompile("`([^`]+)`")
+	markdownLink         = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	markdownBold         = regexp.MustCompile(`\*\*(\S(?:.*?\S)?)\*\*|__(\S(?:.*?\S)?)__`)
+	markdownItalic       = regexp.MustCompile(`\*(\S(?:[^*]*?\S)?)\*|\b_(\S(?:[^_]*?\S)?)_\b`)
+	markdownStrike       = regexp.MustCompile(`~~(\S(?:.*?\S)?)~~`)
+	markdownSpoiler      = regexp.MustCompile(`&gt;!(.+?)!&lt;`)
+	markdownPlaceholder  = regexp.MustCompile("\x00(\\d+)\x00")
+)
+
+// RenderMarkdown renders a Markdown text as sanitized HTML.
+//
+// It supports paragraphs, headings, block quotes, lists, fenced code blocks, horizontal rules,
+// bold, italic, strikethrough, inline code and links. Spoilers are written either inline as
+// ">!hidden text!<", rendered as a span with the "spoiler" class, or as a block between
+// ":::spoiler [summary]" and ":::", rendered as a collapsed details element.
+//
+// The text is escaped before any Markdown is interpreted, so raw HTML is shown as text and never
+// reaches the output, and only http, https and mailto links are kept.
+//
+// Parameters:
+// - source: the Markdown text.
+//
+// Returns:
+// - string: the rendered HTML.
+func RenderMarkdown(source string) string {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	source = strings.ReplaceAll(source, "\x00", "") // Reserved for the placeholders of the inline rendering
+	return renderMarkdownBlocks(strings.Split(source, "\n"))
+}
+
+// renderMarkdownBlocks renders a sequence of lines as HTML blocks.
+func renderMarkdownBlocks(lines []string) string {
+	var out strings.Builder
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			i++
+
+		case strings.HasPrefix(trimmed, "```"):
+			end := i + 1
+			for end < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[end]), "```") {
+				end++
+			}
+			out.WriteString("<pre><code>" + html.EscapeString(strings.Join(lines[i+1:min(end, len(lines))], "\n")) + "</code></pre>\n")
+			i = end + 1
+
+		case markdownSpoilerStart.MatchString(trimmed):
+			summary := strings.TrimSpace(markdownSpoilerStart.FindStringSubmatch(trimmed)[1])
+			if summary == "" {
+				summary = "Spoiler"
+			}
+
+			end, depth := i+1, 1
+			for ; end < len(lines); end++ {
+				inner := strings.TrimSpace(lines[end])
+				if markdownSpoilerStart.MatchString(inner) {
+					depth++
+				} else if inner == ":::" {
+					if depth--; depth == 0 {
+						break
+					}
+				}
+			}
+
+			out.WriteString(`<details class="spoiler"><summary>` + renderMarkdownInline(summary) + "</summary>\n")
+			out.WriteString(renderMarkdownBlocks(lines[i+1 : min(end, len(lines))]))
+			out.WriteString("</details>\n")
+			i = end + 1
+
+		case markdownHeading.MatchString(trimmed):
+			match := markdownHeading.FindStringSubmatch(trimmed)
+			out.WriteString(fmt.Sprintf("<h%d>%s</h%d>\n", len(match[1]), renderMarkdownInline(match[2]), len(match[1])))
+			i++
+
+		case markdownRule.MatchString(trimmed):
+			out.WriteString("<hr>\n")
+			i++
+
+		case isMarkdownQuote(trimmed):
+			quoted := make([]string, 0)
+			for ; i < len(lines) && isMarkdownQuote(strings.TrimSpace(lines[i])); i++ {
+				quoted = append(quoted, strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(lines[i]), ">"), " "))
+			}
+			out.WriteString("<blockquote>\n" + renderMarkdownBlocks(quoted) + "</blockquote>\n")
+
+		case markdownUnordered.MatchString(trimmed), markdownOrdered.MatchString(trimmed):
+			pattern, tag := markdownUnordered, "ul"
+			if markdownOrdered.MatchString(trimmed) {
+				pattern, tag = markdownOrdered, "ol"
+			}
+
+			out.WriteString("<" + tag + ">\n")
+			for ; i < len(lines) && pattern.MatchString(strings.TrimSpace(lines[i])); i++ {
+				item := pattern.FindStringSubmatch(strings.TrimSpace(lines[i]))[1]
+				out.WriteString("<li>" + renderMarkdownInline(item) + "</li>\n")
+			}
+			out.WriteString("</" + tag + ">\n")
+
+		default:
+			paragraph := make([]string, 0)
+			for ; i < len(lines) && isMarkdownParagraphLine(strings.TrimSpace(lines[i])); i++ {
+				paragraph = append(paragraph, strings.TrimSpace(lines[i]))
+			}
+			out.WriteString("<p>" + renderMarkdownInline(strings.Join(paragraph, "\n")) + "</p>\n")
+		}
+	}
+
+	return out.String()
+}
+
+// renderMarkdownInline escapes a text and renders its inline Markdown. Code spans and link tags are
+// set aside so nothing inside them is interpreted.
+func renderMarkdownInline(text string) string {
+	text = html.EscapeString(text)
+
+	spans := make([]string, 0)
+	text = markdownCodeSpan.ReplaceAllStringFunc(text, func(match string) string {
+		spans = append(spans, "<code>"+markdownCodeSpan.FindStringSubmatch(match)[1]+"</code>")
+		return fmt.Sprintf("\x00%d\x00", len(spans)-1)
+	})
+
+	text = markdownLink.ReplaceAllStringFunc(text, func(match string) string {
+		parts := markdownLink.FindStringSubmatch(match)
+		url := html.UnescapeString(parts[2])
+		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") && !strings.HasPrefix(url, "mailto:") {
+			return parts[1]
+		}
+		spans = append(spans, `<a href="`+html.EscapeString(url)+`" rel="nofollow noopener noreferrer">`)
+		return fmt.Sprintf("\x00%d\x00", len(spans)-1) + parts[1] + "</a>"
+	})
+
+	text = markdownSpoiler.ReplaceAllString(text, `<span class="spoiler">$1</span>`)
+	text = markdownBold.ReplaceAllString(text, "<strong>$1$2</strong>")
+	text = markdownItalic.ReplaceAllString(text, "<em>$1$2</em>")
+	text = markdownStrike.ReplaceAllString(text, "<del>$1</del>")
+
+	return markdownPlaceholder.ReplaceAllStringFunc(text, func(match string) string {
+		index, _ := strconv.Atoi(markdownPlaceholder.FindStringSubmatch(match)[1])
+		return spans[index]
+	})
+}
+
+// isMarkdownQuote reports whether a line is part of a block quote. A line starting with an
+// inline spoiler is not.
+func isMarkdownQuote(line string) bool {
+	return strings.HasPrefix(line, ">") && !strings.HasPrefix(line, ">!")
+}
+
+// isMarkdownParagraphLine reports whether a line continues a paragraph rather than starting another block.
+func isMarkdownParagraphLine(line string) bool {
+	return line != "" &&
+		!strings.HasPrefix(line, "```") &&
+		!markdownSpoilerStart.MatchString(line) &&
+		!markdownHeading.MatchString(line) &&
+		!markdownRule.MatchString(line) &&
+		!isMarkdownQuote(line) &&
+		!markdownUnordered.MatchString(line) &&
+		!markdownOrdered.MatchString(line)
+}
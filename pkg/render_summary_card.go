@@ -0,0 +1,184 @@
+package pkg
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/text/unicode/norm"
+)
+
+// The size of a summary card, the usual size of the preview images of links.
+const (
+	summaryCardWidth  = 1200
+	summaryCardHeight = 630
+	summaryCardMargin = 60
+)
+
+var (
+	summaryCardBackground = color.RGBA{R: 0x1e, G: 0x29, B: 0x3b, A: 0xff}
+	summaryCardText       = color.RGBA{R: 0xf8, G: 0xfa, B: 0xfc, A: 0xff}
+	summaryCardMuted      = color.RGBA{R: 0x94, G: 0xa3, B: 0xb8, A: 0xff}
+	summaryCardAccent     = color.RGBA{R: 0xf5, G: 0x9e, B: 0x0b, A: 0xff}
+)
+
+// SummaryCard is the content of a summary card: a title, a row of figures, a bar chart and a few lines of text.
+type SummaryCard struct {
+	Title    string
+	Subtitle string
+	Figures  []CardFigure
+	Bars     []CardBar
+	Lines    []string
+}
+
+// CardFigure is a figure of a summary card, such as "12" with the label "books".
+type CardFigure struct {
+	Value string
+	Label string
+}
+
+// CardBar is a bar of the chart of a summary card.
+type CardBar struct {
+	Label string
+	Value int64
+}
+
+// RenderSummaryCardPNG draws a summary card as a 1200x630 PNG image, without any external font or tool.
+//
+// The text is drawn with a fixed bitmap font, which only has ASCII characters: accents are removed and
+// the other characters are replaced with question marks. Text too long for the card is cut. The bars
+// are scaled to the highest one, at most 4 figures and 2 lines are drawn.
+//
+// Parameters:
+// - w: the writer the PNG image is written to.
+// - card: the content of the card.
+//
+// Returns:
+// - error: an error object if the image could not be written.
+func RenderSummaryCardPNG(w io.Writer, card SummaryCard) error {
+	img := image.NewRGBA(image.Rect(0, 0, summaryCardWidth, summaryCardHeight))
+	draw.Draw(img, img.Bounds(), image.NewUniform(summaryCardBackground), image.Point{}, draw.Src)
+
+	width := summaryCardWidth - 2*summaryCardMargin
+
+	drawCardText(img, card.Title, summaryCardMargin, 50, 4, width, summaryCardText)
+	drawCardText(img, card.Subtitle, summaryCardMargin, 115, 2, width, summaryCardMuted)
+
+	figures := card.Figures[:min(len(card.Figures), 4)]
+	for i, figure := range figures {
+		x := summaryCardMargin + i*width/len(figures)
+		drawCardText(img, figure.Value, x, 170, 5, width/len(figures), summaryCardAccent)
+		drawCardText(img, figure.Label, x, 240, 2, width/len(figures), summaryCardMuted)
+	}
+
+	if len(card.Bars) > 0 {
+		drawCardBars(img, card.Bars, summaryCardMargin, 300, width, 220)
+	}
+
+	for i, line := range card.Lines[:min(len(card.Lines), 2)] {
+		drawCardText(img, line, summaryCardMargin, 566+i*30, 2, width, summaryCardText)
+	}
+
+	return png.Encode(w, img)
+}
+
+// drawCardBars draws a bar chart in the given area, with the value above each bar and its label below.
+func drawCardBars(img *image.RGBA, bars []CardBar, x, y, width, height int) {
+	highest := int64(0)
+	for _, bar := range bars {
+		highest = max(highest, bar.Value)
+	}
+
+	slot := width / len(bars)
+	barWidth := max(slot*7/10, 1)
+	baseline := y + height - 2*13
+	maxHeight := baseline - y - 2*13 - 4
+
+	draw.Draw(img, image.Rect(x, baseline, x+width, baseline+2), image.NewUniform(summaryCardMuted), image.Point{}, draw.Src)
+
+	for i, bar := range bars {
+		left := x + i*slot + (slot-barWidth)/2
+
+		if bar.Value > 0 && highest > 0 {
+			barHeight := max(int(int64(maxHeight)*bar.Value/highest), 2)
+			rect := image.Rect(left, baseline-barHeight, left+barWidth, baseline)
+			draw.Draw(img, rect, image.NewUniform(summaryCardAccent), image.Point{}, draw.Src)
+
+			value := strconv.FormatInt(bar.Value, 10)
+			drawCardText(img, value, left+(barWidth-len(value)*7*2)/2, rect.Min.Y-2*13-4, 2, slot, summaryCardText)
+		}
+
+		label := cardASCII(bar.Label)
+		drawCardText(img, label, left+(barWidth-len(label)*7*2)/2, baseline+6, 2, slot, summaryCardMuted)
+	}
+}
+
+// drawCardText draws a line of text with its top left corner at the given point, with the bitmap font enlarged
+// by the given scale, and cuts it to the given width.
+func drawCardText(img *image.RGBA, text string, x, y, scale, width int, textColor color.Color) {
+	face := basicfont.Face7x13
+
+	text = cardASCII(text)
+	if length := width / (face.Advance * scale); len(text) > length {
+		text = text[:max(length-3, 0)] + "..."
+		text = text[:min(len(text), length)]
+	}
+	if text == "" {
+		return
+	}
+
+	mask := image.NewAlpha(image.Rect(0, 0, len(text)*face.Advance, face.Height))
+	drawer := font.Drawer{
+		Dst:  mask,
+		Src:  image.Opaque,
+		Face: face,
+		Dot:  fixed.P(0, face.Ascent),
+	}
+	drawer.DrawString(text)
+
+	fill := image.NewUniform(textColor)
+	bounds := mask.Bounds()
+	for py := bounds.Min.Y; py < bounds.Max.Y; py++ {
+		for px := bounds.Min.X; px < bounds.Max.X; px++ {
+			if mask.AlphaAt(px, py).A == 0 {
+				continue
+			}
+			rect := image.Rect(x+px*scale, y+py*scale, x+(px+1)*scale, y+(py+1)*scale)
+			draw.Draw(img, rect, fill, image.Point{}, draw.Src)
+		}
+	}
+}
+
+// cardASCII turns a text into the ASCII characters of the bitmap font: accents are removed, typographic
+// quotes and dashes are replaced with their ASCII form and the other characters with question marks.
+func cardASCII(text string) string {
+	var out strings.Builder
+
+	for _, r := range norm.NFD.String(text) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+		case r == '‘' || r == '’':
+			out.WriteRune('\'')
+		case r == '“' || r == '”':
+			out.WriteRune('"')
+		case r == '–' || r == '—':
+			out.WriteRune('-')
+		case unicode.IsSpace(r):
+			out.WriteRune(' ')
+		case r < 0x20 || r > 0x7e:
+			out.WriteRune('?')
+		default:
+			out.WriteRune(r)
+		}
+	}
+
+	return out.String()
+}
@@ -0,0 +1,58 @@
+package pkg
+
+import (
+	"regexp"
+	"strings"
+)
+
+// authorSeparator matches the separators commonly used between the names of co-authors.
+var authorSeparator = regexp.MustCompile(`(?i)\s*(?:;|&|/|\s+and\s+|\s+e\s+)\s*`)
+
+// SplitAuthorNames splits a free-text author string into the individual author names.
+//
+// Names are separated by ";", "&", "/", " and " or " e ". Commas also separate names,
+// except when the string has the inverted "Last, First" form (a single comma between
+// two single words, e.g. "Tolkien, J.R.R."), in which case it is turned back into
+// "First Last". Empty and duplicated names are dropped and the original order is kept.
+//
+// Parameters:
+// - value: the author string to be split.
+//
+// Returns:
+// - []string: the author names found in the string.
+func SplitAuthorNames(value string) []string {
+	names := make([]string, 0)
+	seen := make(map[string]bool)
+
+	for _, part := range authorSeparator.Split(value, -1) {
+		for _, name := range splitCommaNames(part) {
+			name = strings.Join(strings.Fields(name), " ")
+			key := strings.ToLower(name)
+
+			if name == "" || seen[key] {
+				continue
+			}
+
+			seen[key] = true
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// splitCommaNames splits a string on commas unless it looks like an inverted "Last, First" name.
+func splitCommaNames(value string) []string {
+	parts := strings.Split(value, ",")
+
+	if len(parts) == 2 {
+		last := strings.TrimSpace(parts[0])
+		first := strings.TrimSpace(parts[1])
+
+		if last != "" && first != "" && !strings.Contains(last, " ") && !strings.Contains(first, " ") {
+			return []string{first + " " + last}
+		}
+	}
+
+	return parts
+}
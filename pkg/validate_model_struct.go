@@ -72,6 +72,9 @@ func ValidateModelStruct(obj interface{}) error {
 	case "endswith":
 		// Case when the field should end with a specific substring.
 		return errors.New(field + " must end with " + validationError.Param())
+	case "hexcolor":
+		// Case when the field should be a hexadecimal color (e.g. #1E90FF) but is not.
+		return errors.New(field + " must be a valid hexadecimal color")
 	case "datetime":
 		// Case when the field should be a valid datetime in a specific format.
 		return errors.New(field + " must be a valid datetime in the format " + validationError.Param())